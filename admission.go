@@ -0,0 +1,80 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"math/rand/v2"
+)
+
+// Admission decides whether an insert caches a key that is not cached yet.
+// It is called with the cache mutex held, must be fast and must not be
+// shared between caches.
+type Admission[K comparable] interface {
+	Admit(key K) bool
+}
+
+// SetAdmission sets the admission control for new keys. Rejected inserts
+// are not cached: InsertWithOptions returns false, InsertE ErrRejected, and
+// Stats.Rejections counts them. Updates of cached keys, pinned and
+// negative entries are always admitted. Pass nil to admit all keys.
+func (c *SLRUCache[K, V]) SetAdmission(a Admission[K]) {
+	c.mu.Lock()
+	c.admission = a
+	c.mu.Unlock()
+}
+
+// ProbabilisticAdmission admits only a fraction of the keys not seen
+// recently, protecting the cache from one-pass scans without the cost of a
+// frequency sketch. A doorkeeper, a Bloom filter of the recently offered
+// keys, lets keys offered before in right away, so keys that are inserted
+// repeatedly get in at their second insert at the latest. Without
+// doorkeeper every key is admitted with the configured probability.
+type ProbabilisticAdmission[K comparable] struct {
+	fraction float64
+	hasher   Hasher[K]
+	bits     []uint64 // doorkeeper, nil if disabled
+	mask     uint64   // number of bits minus one
+	added    int      // keys added since the last reset
+	limit    int      // keys remembered before the doorkeeper is reset
+}
+
+// NewProbabilisticAdmission creates an admission control admitting the
+// given fraction of the keys not seen among the last remember offered
+// keys. A remember of 0 disables the doorkeeper. hasher may be nil for
+// the DefaultHasher.
+func NewProbabilisticAdmission[K comparable](fraction float64, remember int, hasher Hasher[K]) *ProbabilisticAdmission[K] {
+	a := &ProbabilisticAdmission[K]{fraction: fraction, hasher: hasher, limit: remember}
+	if remember > 0 {
+		// About 8 bits per key keep false positives of two probes low
+		size := 64
+		for size < 8*remember {
+			size <<= 1
+		}
+		a.bits = make([]uint64, size/64)
+		a.mask = uint64(size - 1)
+		if a.hasher == nil {
+			a.hasher = NewDefaultHasher[K]()
+		}
+	}
+	return a
+}
+
+// Admit reports whether key is admitted and remembers it as seen.
+func (a *ProbabilisticAdmission[K]) Admit(key K) bool {
+	if a.bits != nil {
+		h := a.hasher.Hash(key)
+		i, j := h&a.mask, (h>>32|h<<32)&a.mask
+		if a.bits[i/64]&(1<<(i%64)) != 0 && a.bits[j/64]&(1<<(j%64)) != 0 {
+			return true
+		}
+
+		if a.added++; a.added > a.limit {
+			// Forget the old keys so the filter does not fill up
+			clear(a.bits)
+			a.added = 1
+		}
+		a.bits[i/64] |= 1 << (i % 64)
+		a.bits[j/64] |= 1 << (j % 64)
+	}
+	return rand.Float64() < a.fraction
+}
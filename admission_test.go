@@ -0,0 +1,55 @@
+package slrucache
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAdmissionCoinFlip tests that a pure coin flip admits the configured fraction.
+func TestAdmissionCoinFlip(t *testing.T) {
+	c := NewSLRUCache[int, int](10, 10)
+	c.SetAdmission(NewProbabilisticAdmission[int](0, 0, nil))
+	if err := c.InsertE(1, 1); !errors.Is(err, ErrRejected) {
+		t.Errorf("insert should be rejected, got %v", err)
+	}
+	if c.InsertWithOptions(2, 2) || !c.InsertWithOptions(3, 3, WithPin()) {
+		t.Error("only the pinned insert should be admitted")
+	}
+	if s := c.Stats(); s.Rejections != 2 {
+		t.Errorf("rejections %d", s.Rejections)
+	}
+
+	c.SetAdmission(NewProbabilisticAdmission[int](1, 0, nil))
+	if err := c.InsertE(1, 1); err != nil || c.Lookup(1) == nil {
+		t.Error("insert should be admitted")
+	}
+
+	// updates of cached keys are always admitted
+	c.SetAdmission(NewProbabilisticAdmission[int](0, 0, nil))
+	if err := c.InsertE(1, 2); err != nil || *c.Lookup(1) != 2 {
+		t.Error("update should be admitted")
+	}
+}
+
+// TestAdmissionDoorkeeper tests that keys offered before are admitted.
+func TestAdmissionDoorkeeper(t *testing.T) {
+	a := NewProbabilisticAdmission[int](0, 100, nil)
+	for i := 0; i < 50; i++ {
+		if a.Admit(i) {
+			t.Fatalf("never-seen key %d admitted", i)
+		}
+	}
+	for i := 0; i < 50; i++ {
+		if !a.Admit(i) {
+			t.Fatalf("seen key %d rejected", i)
+		}
+	}
+
+	// the doorkeeper forgets after remember new keys
+	for i := 1000; i < 1101; i++ {
+		a.Admit(i)
+	}
+	if a.Admit(0) {
+		t.Error("forgotten key should not be admitted")
+	}
+}
@@ -0,0 +1,272 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bytes"
+	"sync"
+)
+
+// DefaultArenaSize is the size of the arenas an ArenaCache allocates.
+const DefaultArenaSize = 1 << 20
+
+// minArenaSlot is the smallest slot size handed out by an arena.
+const minArenaSlot = 16
+
+// Serializer converts values to and from bytes for storage in arenas.
+type Serializer[V any] interface {
+	// Append appends the encoding of v to buf and returns the result.
+	Append(buf []byte, v V) []byte
+	// Decode decodes a value. data is only valid during the call and
+	// must not be retained.
+	Decode(data []byte) (V, error)
+}
+
+// BytesSerializer stores byte slices as they are.
+type BytesSerializer struct{}
+
+// Append appends v to buf.
+func (BytesSerializer) Append(buf []byte, v []byte) []byte {
+	return append(buf, v...)
+}
+
+// Decode returns a copy of data.
+func (BytesSerializer) Decode(data []byte) ([]byte, error) {
+	return bytes.Clone(data), nil
+}
+
+// arenaRef locates a value in the arenas. It holds no pointers, so the
+// entries of the underlying cache do not point into the heap for values.
+type arenaRef struct {
+	arena int32  // arena index
+	off   uint32 // offset of the slot in the arena
+	size  uint32 // length of the encoded value
+	class int8   // slot size class, -1 for a dedicated arena
+}
+
+// arenaAlloc is a slab allocator carving fixed size arenas into slots of
+// power of two size classes. Each arena serves one class; values larger
+// than an arena get a dedicated arena which is dropped when freed.
+type arenaAlloc struct {
+	size   int        // arena size
+	arenas [][]byte   // arenas by index, nil if dropped
+	spare  []int32    // indexes of dropped arenas
+	free   [][]uint64 // free slots by class, arena index << 32 | offset
+	bytes  int        // bytes allocated in arenas
+}
+
+// class returns the size class of a value of n bytes, -1 if it does not
+// fit into an arena.
+func (a *arenaAlloc) class(n int) int8 {
+	slot := minArenaSlot
+	for c := int8(0); slot <= a.size; c++ {
+		if n <= slot {
+			return c
+		}
+		slot <<= 1
+	}
+	return -1
+}
+
+// newArena allocates an arena of n bytes and returns its index.
+func (a *arenaAlloc) newArena(n int) int32 {
+	a.bytes += n
+	if len(a.spare) > 0 {
+		i := a.spare[len(a.spare)-1]
+		a.spare = a.spare[:len(a.spare)-1]
+		a.arenas[i] = make([]byte, n)
+		return i
+	}
+	a.arenas = append(a.arenas, make([]byte, n))
+	return int32(len(a.arenas) - 1)
+}
+
+// alloc stores data and returns its location.
+func (a *arenaAlloc) alloc(data []byte) arenaRef {
+	c := a.class(len(data))
+	if c < 0 {
+		i := a.newArena(len(data))
+		copy(a.arenas[i], data)
+		return arenaRef{arena: i, size: uint32(len(data)), class: -1}
+	}
+
+	for int(c) >= len(a.free) {
+		a.free = append(a.free, nil)
+	}
+	if len(a.free[c]) == 0 {
+		// Carve a new arena into slots of this class
+		i := a.newArena(a.size)
+		slot := minArenaSlot << c
+		for off := a.size - slot; off >= 0; off -= slot {
+			a.free[c] = append(a.free[c], uint64(i)<<32|uint64(off))
+		}
+	}
+
+	free := a.free[c]
+	loc := free[len(free)-1]
+	a.free[c] = free[:len(free)-1]
+
+	ref := arenaRef{arena: int32(loc >> 32), off: uint32(loc), size: uint32(len(data)), class: c}
+	copy(a.data(ref), data)
+	return ref
+}
+
+// data returns the stored bytes of ref.
+func (a *arenaAlloc) data(ref arenaRef) []byte {
+	return a.arenas[ref.arena][ref.off : ref.off+ref.size]
+}
+
+// release frees the slot of ref.
+func (a *arenaAlloc) release(ref arenaRef) {
+	if ref.class < 0 {
+		a.bytes -= len(a.arenas[ref.arena])
+		a.arenas[ref.arena] = nil
+		a.spare = append(a.spare, ref.arena)
+		return
+	}
+	a.free[ref.class] = append(a.free[ref.class], uint64(ref.arena)<<32|uint64(ref.off))
+}
+
+// ArenaCache is an SLRU cache storing its values serialized in large
+// pre-allocated byte arenas instead of individual heap objects. The arenas
+// contain no pointers, so the GC does not scan them, which matters for
+// caches with millions of entries. Values are copied in on insert and
+// decoded on every Get. Slots of evicted, replaced and removed values are
+// reused; arenas of the size classes are never returned to the runtime.
+// ArenaCache has its own lock and is safe for concurrent use.
+type ArenaCache[K comparable, V any] struct {
+	cache *SLRUCache[K, arenaRef]
+	ser   Serializer[V]
+
+	mu      sync.RWMutex // guards alloc and scratch, taken before the cache lock
+	alloc   arenaAlloc
+	scratch []byte // encoding buffer of Insert
+}
+
+// NewArenaCache creates an ArenaCache with the given segment sizes storing
+// values encoded by ser in arenas of arenaSize bytes, DefaultArenaSize if
+// arenaSize is 0 or less.
+func NewArenaCache[K comparable, V any](lruEntries int, probeEntries int, ser Serializer[V], arenaSize int) *ArenaCache[K, V] {
+	if arenaSize <= 0 {
+		arenaSize = DefaultArenaSize
+	}
+
+	a := &ArenaCache[K, V]{
+		cache: NewSLRUCache[K, arenaRef](lruEntries, probeEntries),
+		ser:   ser,
+		alloc: arenaAlloc{size: arenaSize},
+	}
+	a.cache.mu = new(sync.RWMutex)
+	a.cache.recycle = a.release
+	return a
+}
+
+// NewBytesArenaCache creates an ArenaCache for byte slice values.
+func NewBytesArenaCache[K comparable](lruEntries int, probeEntries int) *ArenaCache[K, []byte] {
+	return NewArenaCache[K, []byte](lruEntries, probeEntries, BytesSerializer{}, 0)
+}
+
+// release frees the slot of an evicted, replaced or removed value.
+func (a *ArenaCache[K, V]) release(ref arenaRef) {
+	a.mu.Lock()
+	a.alloc.release(ref)
+	a.mu.Unlock()
+}
+
+// Insert stores value for key. Returns false if no entry was available,
+// i.e. the segments are pinned completely.
+func (a *ArenaCache[K, V]) Insert(key K, value V) bool {
+	a.mu.Lock()
+	a.scratch = a.ser.Append(a.scratch[:0], value)
+	ref := a.alloc.alloc(a.scratch)
+	a.mu.Unlock()
+
+	var old arenaRef
+	var replaced bool
+	_, ok := a.cache.Compute(key, func(prev arenaRef, exists bool) (arenaRef, bool) {
+		old, replaced = prev, exists
+		return ref, true
+	})
+
+	if replaced {
+		a.release(old)
+	}
+	if !ok {
+		a.release(ref)
+	}
+	return ok
+}
+
+// Get returns the value for key. It returns ErrNotFound on a miss and the
+// error of the serializer if the value cannot be decoded.
+func (a *ArenaCache[K, V]) Get(key K) (V, error) {
+	var zero V
+	if _, ok := a.cache.Get(key); !ok {
+		return zero, ErrNotFound
+	}
+
+	// Slots are only released with the arena lock held exclusively, so the
+	// slot the cache maps key to now stays valid while decoding
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	a.cache.mu.RLock()
+	n, ok := a.cache.find(key)
+	var ref arenaRef
+	if ok {
+		ref = a.cache.entries[n].value
+	}
+	a.cache.mu.RUnlock()
+
+	if !ok {
+		return zero, ErrNotFound
+	}
+	return a.ser.Decode(a.alloc.data(ref))
+}
+
+// Remove deletes key. Returns true if the entry was found and removed.
+func (a *ArenaCache[K, V]) Remove(key K) bool {
+	var old arenaRef
+	var found bool
+	a.cache.Compute(key, func(prev arenaRef, exists bool) (arenaRef, bool) {
+		old, found = prev, exists
+		return prev, false
+	})
+
+	if found {
+		a.release(old)
+	}
+	return found
+}
+
+// Clear removes all entries. Their slots are kept for reuse.
+func (a *ArenaCache[K, V]) Clear() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cache.mu.Lock()
+	a.cache.rangeIndex(func(_ K, n int) {
+		a.alloc.release(a.cache.entries[n].value)
+	})
+	a.cache.reset()
+	a.cache.mu.Unlock()
+}
+
+// Len returns the number of entries.
+func (a *ArenaCache[K, V]) Len() int {
+	a.cache.mu.Lock()
+	defer a.cache.mu.Unlock()
+	return a.cache.indexLen()
+}
+
+// ArenaBytes returns the number of bytes allocated in arenas.
+func (a *ArenaCache[K, V]) ArenaBytes() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.alloc.bytes
+}
+
+// Stats returns the counters of the underlying cache.
+func (a *ArenaCache[K, V]) Stats() Stats {
+	return a.cache.Stats()
+}
@@ -0,0 +1,84 @@
+package slrucache
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestArenaCache tests storing, replacing, evicting and removing values.
+func TestArenaCache(t *testing.T) {
+	a := NewBytesArenaCache[string](4, 4)
+
+	a.Insert("a", []byte("alpha"))
+	a.Insert("b", bytes.Repeat([]byte("b"), 100))
+	if v, err := a.Get("a"); err != nil || string(v) != "alpha" {
+		t.Fatalf("Get(a) = %q, %v", v, err)
+	}
+	a.Insert("a", []byte("ALPHA"))
+	if v, _ := a.Get("a"); string(v) != "ALPHA" {
+		t.Errorf("replaced value %q", v)
+	}
+	if !a.Remove("b") || a.Remove("b") {
+		t.Error("Remove failure")
+	}
+	if _, err := a.Get("b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Evictions release their slots, so the arenas do not grow
+	for i := 0; i < 1000; i++ {
+		a.Insert(strconv.Itoa(i), []byte(strconv.Itoa(i)))
+	}
+	if a.Len() != 5 || a.ArenaBytes() != 2*DefaultArenaSize {
+		t.Errorf("%d entries, %d arena bytes", a.Len(), a.ArenaBytes())
+	}
+	if v, _ := a.Get("999"); string(v) != "999" {
+		t.Errorf("Get(999) = %q", v)
+	}
+
+	a.Clear()
+	if a.Len() != 0 {
+		t.Error("Clear failure")
+	}
+}
+
+// TestArenaCacheLarge tests values larger than an arena.
+func TestArenaCacheLarge(t *testing.T) {
+	a := NewArenaCache[int, []byte](2, 2, BytesSerializer{}, 64)
+	big := bytes.Repeat([]byte("x"), 200)
+	a.Insert(1, big)
+	if a.ArenaBytes() != 200 {
+		t.Errorf("%d arena bytes", a.ArenaBytes())
+	}
+	if v, _ := a.Get(1); !bytes.Equal(v, big) {
+		t.Error("large value damaged")
+	}
+	a.Remove(1)
+	if a.ArenaBytes() != 0 {
+		t.Error("dedicated arena not dropped")
+	}
+}
+
+// TestArenaCacheConcurrent tests concurrent inserts and gets.
+func TestArenaCacheConcurrent(t *testing.T) {
+	a := NewBytesArenaCache[int](50, 50)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				k := i % 300
+				a.Insert(k, []byte(strconv.Itoa(k)))
+				if v, err := a.Get(k); err == nil && string(v) != strconv.Itoa(k) {
+					t.Errorf("key %d has value %q", k, v)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
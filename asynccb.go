@@ -0,0 +1,75 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"sync"
+)
+
+// callbackBatch is the eviction and removal callbacks of one operation.
+type callbackBatch[K comparable, V any] struct {
+	hooks    Hooks[K, V]
+	recycle  func(V)
+	evicted  []KV[K, V]
+	recycled []V
+	removeCb func(K)
+	removed  []K
+}
+
+// callbackQueue delivers eviction callbacks from its own goroutine.
+type callbackQueue[K comparable, V any] struct {
+	mu      sync.RWMutex // guards closed against enqueue, held shared while sending
+	closed  bool
+	batches chan callbackBatch[K, V]
+	done    chan struct{}
+}
+
+// SetAsyncCallbacks delivers the OnEvict hooks, the recycler calls and the
+// removal callbacks from a dedicated goroutine instead of the goroutine whose Insert or Lookup
+// evicted or removed the entries, so slow callbacks do not stall the hot path.
+// Callbacks are delivered in order through a queue holding up to size
+// batches; if it is full, operations block until there is room. A size of
+// 0 or less returns to synchronous delivery. Pending callbacks are always
+// delivered before SetAsyncCallbacks or Close return.
+func (c *SLRUCache[K, V]) SetAsyncCallbacks(size int) {
+	var q *callbackQueue[K, V]
+	if size > 0 {
+		q = &callbackQueue[K, V]{
+			batches: make(chan callbackBatch[K, V], size),
+			done:    make(chan struct{}),
+		}
+		go func() {
+			defer close(q.done)
+			for b := range q.batches {
+				c.deliverNow(b.hooks, b.recycle, b.evicted, b.recycled)
+				for _, key := range b.removed {
+					b.removeCb(key)
+				}
+			}
+		}()
+	}
+
+	if old := c.async.Swap(q); old != nil {
+		old.close()
+	}
+}
+
+// enqueue queues b for delivery. Returns false if the queue is closed.
+func (q *callbackQueue[K, V]) enqueue(b callbackBatch[K, V]) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+	q.batches <- b
+	return true
+}
+
+// close stops the queue after delivering the pending callbacks.
+func (q *callbackQueue[K, V]) close() {
+	q.mu.Lock()
+	q.closed = true
+	close(q.batches)
+	q.mu.Unlock()
+	<-q.done
+}
@@ -0,0 +1,69 @@
+package slrucache
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestAsyncCallbacks tests that slow eviction callbacks do not block
+// inserts and are delivered in order.
+func TestAsyncCallbacks(t *testing.T) {
+	c := NewSLRUCache[int, int](1, 1)
+	c.mu = new(sync.RWMutex)
+
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var recycled []int
+	c.SetRecycler(func(v int) {
+		<-release
+		mu.Lock()
+		recycled = append(recycled, v)
+		mu.Unlock()
+	})
+	c.SetAsyncCallbacks(10)
+
+	for i := 0; i < 5; i++ {
+		c.Insert(i, i) // evicts i-1 from the probelist
+	}
+
+	close(release)
+	c.Close()
+	if !slices.Equal(recycled, []int{0, 1, 2, 3}) {
+		t.Errorf("recycled %v, want [0 1 2 3]", recycled)
+	}
+
+	// Synchronous again after Close
+	c.Insert(5, 5)
+	if !slices.Equal(recycled, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("recycled %v after Close", recycled)
+	}
+}
+
+// TestAsyncRemoveCallbacks tests that removal callbacks go through the queue.
+func TestAsyncRemoveCallbacks(t *testing.T) {
+	c := NewSLRUCache[string, int](10, 10)
+	c.mu = new(sync.RWMutex)
+	release := make(chan struct{})
+	var removed []string
+	c.removeCb = func(key string) {
+		<-release
+		removed = append(removed, key)
+	}
+	c.SetAsyncCallbacks(10)
+
+	c.Insert("a:1", 1)
+	c.Insert("a:2", 2)
+	c.Insert("b", 3)
+	c.Remove("b")
+	if n := RemovePrefix(c, "a:"); n != 2 {
+		t.Errorf("removed %d entries", n)
+	}
+
+	close(release)
+	c.Close()
+	slices.Sort(removed[1:])
+	if !slices.Equal(removed, []string{"b", "a:1", "a:2"}) {
+		t.Errorf("removed %v", removed)
+	}
+}
@@ -0,0 +1,171 @@
+// author: (c) Gunter Hartmann
+
+// Package benchmarks compares SLRUCache with other Go caches on identical
+// workloads. It is a separate module so the cache itself does not depend
+// on the libraries it is compared with. Run the comparison with
+//
+//	go test -bench . -benchmem
+//
+// Each benchmark reports ns/op and the hit ratio as "hit%". The workloads
+// are generated up front from fixed seeds, so every cache sees the same
+// key sequence.
+package benchmarks
+
+import (
+	"math/rand"
+
+	"github.com/dgraph-io/ristretto"
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"slrucache"
+)
+
+// Cache is the common interface of the compared caches. A miss is followed
+// by a Set, like a cache fronting a slower store would do.
+type Cache interface {
+	Get(key uint64) bool
+	Set(key uint64)
+}
+
+// Factory creates a cache holding about capacity entries.
+type Factory func(capacity int) Cache
+
+// Caches are the compared implementations by name.
+var Caches = map[string]Factory{
+	"slru":       newSLRU,
+	"lru":        newLRU,
+	"ristretto":  newRistretto,
+	"slru-plain": newPlainLRU,
+}
+
+// slruCache adapts SLRUCache with a probation share of 20%.
+type slruCache struct {
+	c *slrucache.SLRUCache[uint64, uint64]
+}
+
+func newSLRU(capacity int) Cache {
+	probe := max(1, capacity/5)
+	return slruCache{slrucache.NewSLRUCache[uint64, uint64](max(1, capacity-probe), probe)}
+}
+
+// newPlainLRU creates an SLRUCache without probation, a plain LRU cache.
+func newPlainLRU(capacity int) Cache {
+	return slruCache{slrucache.NewSLRUCache[uint64, uint64](max(1, capacity), 0)}
+}
+
+func (s slruCache) Get(key uint64) bool {
+	_, ok := s.c.Get(key)
+	return ok
+}
+
+func (s slruCache) Set(key uint64) {
+	s.c.Insert(key, key)
+}
+
+// lruCache adapts hashicorp/golang-lru.
+type lruCache struct {
+	c *lru.Cache[uint64, uint64]
+}
+
+func newLRU(capacity int) Cache {
+	c, err := lru.New[uint64, uint64](capacity)
+	if err != nil {
+		panic(err)
+	}
+	return lruCache{c}
+}
+
+func (l lruCache) Get(key uint64) bool {
+	_, ok := l.c.Get(key)
+	return ok
+}
+
+func (l lruCache) Set(key uint64) {
+	l.c.Add(key, key)
+}
+
+// ristrettoCache adapts ristretto. Sets are applied asynchronously by
+// ristretto, Set waits for them to keep the comparison deterministic.
+type ristrettoCache struct {
+	c *ristretto.Cache
+}
+
+func newRistretto(capacity int) Cache {
+	c, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters: int64(10 * capacity),
+		MaxCost:     int64(capacity),
+		BufferItems: 64,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return ristrettoCache{c}
+}
+
+func (r ristrettoCache) Get(key uint64) bool {
+	_, ok := r.c.Get(key)
+	return ok
+}
+
+func (r ristrettoCache) Set(key uint64) {
+	r.c.Set(key, key, 1)
+	r.c.Wait()
+}
+
+// Zipf returns n keys drawn from a Zipf distribution with exponent s over
+// keys distinct keys.
+func Zipf(n int, s float64, keys uint64, seed int64) []uint64 {
+	rnd := rand.New(rand.NewSource(seed))
+	z := rand.NewZipf(rnd, s, 1, keys-1)
+	trace := make([]uint64, n)
+	for i := range trace {
+		trace[i] = z.Uint64()
+	}
+	return trace
+}
+
+// Scan returns n keys of a Zipf distributed hot set interrupted every
+// period keys by a sequential scan of length keys never seen before. Scans
+// flush plain LRU caches while SLRU keeps the hot set protected.
+func Scan(n int, period, length int, seed int64) []uint64 {
+	rnd := rand.New(rand.NewSource(seed))
+	z := rand.NewZipf(rnd, 1.1, 1, 10000)
+	trace := make([]uint64, 0, n)
+	next := uint64(1 << 32)
+	for len(trace) < n {
+		if len(trace)%period == 0 {
+			for i := 0; i < length && len(trace) < n; i++ {
+				trace = append(trace, next)
+				next++
+			}
+			continue
+		}
+		trace = append(trace, z.Uint64())
+	}
+	return trace
+}
+
+// Window returns n keys drawn uniformly from a window of size keys that
+// moves forward by one key every step accesses, a working set drifting
+// over time.
+func Window(n int, size int, step int, seed int64) []uint64 {
+	rnd := rand.New(rand.NewSource(seed))
+	trace := make([]uint64, n)
+	for i := range trace {
+		trace[i] = uint64(i/step + rnd.Intn(size))
+	}
+	return trace
+}
+
+// Run replays trace against c and returns the hit ratio.
+func Run(c Cache, trace []uint64) float64 {
+	hits := 0
+	for _, k := range trace {
+		if c.Get(k) {
+			hits++
+		} else {
+			c.Set(k)
+		}
+	}
+	return float64(hits) / float64(len(trace))
+}
@@ -0,0 +1,57 @@
+package benchmarks
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+const traceLen = 1 << 18
+
+// workloads are the compared access patterns with the cache size used.
+var workloads = []struct {
+	name     string
+	capacity int
+	trace    []uint64
+}{
+	{"zipf", 1000, Zipf(traceLen, 1.1, 100000, 1)},
+	{"scan", 1000, Scan(traceLen, 5000, 2000, 2)},
+	{"window", 1000, Window(traceLen, 2000, 16, 3)},
+}
+
+// names returns the cache names in a stable order.
+func names() []string {
+	var names []string
+	for name := range Caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BenchmarkWorkloads replays each workload against each cache.
+func BenchmarkWorkloads(b *testing.B) {
+	for _, w := range workloads {
+		for _, name := range names() {
+			b.Run(fmt.Sprintf("%s/%s", w.name, name), func(b *testing.B) {
+				var ratio float64
+				for i := 0; i < b.N; i++ {
+					ratio = Run(Caches[name](w.capacity), w.trace)
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*len(w.trace)), "ns/access")
+				b.ReportMetric(100*ratio, "hit%")
+			})
+		}
+	}
+}
+
+// TestScanResistance checks the property the suite is meant to show:
+// SLRU keeps its hot set through scans that flush a plain LRU cache.
+func TestScanResistance(t *testing.T) {
+	trace := Scan(1<<16, 5000, 2000, 2)
+	slru := Run(Caches["slru"](1000), trace)
+	plain := Run(Caches["lru"](1000), trace)
+	if slru <= plain {
+		t.Errorf("SLRU hit ratio %.3f not above LRU %.3f on scans", slru, plain)
+	}
+}
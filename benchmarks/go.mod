@@ -0,0 +1,18 @@
+module slrucache/benchmarks
+
+go 1.22.2
+
+require (
+	github.com/dgraph-io/ristretto v0.2.0
+	github.com/hashicorp/golang-lru/v2 v2.0.7
+	slrucache v0.0.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.1.1 // indirect
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	golang.org/x/sys v0.11.0 // indirect
+)
+
+replace slrucache => ../
@@ -0,0 +1,35 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"unsafe"
+)
+
+// LookupBytes looks up a string key given as bytes, e.g. a slice of a
+// network buffer, without converting it to a string on a hit. The bytes
+// are only used to find the entry; everything retaining the key (hooks,
+// events, the read buffer) gets the cached key instead, so key may be
+// reused after the call. Misses convert key to a string like Lookup does.
+func LookupBytes[V any](c *SLRUCache[string, V], key []byte) *V {
+	return c.Lookup(cachedKey(c, key))
+}
+
+// GetBytes is the Get variant of LookupBytes.
+func GetBytes[V any](c *SLRUCache[string, V], key []byte) (V, bool) {
+	return c.Get(cachedKey(c, key))
+}
+
+// cachedKey returns the cached string equal to key, or a copy of key if
+// there is none.
+func cachedKey[V any](c *SLRUCache[string, V], key []byte) string {
+	c.mu.RLock()
+	n, ok := c.find(unsafe.String(unsafe.SliceData(key), len(key)))
+	if ok {
+		k := c.entries[n].key
+		c.mu.RUnlock()
+		return k
+	}
+	c.mu.RUnlock()
+	return string(key)
+}
@@ -0,0 +1,43 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestLookupBytes tests hits, misses and reuse of the key buffer.
+func TestLookupBytes(t *testing.T) {
+	c := NewSLRUCache[string, int](4, 4)
+	c.Insert("key", 1)
+
+	buf := []byte("key")
+	if v := LookupBytes(c, buf); v == nil || *v != 1 {
+		t.Fatal("hit expected")
+	}
+	// Entry is protected now, the read buffer must not keep buf
+	c.SetReadBuffer(16)
+	if v, ok := GetBytes(c, buf); !ok || v != 1 {
+		t.Fatal("hit expected")
+	}
+	copy(buf, "xyz")
+	if LookupBytes(c, buf) != nil {
+		t.Error("miss expected")
+	}
+	if c.Lookup("key") == nil || checkSLRUCacheSanity(c) {
+		t.Error("cached key damaged")
+	}
+	if s := c.Stats(); s.Hits != 3 || s.Misses != 1 {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+// TestLookupBytesAllocs tests that hits do not allocate.
+func TestLookupBytesAllocs(t *testing.T) {
+	c := NewSLRUCache[string, int](4, 4)
+	c.Insert("key", 1)
+	buf := []byte("key")
+	LookupBytes(c, buf)
+
+	if n := testing.AllocsPerRun(100, func() { LookupBytes(c, buf) }); n != 0 {
+		t.Errorf("%v allocations per hit", n)
+	}
+}
@@ -0,0 +1,59 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"reflect"
+)
+
+// SetEqual sets the function CompareAndSwap uses to compare values.
+// Pass nil to compare with ==; values that are not comparable then never
+// match.
+func (c *SLRUCache[K, V]) SetEqual(equal func(a, b V) bool) {
+	c.mu.Lock()
+	c.equal = equal
+	c.mu.Unlock()
+}
+
+// CompareAndSwap replaces the value for key with new if the current value
+// equals old. Missing, expired and negative entries never match.
+// The new value is stored like by Insert, so it is written through with
+// the cache unlocked and the entry keeps its position. Returns true if the
+// value was swapped.
+func (c *SLRUCache[K, V]) CompareAndSwap(key K, old, new V) bool {
+	wt := c.writeThrough.Load()
+	if wt != nil {
+		wt.mu.Lock()
+		defer wt.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+	c.drainReads()
+
+	n, ok := c.find(key)
+	if !ok || c.entries[n].negative || c.expired(n) || !c.valuesEqual(c.entries[n].value, old) {
+		c.mu.Unlock()
+		return false
+	}
+
+	if wt != nil {
+		if err := c.putUnlocked(wt, key, new); err != nil {
+			c.mu.Unlock()
+			return false
+		}
+	}
+	return c.insertLocked(key, new, entryOptions{}, nil) == nil
+}
+
+// valuesEqual compares a and b with the configured equal function or ==.
+// Without equal function values that are not comparable are not equal.
+func (c *SLRUCache[K, V]) valuesEqual(a, b V) bool {
+	if c.equal != nil {
+		return c.equal(a, b)
+	}
+	if !reflect.ValueOf(&a).Elem().Comparable() || !reflect.ValueOf(&b).Elem().Comparable() {
+		return false
+	}
+	return any(a) == any(b)
+}
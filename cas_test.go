@@ -0,0 +1,64 @@
+package slrucache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestCompareAndSwap tests conditional updates with the default comparison.
+func TestCompareAndSwap(t *testing.T) {
+	c := NewSLRUCache[string, int](3, 3)
+	c.Insert("a", 1)
+
+	if c.CompareAndSwap("a", 2, 3) {
+		t.Error("swap with wrong old value should fail")
+	}
+	if !c.CompareAndSwap("a", 1, 2) || *c.Lookup("a") != 2 {
+		t.Error("swap with matching old value should succeed")
+	}
+	if c.CompareAndSwap("b", 0, 1) || c.Lookup("b") != nil {
+		t.Error("swap on a missing key should fail")
+	}
+}
+
+// TestCompareAndSwapEqual tests a custom equal function for non-comparable values.
+func TestCompareAndSwapEqual(t *testing.T) {
+	c := NewSLRUCache[string, []byte](3, 3)
+	c.SetEqual(bytes.Equal)
+	c.Insert("a", []byte("x"))
+
+	if !c.CompareAndSwap("a", []byte("x"), []byte("y")) || string(*c.Lookup("a")) != "y" {
+		t.Error("swap should use the equal function")
+	}
+}
+
+// TestCompareAndSwapNotComparable tests that values that are not comparable do not panic without equal function.
+func TestCompareAndSwapNotComparable(t *testing.T) {
+	c := NewSLRUCache[string, any](3, 3)
+	c.Insert("a", []byte("x"))
+	c.Insert("b", 1)
+
+	if c.CompareAndSwap("a", []byte("x"), 2) {
+		t.Error("slices should not match")
+	}
+	if !c.CompareAndSwap("b", 1, 2) || (*c.Lookup("b")).(int) != 2 {
+		t.Error("comparable values should match")
+	}
+}
+
+// TestCompareAndSwapWriteThrough tests that swapped values are written through.
+func TestCompareAndSwapWriteThrough(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	s := &mapStore{m: map[string]string{}}
+	c.SetWriteThrough(s)
+	c.Insert("a", "1")
+
+	if !c.CompareAndSwap("a", "1", "2") || s.m["a"] != "2" {
+		t.Error("swapped value should be written through")
+	}
+	s.err = errors.New("unavailable")
+	if c.CompareAndSwap("a", "2", "3") || *c.Lookup("a") != "2" {
+		t.Error("failed write should not swap")
+	}
+}
@@ -0,0 +1,68 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import "maps"
+
+// Clone returns an independent cache with the same capacities, contents,
+// segment membership and recency order. Values are copied with the cloner
+// if one is set. Entries keep their TTLs, priorities, weights and the hits
+// counting towards their promotion. The TTL, demotion, promotion threshold
+// and policy, weight, entry, soft and hard limits, recovery, equal, cloner
+// and hasher settings are copied; the clone uses the default LRU policy
+// since policies may keep per cache state. Not carried over are pins,
+// statistics, hooks, callbacks, the recycler, loader, tier, stores,
+// mutation log, webhook, events, victim buffer and admission control, and
+// value indexes, which stay bound to c; register them on the clone with
+// NewValueIndex.
+func (c *SLRUCache[K, V]) Clone() *SLRUCache[K, V] {
+	clone := NewSLRUCache[K, V](c.snum, c.pnum)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainReads()
+
+	clone.ttl = c.ttl
+	clone.idleTTL = c.idleTTL
+	clone.epoch = c.epoch
+	clone.negativeTTL = c.negativeTTL
+	clone.entryTTLs = maps.Clone(c.entryTTLs)
+	clone.now = c.now
+	clone.demote = c.demote
+	clone.promoteHits = c.promoteHits
+	clone.promotion = c.promotion
+	clone.maxWeight = c.maxWeight
+	clone.evictBatch = c.evictBatch
+	clone.entryLimit = c.entryLimit
+	clone.softLimit, clone.hardLimit = c.softLimit, c.hardLimit
+	clone.recovery = c.recovery
+	clone.equal = c.equal
+	clone.cloner = c.cloner
+	clone.hasher = c.hasher
+
+	for _, l := range [][2]*SLRUList[K, V]{
+		{clone.freelist, c.freelist},
+		{clone.probelist, c.probelist},
+		{clone.lrulist, c.lrulist},
+	} {
+		*l[0] = *l[1]
+		l[0].entries = &clone.entries
+	}
+
+	copy(clone.entries, c.entries)
+	for n := range clone.entries {
+		e := &clone.entries[n]
+		e.pins = 0
+		if c.cloner != nil && e.tag != tagFree {
+			e.value = c.cloner(e.value)
+		}
+	}
+
+	if c.index != nil {
+		clone.index, clone.mapping = newOpenIndex[K](clone.cnum, c.index.hasher), nil
+	}
+	c.rangeIndex(func(k K, n int) {
+		clone.setIndex(k, n)
+	})
+	return clone
+}
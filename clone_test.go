@@ -0,0 +1,49 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestClone tests that a clone keeps order and segments and is independent.
+func TestClone(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	insertN(c, 5, 0)
+	lookupN(c, 2, 2)
+	c.Pin("4")
+
+	clone := c.Clone()
+	if checkSLRUCacheSanity(clone) {
+		t.Fatal("clone is inconsistent")
+	}
+	if !equalKeys(listKeys(clone, clone.lrulist), listKeys(c, c.lrulist)) ||
+		!equalKeys(listKeys(clone, clone.probelist), listKeys(c, c.probelist)) {
+		t.Error("clone should keep segments and recency order")
+	}
+	if clone.Pinned("4") {
+		t.Error("pins should not be cloned")
+	}
+
+	clone.Insert("x", "x")
+	clone.Remove("2")
+	if c.Lookup("x") != nil || c.Lookup("2") == nil || checkSLRUCacheSanity(c) {
+		t.Error("changes to the clone should not affect the original")
+	}
+}
+
+// TestCloneSettings tests that the entry limit and the promotion progress of entries are kept.
+func TestCloneSettings(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetPromotionThreshold(2)
+	c.Insert("a", "a")
+	c.Lookup("a")
+	c.setEntryLimit(4)
+
+	clone := c.Clone()
+	if clone.entryLimit != 4 {
+		t.Errorf("entry limit %d", clone.entryLimit)
+	}
+	clone.Lookup("a")
+	if !equalKeys(listKeys(clone, clone.lrulist), []string{"a"}) {
+		t.Error("second hit in the clone should promote")
+	}
+}
@@ -0,0 +1,50 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"time"
+)
+
+// SetCloner sets a function returning deep copies of values. With a cloner
+// Lookup, LookupResult, Get and GetOrCompute hand out copies, so callers
+// mutating maps or slices they got from the cache do not change the cached
+// state. The cloner runs with the cache locked and must not call into any
+// cache. Pass nil to hand out the cached values again.
+func (c *SLRUCache[K, V]) SetCloner(cloner func(V) V) {
+	c.mu.Lock()
+	c.cloner = cloner
+	c.mu.Unlock()
+}
+
+// lookupOut receives the results of a lookup hit, filled under the mutex.
+type lookupOut[V any] struct {
+	value   V
+	expires time.Time // expiration after the hit, zero if the entry does not expire
+	pin     bool      // pin the entry on a hit, see Acquire
+	n       int       // index of the pinned entry
+	lease   uint64    // generation of the pinned entry, see lease
+
+	segment  Segment // segment the entry was found in
+	promoted bool    // the hit promoted the entry
+}
+
+// result returns the pointer a lookup hands out for the value of the entry
+// at index n, a clone if a cloner is set, and fills out if out is not nil.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) result(n int, out *lookupOut[V]) *V {
+	v := &c.entries[n].value
+	if c.cloner != nil {
+		cv := c.cloner(*v)
+		v = &cv
+	}
+	if out != nil {
+		out.value = *v
+		out.expires = c.hitDeadline(n)
+		if out.pin {
+			c.entries[n].pins++
+			out.n, out.lease = n, c.lease(n)
+		}
+	}
+	return v
+}
@@ -0,0 +1,27 @@
+package slrucache
+
+import (
+	"maps"
+	"testing"
+)
+
+// TestCloner tests that lookups hand out copies when a cloner is set.
+func TestCloner(t *testing.T) {
+	c := NewSLRUCache[string, map[string]int](3, 3)
+	c.SetCloner(maps.Clone[map[string]int])
+	c.Insert("a", map[string]int{"n": 1})
+
+	m, _ := c.Get("a")
+	m["n"] = 2
+	(*c.Lookup("a"))["n"] = 3
+
+	if v, _ := c.Get("a"); v["n"] != 1 {
+		t.Error("mutating a returned value should not change the cache")
+	}
+
+	c.SetCloner(nil)
+	(*c.Lookup("a"))["n"] = 4
+	if v, _ := c.Get("a"); v["n"] != 4 {
+		t.Error("without cloner the cached value is shared")
+	}
+}
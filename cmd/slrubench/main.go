@@ -0,0 +1,174 @@
+// author: (c) Gunter Hartmann
+
+// Command slrubench replays a key trace against an SLRUCache and reports the hit ratio.
+//
+// The trace is read with slrucache.ReadTrace, so it is either a plain file
+// with one key per line or a recorded trace. A miss is followed by an
+// insert of the key, like a cache fronting a slower store would do. The
+// simulation is done by package slrusim.
+//
+// Memory is reported in bytes as measured by the cache's Sizer, counting
+// each entry as the length of its key plus -value-size.
+//
+// In planner mode (-plan) it sweeps a grid of total capacities and probe
+// ratios over the trace and prints the Pareto frontier of memory versus
+// hit ratio as CSV or JSON:
+//
+//	slrubench -trace keys.txt -plan -capacities 1000,2000,4000 -ratios 0.1,0.2,0.5 -value-size 512 -format json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"slrucache/slrusim"
+)
+
+// result is the outcome of replaying the trace against one configuration.
+type result struct {
+	Capacity int     `json:"capacity"`
+	LRU      int     `json:"lru"`
+	Probe    int     `json:"probe"`
+	Bytes    int64   `json:"bytes"`
+	Hits     int     `json:"hits"`
+	Misses   int     `json:"misses"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+func main() {
+	tracePath := flag.String("trace", "", "trace file with one key per line (default stdin)")
+	lru := flag.Int("lru", 1000, "protected segment size")
+	probe := flag.Int("probe", 1000, "probation segment size")
+	valueSize := flag.Int("value-size", 0, "bytes of a cached value, added to the key length per entry")
+	plan := flag.Bool("plan", false, "sweep capacities and ratios and print the Pareto frontier")
+	capacities := flag.String("capacities", "1000,2000,4000,8000", "comma separated total capacities for -plan")
+	ratios := flag.String("ratios", "0.1,0.2,0.3,0.5", "comma separated probe ratios for -plan")
+	format := flag.String("format", "csv", "output format for -plan: csv or json")
+	flag.Parse()
+
+	trace, err := readTrace(*tracePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if !*plan {
+		r := simulate(trace, []slrusim.Config{{LRU: *lru, Probe: *probe}}, *valueSize)[0]
+		fmt.Printf("lru:%d probe:%d bytes:%d hits:%d misses:%d hit ratio:%.4f\n", r.LRU, r.Probe, r.Bytes, r.Hits, r.Misses, r.HitRatio)
+		return
+	}
+
+	caps, err := parseInts(*capacities)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	rats, err := parseFloats(*ratios)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	results := simulate(trace, slrusim.Grid(caps, rats), *valueSize)
+	if err := write(os.Stdout, pareto(results), *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// readTrace reads the looked up keys from path, or stdin if path is empty.
+func readTrace(path string) ([]string, error) {
+	in := os.Stdin
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	return slrusim.ReadKeys(in, func(s string) (string, error) { return s, nil })
+}
+
+// simulate replays the trace against the configurations, sizing each entry
+// as its key plus valueSize bytes.
+func simulate(trace []string, configs []slrusim.Config, valueSize int) []result {
+	size := func(k string) int { return len(k) + valueSize }
+	var results []result
+	for _, r := range slrusim.SimulateSized(trace, configs, size) {
+		results = append(results, result{Capacity: r.Capacity(), LRU: r.LRU, Probe: r.Probe,
+			Bytes: r.Bytes, Hits: r.Hits, Misses: r.Misses, HitRatio: r.HitRatio})
+	}
+	return results
+}
+
+// pareto returns the results not dominated by another result with less or
+// equal memory and a higher hit ratio, ordered by memory.
+func pareto(results []result) []result {
+	sorted := append([]result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Bytes != sorted[j].Bytes {
+			return sorted[i].Bytes < sorted[j].Bytes
+		}
+		return sorted[i].HitRatio > sorted[j].HitRatio
+	})
+
+	var frontier []result
+	best := -1.0
+	for _, r := range sorted {
+		if r.HitRatio > best {
+			frontier = append(frontier, r)
+			best = r.HitRatio
+		}
+	}
+	return frontier
+}
+
+// write prints the results as csv or json.
+func write(w io.Writer, results []result, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case "csv":
+		fmt.Fprintln(w, "capacity,lru,probe,bytes,hits,misses,hit_ratio")
+		for _, r := range results {
+			fmt.Fprintf(w, "%d,%d,%d,%d,%d,%d,%.6f\n", r.Capacity, r.LRU, r.Probe, r.Bytes, r.Hits, r.Misses, r.HitRatio)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// parseInts parses a comma separated list of integers.
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("invalid capacity %q", f)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// parseFloats parses a comma separated list of floats.
+func parseFloats(s string) ([]float64, error) {
+	var out []float64
+	for _, f := range strings.Split(s, ",") {
+		r, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ratio %q", f)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
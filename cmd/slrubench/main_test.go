@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"slrucache/slrusim"
+)
+
+// TestPareto tests that dominated configurations are dropped.
+func TestPareto(t *testing.T) {
+	results := []result{
+		{Bytes: 200, HitRatio: 0.5},
+		{Bytes: 100, HitRatio: 0.4},
+		{Bytes: 100, HitRatio: 0.3},
+		{Bytes: 300, HitRatio: 0.45},
+		{Bytes: 400, HitRatio: 0.7},
+	}
+	f := pareto(results)
+	if len(f) != 3 || f[0].HitRatio != 0.4 || f[1].Bytes != 200 || f[2].Bytes != 400 {
+		t.Errorf("unexpected frontier %v", f)
+	}
+}
+
+// TestSimulate tests the hit counting and sizing of a trace replay.
+func TestSimulate(t *testing.T) {
+	r := simulate([]string{"a", "b", "a", "a", "c"}, []slrusim.Config{{LRU: 2, Probe: 2}}, 9)[0]
+	if r.Hits != 2 || r.Misses != 3 || r.Capacity != 4 || r.Bytes != 30 {
+		t.Errorf("unexpected result %v", r)
+	}
+}
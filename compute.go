@@ -0,0 +1,77 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Compute atomically reads, transforms and writes back the value for key.
+// fn receives the current value and whether the key exists; expired and
+// negative entries count as missing. If fn returns true the result is
+// stored like by Insert, a new entry goes into the probelist. If fn
+// returns false an existing entry is removed and a missing one is not
+// created. Compute returns the stored value and whether the key is
+// present afterwards.
+//
+// fn runs with the cache locked and must not call into any cache. With
+// write-through the result is written to the store after fn returns with
+// the cache unlocked; other writes through the store wait for it.
+func (c *SLRUCache[K, V]) Compute(key K, fn func(old V, exists bool) (V, bool)) (V, bool) {
+	wt := c.writeThrough.Load()
+	if wt != nil {
+		wt.mu.Lock()
+		defer wt.mu.Unlock()
+	}
+
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+	c.drainReads()
+
+	var old, zero V
+	n, found := c.find(key)
+	exists := found && !c.entries[n].negative && !c.expired(n)
+	if exists {
+		old = c.entries[n].value
+	}
+
+	value, keep := c.applyLocked(fn, old, exists)
+
+	switch {
+	case keep:
+		if wt != nil {
+			if err := c.putUnlocked(wt, key, value); err != nil {
+				c.mu.Unlock()
+				return old, exists
+			}
+		}
+		if c.insertLocked(key, value, entryOptions{}, nil) != nil {
+			return zero, false
+		}
+		return value, true
+
+	case found:
+		c.removeKey(n, key)
+		tier, removeCb := c.tier, c.removeCb
+		c.mu.Unlock()
+
+		if tier != nil {
+			tier.Delete(key)
+		}
+		c.deliverRemoved(removeCb, key)
+		return zero, false
+
+	default:
+		c.mu.Unlock()
+		return zero, false
+	}
+}
+
+// applyLocked calls fn and releases the mutex if fn panics.
+func (c *SLRUCache[K, V]) applyLocked(fn func(V, bool) (V, bool), old V, exists bool) (value V, keep bool) {
+	done := false
+	defer func() {
+		if !done {
+			c.mu.Unlock()
+		}
+	}()
+	value, keep = fn(old, exists)
+	done = true
+	return value, keep
+}
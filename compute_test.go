@@ -0,0 +1,82 @@
+package slrucache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestCompute tests creating, updating and removing entries with Compute.
+func TestCompute(t *testing.T) {
+	c := NewSLRUCache[string, int](3, 3)
+	incr := func(old int, exists bool) (int, bool) {
+		return old + 1, true
+	}
+
+	for i := 0; i < 3; i++ {
+		c.Compute("n", incr)
+	}
+	if v, ok := c.Compute("n", incr); !ok || v != 4 {
+		t.Errorf("counter should be 4, got %d", v)
+	}
+	if c.probelist.count != 1 {
+		t.Error("compute should create a single entry")
+	}
+
+	// returning false removes the entry
+	if _, ok := c.Compute("n", func(old int, exists bool) (int, bool) {
+		if !exists || old != 4 {
+			t.Error("compute should see the current value")
+		}
+		return 0, false
+	}); ok || c.Lookup("n") != nil {
+		t.Error("entry should be removed")
+	}
+
+	// returning false for a missing key creates nothing
+	c.Compute("m", func(int, bool) (int, bool) { return 1, false })
+	if c.Lookup("m") != nil || checkSLRUCacheSanity(c) {
+		t.Error("no entry should be created")
+	}
+}
+
+// TestComputePanic tests that a panicking fn releases the lock.
+func TestComputePanic(t *testing.T) {
+	c := NewSLRUCache[string, int](3, 3)
+	c.mu = new(sync.RWMutex)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic should be passed on")
+			}
+		}()
+		c.Compute("n", func(int, bool) (int, bool) { panic("fn") })
+	}()
+
+	if v, ok := c.Compute("n", func(old int, exists bool) (int, bool) { return 1, true }); !ok || v != 1 {
+		t.Error("cache should be usable after a panic")
+	}
+}
+
+// TestComputeInsertPath tests that new entries go through admission and write-through.
+func TestComputeInsertPath(t *testing.T) {
+	set := func(string, bool) (string, bool) { return "1", true }
+
+	c := NewSLRUCache[string, string](3, 3)
+	s := &mapStore{m: map[string]string{}}
+	c.SetWriteThrough(s)
+	if v, ok := c.Compute("a", set); !ok || v != "1" || s.m["a"] != "1" {
+		t.Error("computed value should be written through")
+	}
+	s.err = errors.New("unavailable")
+	if _, ok := c.Compute("b", set); ok || c.Lookup("b") != nil {
+		t.Error("failed write should not be cached")
+	}
+
+	c = NewSLRUCache[string, string](3, 3)
+	c.SetAdmission(NewProbabilisticAdmission[string](0, 0, nil))
+	if _, ok := c.Compute("a", set); ok || c.Lookup("a") != nil || c.Stats().Rejections != 1 {
+		t.Error("admission should reject the new entry")
+	}
+}
@@ -0,0 +1,203 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidConfig is returned by NewFromConfig for unusable configurations.
+var ErrInvalidConfig = errors.New("slrucache: invalid config")
+
+// Config configures a cache created by NewFromConfig. The segment sizes are
+// given either by Protected and Probation or by Capacity and ProtectedRatio.
+// A Probation of 0 or a ProtectedRatio of 1 creates a plain LRU cache.
+// Zero values of the remaining fields keep the defaults of NewSLRUCache.
+type Config[K comparable, V any] struct {
+	Protected int // size of the protected segment (lrulist)
+	Probation int // size of the probationary segment (probelist)
+
+	Capacity       int     // total number of entries, alternative to the sizes
+	ProtectedRatio float64 // share of Capacity given to the protected segment, default 0.8
+	ExpectedLoad   int     // number of keys the index is allocated for, see SetExpectedLoad
+	MaxWeight      int64   // limit of the total entry weight, see SetMaxWeight
+	EvictionBatch  int     // minimum evictions for the weight limit, see SetEvictionBatch
+	SoftLimit      int     // number of entries above which lookups evict, see SetLimits
+	HardLimit      int     // number of entries above which inserts evict, see SetLimits
+
+	TTL         time.Duration // time to live of entries, see SetTTL
+	IdleTTL     time.Duration // idle timeout of entries, see SetIdleTTL
+	NegativeTTL time.Duration // time to live of negative entries, see SetNegativeTTL
+
+	Expiration      ExpirationMode // see SetExpiration
+	JanitorInterval time.Duration  // sweep interval of the janitor, see SetExpiration
+
+	Policy          Policy[K, V]          // eviction policy, see SetPolicy
+	ProbationPolicy Policy[K, V]          // eviction policy of the probelist, see SetProbationPolicy
+	Demotion        bool                  // see SetDemotion
+	PromoteHits     int                   // see SetPromotionThreshold
+	PromotionPolicy PromotionPolicy[K, V] // see SetPromotionPolicy
+	Recovery        bool                  // see SetRecovery
+	Hooks           Hooks[K, V]           // see SetHooks
+	Loader          LoaderFunc[K, V]      // see SetLoader
+	Tier            Tier[K, V]            // see SetTier
+	Webhook         *WebhookSink          // see SetWebhook
+	EventBuffer     int                   // see SetEventBuffer
+	Equal           func(a, b V) bool     // see SetEqual
+	Cloner          func(V) V             // see SetCloner
+	Hasher          Hasher[K]             // see SetHasher
+	Sizer           func(V) int           // see SetSizer
+	OpenIndex       bool                  // see SetOpenIndex
+}
+
+// defaultProtectedRatio is the protected share used if only Capacity is set.
+const defaultProtectedRatio = 0.8
+
+// sizes validates the segment configuration and returns the sizes of the
+// protected and probationary segments.
+func (cfg *Config[K, V]) sizes() (int, int, error) {
+	if cfg.Capacity != 0 || cfg.ProtectedRatio != 0 {
+		if cfg.Protected != 0 || cfg.Probation != 0 {
+			return 0, 0, fmt.Errorf("%w: set either Protected and Probation or Capacity and ProtectedRatio", ErrInvalidConfig)
+		}
+		ratio := cfg.ProtectedRatio
+		if ratio == 0 {
+			ratio = defaultProtectedRatio
+		}
+		if ratio <= 0 || ratio > 1 {
+			return 0, 0, fmt.Errorf("%w: ProtectedRatio %v must be greater than 0 and at most 1", ErrInvalidConfig, cfg.ProtectedRatio)
+		}
+		protected := int(float64(cfg.Capacity) * ratio)
+		probation := cfg.Capacity - protected
+		if protected < 1 || (probation < 1 && ratio < 1) {
+			return 0, 0, fmt.Errorf("%w: Capacity %d with ProtectedRatio %v leaves an empty segment", ErrInvalidConfig, cfg.Capacity, ratio)
+		}
+		return protected, probation, nil
+	}
+
+	if err := validateSizes(cfg.Protected, cfg.Probation); err != nil {
+		return 0, 0, err
+	}
+	return cfg.Protected, cfg.Probation, nil
+}
+
+// validateSizes checks the sizes of the protected and probationary segments.
+// The protected segment needs at least one entry, the probationary segment
+// may be empty for a plain LRU cache.
+func validateSizes(protected, probation int) error {
+	if protected < 1 || probation < 0 {
+		return fmt.Errorf("%w: protected size %d must be positive and probation size %d not negative", ErrInvalidConfig, protected, probation)
+	}
+	if protected > MaxEntries-probation {
+		return fmt.Errorf("%w: %d entries exceed MaxEntries", ErrInvalidConfig, protected+probation)
+	}
+	return nil
+}
+
+// validate checks the configuration and returns the segment sizes.
+func (cfg *Config[K, V]) validate() (int, int, error) {
+	protected, probation, err := cfg.sizes()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	switch {
+	case cfg.TTL < 0:
+		err = fmt.Errorf("%w: TTL %v must not be negative", ErrInvalidConfig, cfg.TTL)
+	case cfg.MaxWeight < 0:
+		err = fmt.Errorf("%w: MaxWeight %d must not be negative", ErrInvalidConfig, cfg.MaxWeight)
+	case cfg.SoftLimit < 0 || cfg.HardLimit < 0:
+		err = fmt.Errorf("%w: limits %d/%d must not be negative", ErrInvalidConfig, cfg.SoftLimit, cfg.HardLimit)
+	case cfg.EvictionBatch < 0:
+		err = fmt.Errorf("%w: EvictionBatch %d must not be negative", ErrInvalidConfig, cfg.EvictionBatch)
+	case cfg.IdleTTL < 0:
+		err = fmt.Errorf("%w: IdleTTL %v must not be negative", ErrInvalidConfig, cfg.IdleTTL)
+	case cfg.NegativeTTL < 0:
+		err = fmt.Errorf("%w: NegativeTTL %v must not be negative", ErrInvalidConfig, cfg.NegativeTTL)
+	case cfg.Expiration < ExpireLazy || cfg.Expiration > ExpireHybrid:
+		err = fmt.Errorf("%w: unknown Expiration mode %d", ErrInvalidConfig, cfg.Expiration)
+	case cfg.JanitorInterval < 0:
+		err = fmt.Errorf("%w: JanitorInterval %v must not be negative", ErrInvalidConfig, cfg.JanitorInterval)
+	case cfg.ExpectedLoad < 0:
+		err = fmt.Errorf("%w: ExpectedLoad %d must not be negative", ErrInvalidConfig, cfg.ExpectedLoad)
+	case cfg.PromoteHits < 0:
+		err = fmt.Errorf("%w: PromoteHits %d must not be negative", ErrInvalidConfig, cfg.PromoteHits)
+	case cfg.EventBuffer < 0:
+		err = fmt.Errorf("%w: EventBuffer %d must not be negative", ErrInvalidConfig, cfg.EventBuffer)
+	default:
+		err = cfg.validateCombinations(probation)
+	}
+	return protected, probation, err
+}
+
+// validateCombinations rejects callbacks and settings that never take
+// effect with the rest of the configuration.
+func (cfg *Config[K, V]) validateCombinations(probation int) error {
+	switch {
+	case probation == 0 && cfg.ProbationPolicy != nil:
+		return fmt.Errorf("%w: ProbationPolicy needs a probation segment", ErrInvalidConfig)
+	case probation == 0 && cfg.PromotionPolicy != nil:
+		return fmt.Errorf("%w: PromotionPolicy needs a probation segment", ErrInvalidConfig)
+	case probation == 0 && (cfg.Demotion || cfg.PromoteHits > 0):
+		return fmt.Errorf("%w: Demotion and PromoteHits need a probation segment", ErrInvalidConfig)
+	case cfg.JanitorInterval > 0 && cfg.Expiration == ExpireLazy:
+		return fmt.Errorf("%w: JanitorInterval needs the active or hybrid Expiration mode", ErrInvalidConfig)
+	case cfg.EvictionBatch > 0 && cfg.MaxWeight == 0:
+		return fmt.Errorf("%w: EvictionBatch needs MaxWeight", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// NewFromConfig creates a cache configured by cfg. Invalid configurations
+// are reported as errors wrapping ErrInvalidConfig instead of failing later:
+// negative sizes, TTLs and limits, and combinations that never take
+// effect, such as probation or promotion callbacks without a probation
+// segment or a JanitorInterval with lazy expiration.
+func NewFromConfig[K comparable, V any](cfg Config[K, V]) (*SLRUCache[K, V], error) {
+	protected, probation, err := cfg.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewSLRUCache[K, V](protected, probation)
+	if cfg.ExpectedLoad > 0 {
+		c.mapSize = cfg.ExpectedLoad
+		c.mapping = make(map[K]int, c.mapSize)
+	}
+	if cfg.Policy != nil {
+		c.policy = cfg.Policy
+	}
+	c.probePolicy = cfg.ProbationPolicy
+	c.ttl = cfg.TTL
+	c.idleTTL = cfg.IdleTTL
+	c.negativeTTL = cfg.NegativeTTL
+	c.demote = cfg.Demotion
+	c.promoteHits = cfg.PromoteHits
+	c.promotion = cfg.PromotionPolicy
+	c.maxWeight = cfg.MaxWeight
+	c.evictBatch = max(cfg.EvictionBatch, 1)
+	c.recovery = cfg.Recovery
+	c.hooks = cfg.Hooks
+	c.loader = cfg.Loader
+	c.tier = cfg.Tier
+	c.webhook = cfg.Webhook
+	c.eventBuffer = cfg.EventBuffer
+	c.equal = cfg.Equal
+	c.cloner = cfg.Cloner
+	c.hasher = cfg.Hasher
+	if cfg.Sizer != nil {
+		c.lrulist.sizer, c.probelist.sizer = cfg.Sizer, cfg.Sizer
+	}
+	if cfg.OpenIndex {
+		c.index, c.mapping = newOpenIndex[K](c.cnum, c.hasher), nil
+	}
+	if cfg.SoftLimit > 0 || cfg.HardLimit > 0 {
+		c.SetLimits(cfg.SoftLimit, cfg.HardLimit)
+	}
+	if cfg.Expiration != ExpireLazy {
+		c.SetExpiration(cfg.Expiration, cfg.JanitorInterval)
+	}
+	return c, nil
+}
@@ -0,0 +1,52 @@
+package slrucache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestNewFromConfig tests sizes derived from capacity and ratio and applied options.
+func TestNewFromConfig(t *testing.T) {
+	c, err := NewFromConfig(Config[string, string]{Capacity: 10, TTL: time.Minute, Demotion: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.snum != 8 || c.pnum != 2 || c.ttl != time.Minute || !c.demote {
+		t.Error("config not applied")
+	}
+
+	c, err = NewFromConfig(Config[string, string]{Protected: 3, Probation: 2, Policy: FIFOPolicy[string, string]{}})
+	if err != nil || c.snum != 3 || c.pnum != 2 {
+		t.Fatal("explicit sizes not applied")
+	}
+	insertN(c, 5, 0)
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestNewFromConfigInvalid tests that misconfigurations are reported.
+func TestNewFromConfigInvalid(t *testing.T) {
+	for _, cfg := range []Config[string, string]{
+		{},
+		{Probation: 1},
+		{Protected: 1, Probation: 1, Capacity: 2},
+		{Capacity: 10, ProtectedRatio: 1.5},
+		{Protected: 1, Probation: -1},
+		{Capacity: 1},
+		{Protected: 1, Probation: 1, TTL: -time.Second},
+		{Protected: 1, Probation: 1, NegativeTTL: -time.Second},
+		{Protected: 1, Probation: 1, EventBuffer: -1},
+		{Protected: 1, ProbationPolicy: FIFOPolicy[string, string]{}},
+		{Protected: 1, PromotionPolicy: PromotionFunc[string, string](func(string, string, uint64) Promotion { return Promote })},
+		{Protected: 1, Demotion: true},
+		{Capacity: 10, ProtectedRatio: 1, PromoteHits: 2},
+		{Protected: 1, Probation: 1, JanitorInterval: time.Second},
+		{Protected: 1, Probation: 1, EvictionBatch: 4},
+	} {
+		if _, err := NewFromConfig(cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("config %+v should be invalid, got %v", cfg, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// debugHotKeys is the default number of hottest keys shown by the debug handler.
+const debugHotKeys = 10
+
+// debugSegment describes the occupancy of a segment.
+type debugSegment struct {
+	Name     string `json:"name"`
+	Count    int    `json:"count"`
+	Capacity int    `json:"capacity"`
+}
+
+// debugEntry describes a single entry.
+type debugEntry struct {
+	Key      string    `json:"key"`
+	Segment  string    `json:"segment"`
+	Index    int       `json:"index"`
+	Pins     int       `json:"pins"`
+	Priority string    `json:"priority"`
+	Negative bool      `json:"negative"`
+	Expires  time.Time `json:"expires,omitempty"`
+}
+
+// debugInfo is rendered by the debug handler.
+type debugInfo struct {
+	Capacity int            `json:"capacity"`
+	Entries  int            `json:"entries"`
+	Free     int            `json:"free"`
+	Stats    Stats          `json:"stats"`
+	Segments []debugSegment `json:"segments"`
+	HotKeys  []string       `json:"hot_keys"`
+	Details  []debugEntry   `json:"details,omitempty"`
+}
+
+// DebugHandler returns an http.Handler rendering statistics, segment
+// occupancy and the hottest keys of the cache, meant to be mounted under
+// /debug/slru. Query parameters:
+//   - format=json renders JSON instead of text
+//   - hot=N shows the N hottest keys (default 10)
+//   - entries=1 adds metadata of every entry
+func (c *SLRUCache[K, V]) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		hot := debugHotKeys
+		if h, err := strconv.Atoi(q.Get("hot")); err == nil && h >= 0 {
+			hot = h
+		}
+		info := c.debugInfo(hot, q.Get("entries") == "1")
+
+		if q.Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(info)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintf(w, "entries: %d/%d (free %d)\n", info.Entries, info.Capacity, info.Free)
+		for _, s := range info.Segments {
+			fmt.Fprintf(w, "%s: %d/%d\n", s.Name, s.Count, s.Capacity)
+		}
+		st := info.Stats
+		fmt.Fprintf(w, "\nhits: %d (protected %d, probation %d) misses: %d hit ratio: %.4f\n",
+			st.Hits, st.ProtectedHits, st.ProbationHits, st.Misses, st.HitRatio())
+		fmt.Fprintf(w, "inserts: %d promotions: %d demotions: %d evictions: %d expirations: %d\n",
+			st.Inserts, st.Promotions, st.Demotions, st.Evictions, st.Expirations)
+		fmt.Fprintf(w, "\nhottest keys:\n")
+		for i, k := range info.HotKeys {
+			fmt.Fprintf(w, "%4d %s\n", i+1, k)
+		}
+		if len(info.Details) > 0 {
+			fmt.Fprintf(w, "\nentries:\n")
+			for _, e := range info.Details {
+				fmt.Fprintf(w, "%s segment=%s index=%d pins=%d priority=%s negative=%t",
+					e.Key, e.Segment, e.Index, e.Pins, e.Priority, e.Negative)
+				if !e.Expires.IsZero() {
+					fmt.Fprintf(w, " expires=%s", e.Expires.Format(time.RFC3339))
+				}
+				fmt.Fprintln(w)
+			}
+		}
+	})
+}
+
+// debugInfo collects the data rendered by DebugHandler.
+// The hottest keys are taken from the head of the lrulist, then the probelist.
+func (c *SLRUCache[K, V]) debugInfo(hot int, details bool) debugInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainReads()
+
+	info := debugInfo{
+		Capacity: c.cnum,
+		Entries:  c.indexLen(),
+		Free:     c.freelist.count,
+		Stats:    c.currentStats(),
+		Segments: []debugSegment{
+			{Name: "protected", Count: c.lrulist.count, Capacity: c.snum},
+			{Name: "probation", Count: c.probelist.count, Capacity: c.pnum},
+		},
+	}
+
+	for _, l := range []*SLRUList[K, V]{c.lrulist, c.probelist} {
+		for n := l.head; n >= 0 && len(info.HotKeys) < hot; n = int(c.entries[n].next) {
+			info.HotKeys = append(info.HotKeys, fmt.Sprint(c.entries[n].key))
+		}
+	}
+
+	if details {
+		for _, seg := range []struct {
+			name string
+			l    *SLRUList[K, V]
+		}{{"protected", c.lrulist}, {"probation", c.probelist}} {
+			for n := seg.l.head; n >= 0; n = int(c.entries[n].next) {
+				e := &c.entries[n]
+				info.Details = append(info.Details, debugEntry{
+					Key:      fmt.Sprint(e.key),
+					Segment:  seg.name,
+					Index:    n,
+					Pins:     int(e.pins),
+					Priority: e.prio.String(),
+					Negative: e.negative,
+					Expires:  e.expires,
+				})
+			}
+		}
+	}
+
+	return info
+}
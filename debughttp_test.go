@@ -0,0 +1,34 @@
+package slrucache
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestDebugHandler tests the text and JSON renderings.
+func TestDebugHandler(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 8, 0)
+	lookupN(c, 2, 6)
+
+	rec := httptest.NewRecorder()
+	c.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/slru?entries=1", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "protected: 2/5") || !strings.Contains(body, "   1 7\n") ||
+		!strings.Contains(body, "6 segment=protected") || !strings.Contains(body, "probation 2) misses: 0") {
+		t.Errorf("unexpected text output:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	c.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/slru?format=json&hot=3", nil))
+	var info debugInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Entries != 5 || len(info.HotKeys) != 3 || info.HotKeys[2] != "5" || len(info.Details) != 0 ||
+		info.Stats.Inserts != 8 {
+		t.Errorf("unexpected json output %+v", info)
+	}
+}
@@ -0,0 +1,266 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+)
+
+// diskRecord is one record of a DiskStore file.
+type diskRecord[K comparable, V any] struct {
+	Key     K
+	Value   V
+	Deleted bool
+}
+
+// DiskStore is a simple append-only key/value file used as overflow tier.
+// Every Set appends a record, Delete appends a tombstone; an in-memory
+// index maps keys to the offset of their latest record. The file grows
+// until Compact is called.
+type DiskStore[K comparable, V any] struct {
+	mu    sync.Mutex
+	path  string
+	f     *os.File
+	size  int64       // current file size, offset of the next record
+	index map[K]int64 // key to record offset
+	dead  int         // number of superseded records
+}
+
+// OpenDiskStore opens or creates the store at path and indexes its records.
+// A torn record at the end of the file is dropped. A record failing its
+// checksum before the end fails Open with an error wrapping ErrCorrupted
+// and leaves the file unchanged.
+func OpenDiskStore[K comparable, V any](path string) (*DiskStore[K, V], error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiskStore[K, V]{path: path, f: f, index: make(map[K]int64)}
+	if err := d.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// errChecksum reports a complete record whose checksum does not match.
+var errChecksum = errors.New("slrucache: record checksum mismatch")
+
+// load builds the index from the file, truncating a torn last record. A
+// record failing its checksum before the end of the file fails the load
+// with an error wrapping ErrCorrupted, the records after it are kept.
+func (d *DiskStore[K, V]) load() error {
+	r := bufio.NewReader(d.f)
+	var off int64
+	for {
+		rec, size, err := readDiskRecord[K, V](r)
+		if errors.Is(err, errChecksum) {
+			if _, perr := r.Peek(1); perr != io.EOF {
+				return fmt.Errorf("%w: disk store %s: record at offset %d fails its checksum", ErrCorrupted, d.path, off)
+			}
+			break
+		}
+		if err == io.EOF || errors.Is(err, errTornRecord) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, ok := d.index[rec.Key]; ok {
+			d.dead++
+		}
+		if rec.Deleted {
+			delete(d.index, rec.Key)
+			d.dead++
+		} else {
+			d.index[rec.Key] = off
+		}
+		off += size
+	}
+
+	d.size = off
+	return d.f.Truncate(off)
+}
+
+// readDiskRecord reads one framed record from r and returns it with its size in bytes.
+func readDiskRecord[K comparable, V any](r io.Reader) (*diskRecord[K, V], int64, error) {
+	var frame [8]byte
+	if _, err := io.ReadFull(r, frame[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, 0, errTornRecord
+		}
+		return nil, 0, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(frame[:4]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, 0, errTornRecord
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(frame[4:]) {
+		return nil, 0, errChecksum
+	}
+
+	var rec diskRecord[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+		return nil, 0, err
+	}
+	return &rec, int64(len(frame) + len(payload)), nil
+}
+
+// append writes a framed record at the end of the file and returns its offset.
+// Must be called with d.mu held.
+func (d *DiskStore[K, V]) append(rec *diskRecord[K, V]) (int64, error) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 8))
+	if err := gob.NewEncoder(&buf).Encode(rec); err != nil {
+		return 0, err
+	}
+	b := buf.Bytes()
+	binary.BigEndian.PutUint32(b[:4], uint32(len(b)-8))
+	binary.BigEndian.PutUint32(b[4:8], crc32.ChecksumIEEE(b[8:]))
+
+	off := d.size
+	if _, err := d.f.WriteAt(b, off); err != nil {
+		return 0, err
+	}
+	d.size += int64(len(b))
+	return off, nil
+}
+
+// Get returns the value stored for key.
+func (d *DiskStore[K, V]) Get(key K) (V, bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var zero V
+	if d.f == nil {
+		return zero, false, ErrClosed
+	}
+	off, ok := d.index[key]
+	if !ok {
+		return zero, false, nil
+	}
+
+	rec, _, err := readDiskRecord[K, V](io.NewSectionReader(d.f, off, d.size-off))
+	if err != nil {
+		return zero, false, err
+	}
+	return rec.Value, true, nil
+}
+
+// Set stores value for key.
+func (d *DiskStore[K, V]) Set(key K, value V) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.f == nil {
+		return ErrClosed
+	}
+	off, err := d.append(&diskRecord[K, V]{Key: key, Value: value})
+	if err != nil {
+		return err
+	}
+	if _, ok := d.index[key]; ok {
+		d.dead++
+	}
+	d.index[key] = off
+	return nil
+}
+
+// Delete removes key from the store.
+func (d *DiskStore[K, V]) Delete(key K) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.f == nil {
+		return ErrClosed
+	}
+	if _, ok := d.index[key]; !ok {
+		return nil
+	}
+	if _, err := d.append(&diskRecord[K, V]{Key: key, Deleted: true}); err != nil {
+		return err
+	}
+	delete(d.index, key)
+	d.dead += 2
+	return nil
+}
+
+// Len returns the number of keys in the store.
+func (d *DiskStore[K, V]) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.index)
+}
+
+// Compact rewrites the file keeping only the latest record of each key.
+func (d *DiskStore[K, V]) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.f == nil {
+		return ErrClosed
+	}
+	if d.dead == 0 {
+		return nil
+	}
+
+	tmp := d.path + ".compact"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+
+	nd := &DiskStore[K, V]{path: d.path, f: f, index: make(map[K]int64, len(d.index))}
+	for key, off := range d.index {
+		rec, _, err := readDiskRecord[K, V](io.NewSectionReader(d.f, off, d.size-off))
+		if err == nil {
+			nd.index[key], err = nd.append(rec)
+		}
+		if err != nil {
+			f.Close()
+			os.Remove(tmp)
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, d.path); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	d.f.Close()
+	d.f = f
+	d.size = nd.size
+	d.index = nd.index
+	d.dead = 0
+	return nil
+}
+
+// Close closes the store file. Operations on a closed store return
+// ErrClosed.
+func (d *DiskStore[K, V]) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.f == nil {
+		return ErrClosed
+	}
+	err := d.f.Close()
+	d.f = nil
+	return err
+}
@@ -0,0 +1,59 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Dump writes the internal structure of the cache to w: every list in
+// order from head to tail with entry indices, keys and link pointers,
+// followed by the problems found by VerifyReport. Meant for bug reports.
+func (c *SLRUCache[K, V]) Dump(w io.Writer) error {
+	var buf bytes.Buffer
+
+	c.mu.Lock()
+	c.drainReads()
+	fmt.Fprintf(&buf, "cache: capacity %d, probation %d, protected %d, keys %d\n",
+		c.cnum, c.pnum, c.snum, c.indexLen())
+	c.dumpList(&buf, "freelist", c.freelist, c.cnum)
+	c.dumpList(&buf, "probelist", c.probelist, c.pnum)
+	c.dumpList(&buf, "lrulist", c.lrulist, c.snum)
+	problems := c.verify()
+	c.mu.Unlock()
+
+	for _, p := range problems {
+		fmt.Fprintf(&buf, "problem: %v\n", p)
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// dumpList writes the entries of l from head to tail.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) dumpList(buf *bytes.Buffer, name string, l *SLRUList[K, V], capacity int) {
+	fmt.Fprintf(buf, "%s: count %d/%d head %d tail %d\n", name, l.count, capacity, l.head, l.tail)
+	steps := 0
+	for n := l.head; n >= 0 && n < len(c.entries); n = int(c.entries[n].next) {
+		if steps++; steps > len(c.entries) {
+			buf.WriteString("  ... cycle detected\n")
+			return
+		}
+		e := &c.entries[n]
+		if l == c.freelist {
+			fmt.Fprintf(buf, "  [%d] prev=%d next=%d\n", n, e.prev, e.next)
+			continue
+		}
+		fmt.Fprintf(buf, "  [%d] key=%v prev=%d next=%d prio=%v pins=%d", n, e.key, e.prev, e.next, e.prio, e.pins)
+		if e.negative {
+			buf.WriteString(" negative")
+		}
+		if !e.expires.IsZero() {
+			fmt.Fprintf(buf, " expires=%s", e.expires.Format("2006-01-02T15:04:05.000Z07:00"))
+		}
+		buf.WriteByte('\n')
+	}
+}
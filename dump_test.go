@@ -0,0 +1,27 @@
+package slrucache
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDump tests that every list and entry is written.
+func TestDump(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	insertN(c, 4, 0)
+	lookupN(c, 1, 1)
+
+	var b strings.Builder
+	if err := c.Dump(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, s := range []string{"freelist: count 3/6", "probelist: count 2/3", "lrulist: count 1/3", "key=1 ", "key=3 "} {
+		if !strings.Contains(out, s) {
+			t.Errorf("dump is missing %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "problem") {
+		t.Errorf("unexpected problem:\n%s", out)
+	}
+}
@@ -0,0 +1,118 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"time"
+)
+
+// Segment identifies the part of the cache an entry lives in.
+type Segment int
+
+// Cache segments.
+const (
+	SegmentNone      Segment = iota // not cached
+	SegmentProbation                // probelist
+	SegmentProtected                // lrulist
+)
+
+// String returns the name of the segment.
+func (s Segment) String() string {
+	switch s {
+	case SegmentProbation:
+		return "probation"
+	case SegmentProtected:
+		return "protected"
+	}
+	return "none"
+}
+
+// segmentOf returns the segment of the entry at index n.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) segmentOf(n int) Segment {
+	switch c.entries[n].tag {
+	case tagProbation:
+		return SegmentProbation
+	case tagProtected:
+		return SegmentProtected
+	}
+	return SegmentNone
+}
+
+// listOf returns the list holding the entry at index n or nil.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) listOf(n int) *SLRUList[K, V] {
+	switch c.entries[n].tag {
+	case tagFree:
+		return c.freelist
+	case tagProbation:
+		return c.probelist
+	case tagProtected:
+		return c.lrulist
+	}
+	return nil
+}
+
+// segmentList returns the list of segment s or nil.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) segmentList(s Segment) *SLRUList[K, V] {
+	switch s {
+	case SegmentProbation:
+		return c.probelist
+	case SegmentProtected:
+		return c.lrulist
+	}
+	return nil
+}
+
+// EntryInfo describes a single cache entry.
+type EntryInfo struct {
+	Inserted time.Time     // time the key was inserted
+	Accessed time.Time     // time of the last hit, the insertion time if there was none
+	Accesses uint64        // number of hits since the insertion
+	Segment  Segment       // segment holding the entry
+	TTL      time.Duration // remaining time to live, 0 if the entry does not expire
+	Priority Priority      // eviction priority
+	Negative bool          // entry caches a "not found" result
+}
+
+// EntryInfo returns the metadata of the entry for key and whether it is
+// cached. Expired entries are reported as not cached. Unlike Lookup it
+// neither counts as an access nor moves the entry.
+func (c *SLRUCache[K, V]) EntryInfo(key K) (EntryInfo, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainReads()
+
+	n, ok := c.find(key)
+	if !ok || c.expired(n) {
+		return EntryInfo{}, false
+	}
+
+	e := &c.entries[n]
+	info := EntryInfo{
+		Inserted: e.inserted,
+		Accessed: e.accessed,
+		Accesses: e.accesses,
+		Segment:  c.segmentOf(n),
+		Priority: e.prio,
+		Negative: e.negative,
+	}
+	if d := c.deadline(n); !d.IsZero() {
+		info.TTL = d.Sub(c.now())
+	}
+	return info, true
+}
+
+// SegmentOf returns the segment holding key, SegmentNone if it is not
+// cached or expired. Like EntryInfo it does not count as an access.
+func (c *SLRUCache[K, V]) SegmentOf(key K) Segment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.find(key)
+	if !ok || c.expired(n) {
+		return SegmentNone
+	}
+	return c.segmentOf(n)
+}
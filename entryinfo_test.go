@@ -0,0 +1,56 @@
+package slrucache
+
+import (
+	"testing"
+	"time"
+)
+
+// TestEntryInfo tests the metadata reported for an entry.
+func TestEntryInfo(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	clock := newTestClock(c)
+	c.SetTTL(time.Minute)
+
+	c.Insert("a", "a")
+	inserted := clock.now()
+	info, ok := c.EntryInfo("a")
+	if !ok || info.Segment != SegmentProbation || info.Accesses != 0 || info.TTL != time.Minute {
+		t.Errorf("unexpected info after insert: %+v", info)
+	}
+
+	clock.advance(10 * time.Second)
+	c.Lookup("a")
+	c.Lookup("a")
+	info, _ = c.EntryInfo("a")
+	if info.Segment != SegmentProtected || info.Accesses != 2 || !info.Inserted.Equal(inserted) ||
+		!info.Accessed.Equal(clock.now()) || info.TTL != 50*time.Second {
+		t.Errorf("unexpected info after hits: %+v", info)
+	}
+
+	clock.advance(time.Minute)
+	if _, ok := c.EntryInfo("a"); ok {
+		t.Error("expired entry should not be reported")
+	}
+	if _, ok := c.EntryInfo("b"); ok || SegmentNone.String() != "none" {
+		t.Error("missing entry should not be reported")
+	}
+}
+
+// TestSegmentOf tests that SegmentOf follows promotions without causing them.
+func TestSegmentOf(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	c.Insert("a", "1")
+	if s := c.SegmentOf("a"); s != SegmentProbation {
+		t.Errorf("new entry in %v", s)
+	}
+	if s := c.SegmentOf("a"); s != SegmentProbation {
+		t.Errorf("SegmentOf should not promote, entry in %v", s)
+	}
+	c.Lookup("a")
+	if s := c.SegmentOf("a"); s != SegmentProtected {
+		t.Errorf("promoted entry in %v", s)
+	}
+	if s := c.SegmentOf("missing"); s != SegmentNone {
+		t.Errorf("missing entry in %v", s)
+	}
+}
@@ -0,0 +1,71 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// InternalError wraps ErrCorrupted and reports an internal inconsistency of the cache detected by
+// an operation of the error-returning API, such as LookupE. The cache is
+// left inconsistent and should be discarded; enable SetRecovery to have it
+// repaired instead, which makes the operations report a miss or drop the
+// insert without error.
+type InternalError struct {
+	Msg string // description of the inconsistency
+}
+
+// Error returns the description of the inconsistency.
+func (e *InternalError) Error() string {
+	return "slrucache: internal error: " + e.Msg
+}
+
+// Unwrap returns ErrCorrupted.
+func (e *InternalError) Unwrap() error {
+	return ErrCorrupted
+}
+
+// LookupE is Lookup returning an InternalError instead of panicking on an
+// internal inconsistency, letting the caller decide whether to crash.
+func (c *SLRUCache[K, V]) LookupE(key K) (v *V, err error) {
+	defer c.catchFatal(&err)
+	return c.Lookup(key), nil
+}
+
+// GetE is Get returning ErrNotFound on a miss, the error of the
+// read-through store and an InternalError instead of panicking.
+func (c *SLRUCache[K, V]) GetE(key K) (v V, err error) {
+	defer c.catchFatal(&err)
+	return c.get(key)
+}
+
+// InsertE is Insert returning ErrCapacityExceeded if the entry was dropped
+// because the target segment is pinned completely, ErrRejected if the
+// admission control rejected it, the error of the write-through store, and
+// an InternalError instead of panicking.
+func (c *SLRUCache[K, V]) InsertE(key K, value V) (err error) {
+	defer c.catchFatal(&err)
+	return c.insert(key, value, entryOptions{})
+}
+
+// RemoveE is Remove returning ErrNotFound if key was not cached and an
+// InternalError instead of panicking.
+func (c *SLRUCache[K, V]) RemoveE(key K) (err error) {
+	defer c.catchFatal(&err)
+	if !c.Remove(key) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// catchFatal is deferred by the error-returning API. It turns a fatalPanic
+// into an InternalError and releases the mutex the panic left held; other
+// panics are passed on.
+func (c *SLRUCache[K, V]) catchFatal(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	p, ok := r.(fatalPanic)
+	if !ok {
+		panic(r)
+	}
+	c.mu.Unlock()
+	*err = &InternalError{Msg: string(p)}
+}
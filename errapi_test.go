@@ -0,0 +1,50 @@
+package slrucache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestErrorAPI tests that inconsistencies are returned as errors.
+func TestErrorAPI(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.mu = new(sync.RWMutex)
+	if err := c.InsertE("a", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := c.LookupE("a"); err != nil || v == nil || *v != "a" {
+		t.Fatalf("LookupE = %v, %v", v, err)
+	}
+
+	// Lose all free entries
+	c.freelist = NewSLRUList(&c.entries, tagFree)
+	var ie *InternalError
+	err := c.InsertE("x", "x")
+	if !errors.As(err, &ie) || !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("InsertE error %v, want InternalError", err)
+	}
+
+	// The mutex was released
+	if _, err := c.GetE("a"); err != nil {
+		t.Errorf("GetE after error: %v", err)
+	}
+	if err := c.RemoveE("a"); err != nil {
+		t.Errorf("RemoveE: %v", err)
+	}
+	if _, err := c.GetE("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetE of removed key: %v", err)
+	}
+	if err := c.RemoveE("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RemoveE of removed key: %v", err)
+	}
+}
+
+// TestInsertECapacity tests that dropped inserts report ErrCapacityExceeded.
+func TestInsertECapacity(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	c.InsertWithOptions("a", "a", WithPin())
+	if err := c.InsertE("b", "b"); !errors.Is(err, ErrCapacityExceeded) {
+		t.Errorf("InsertE into pinned probelist: %v", err)
+	}
+}
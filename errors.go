@@ -0,0 +1,31 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"errors"
+)
+
+// Errors of the error-returning and loader based APIs, to be tested with
+// errors.Is.
+var (
+	// ErrNotFound reports a missing key. Loader based calls return it for
+	// keys cached as negative entries; loaders return it to report a
+	// missing key, which is then cached as negative entry if a negative
+	// TTL is set.
+	ErrNotFound = errors.New("slrucache: not found")
+
+	// ErrCapacityExceeded reports an insert dropped because no entry could
+	// be evicted, i.e. the target segment is pinned completely.
+	ErrCapacityExceeded = errors.New("slrucache: capacity exceeded")
+
+	// ErrRejected reports an insert rejected by the admission control, see
+	// SetAdmission.
+	ErrRejected = errors.New("slrucache: rejected by admission")
+
+	// ErrClosed reports the use of a closed store.
+	ErrClosed = errors.New("slrucache: closed")
+
+	// ErrCorrupted reports an internal inconsistency, see InternalError.
+	ErrCorrupted = errors.New("slrucache: corrupted")
+)
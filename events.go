@@ -0,0 +1,87 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// DefaultEventBuffer is the default capacity of the event channel.
+const DefaultEventBuffer = 1024
+
+// CacheEventKind is the kind of a cache event.
+type CacheEventKind int
+
+// Cache event kinds.
+const (
+	EventInsert  CacheEventKind = iota // new entry inserted into probation
+	EventPromote                       // entry promoted from probation to protected
+	EventDemote                        // protected victim demoted into probation
+	EventEvict                         // entry evicted for capacity
+	EventRemove                        // entry removed by Remove
+	EventExpire                        // expired entry dropped
+)
+
+// String returns the name of the event kind.
+func (k CacheEventKind) String() string {
+	switch k {
+	case EventInsert:
+		return "insert"
+	case EventPromote:
+		return "promote"
+	case EventDemote:
+		return "demote"
+	case EventEvict:
+		return "evict"
+	case EventRemove:
+		return "remove"
+	case EventExpire:
+		return "expire"
+	}
+	return "unknown"
+}
+
+// CacheEvent describes a change of the cache contents.
+type CacheEvent[K comparable] struct {
+	Kind CacheEventKind
+	Key  K
+}
+
+// SetEventBuffer sets the capacity of the event channel created by Events.
+// It has no effect once Events has been called.
+func (c *SLRUCache[K, V]) SetEventBuffer(n int) {
+	c.mu.Lock()
+	c.eventBuffer = n
+	c.mu.Unlock()
+}
+
+// Events returns a channel receiving insert, promote, demote, evict,
+// remove and expire events. The channel is created on the first call,
+// events are only generated from then on. Sending never blocks the cache:
+// if the buffer is full the event is dropped and counted, see DroppedEvents.
+func (c *SLRUCache[K, V]) Events() <-chan CacheEvent[K] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.events == nil {
+		size := c.eventBuffer
+		if size <= 0 {
+			size = DefaultEventBuffer
+		}
+		c.events = make(chan CacheEvent[K], size)
+	}
+	return c.events
+}
+
+// DroppedEvents returns the number of events dropped because the channel was full.
+func (c *SLRUCache[K, V]) DroppedEvents() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.droppedEvents
+}
+
+// emit sends an event without blocking.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) emit(kind CacheEventKind, key K) {
+	select {
+	case c.events <- CacheEvent[K]{Kind: kind, Key: key}:
+	default:
+		c.droppedEvents++
+	}
+}
@@ -0,0 +1,37 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestEvents tests the emitted events and the drop counter.
+func TestEvents(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	c.SetEventBuffer(6)
+	events := c.Events()
+
+	c.Insert("a", "a")
+	c.Lookup("a")
+	c.Insert("b", "b")
+	c.Lookup("b") // evicts a from protected
+	c.Insert("c", "c")
+	c.Remove("b")
+	c.Insert("d", "d") // evicts c, buffer full
+
+	want := []CacheEvent[string]{
+		{EventInsert, "a"},
+		{EventPromote, "a"},
+		{EventInsert, "b"},
+		{EventEvict, "a"},
+		{EventPromote, "b"},
+		{EventInsert, "c"},
+	}
+	for _, w := range want {
+		if e := <-events; e != w {
+			t.Errorf("got %v %s, want %v %s", e.Kind, e.Key, w.Kind, w.Key)
+		}
+	}
+	if c.DroppedEvents() != 3 {
+		t.Errorf("expected 3 dropped events, got %d", c.DroppedEvents())
+	}
+}
@@ -0,0 +1,166 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"time"
+)
+
+// SetTTL sets the time to live of entries inserted afterwards.
+// Expired entries are dropped on their next Lookup. 0 disables expiration.
+func (c *SLRUCache[K, V]) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.ttl = ttl
+	c.mu.Unlock()
+}
+
+// expiry returns the expiration time for an entry inserted now with
+// options o. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) expiry(o entryOptions) time.Time {
+	ttl := c.ttl
+	switch {
+	case o.hasTTL && !o.deadline.IsZero():
+		return o.deadline
+	case o.hasTTL:
+		ttl = o.ttl
+	case o.negative:
+		ttl = c.negativeTTL
+	}
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return c.now().Add(ttl)
+}
+
+// setEntryTTL records the own TTL or deadline of o for the entry at index
+// n, TouchTTL restarts it. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) setEntryTTL(n int, o entryOptions) {
+	switch {
+	case !o.hasTTL:
+		delete(c.entryTTLs, n)
+		return
+	case c.entryTTLs == nil:
+		c.entryTTLs = make(map[int]time.Duration)
+	}
+	if o.deadline.IsZero() {
+		c.entryTTLs[n] = o.ttl
+	} else {
+		c.entryTTLs[n] = -1
+	}
+}
+
+// ExpireAt sets the time the entry for key expires, the zero time removes
+// the expiration. A time in the past expires the entry right away.
+// Returns false if the key is not cached.
+func (c *SLRUCache[K, V]) ExpireAt(key K, t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.find(key)
+	if !ok || c.expired(n) {
+		return false
+	}
+	e := &c.entries[n]
+	e.expires = t
+	c.setEntryTTL(n, entryOptions{hasTTL: true, deadline: t})
+	if c.mlog != nil {
+		c.logMutation(logOp(e.negative), n, key)
+	}
+	return true
+}
+
+// TouchTTL is Touch additionally restarting the time to live of the entry
+// as if it had just been inserted: its own TTL if it was inserted with
+// WithTTL, otherwise the one set by SetTTL. Deadlines are kept.
+func (c *SLRUCache[K, V]) TouchTTL(key K) bool {
+	c.mu.Lock()
+	if n, ok := c.find(key); ok && !c.expired(n) && !c.entries[n].negative {
+		var o entryOptions
+		if ttl, ok := c.entryTTLs[n]; ok {
+			o = entryOptions{ttl: ttl, hasTTL: true}
+			if ttl < 0 {
+				o.deadline = c.entries[n].expires
+			}
+		}
+		e := &c.entries[n]
+		e.expires = c.expiry(o)
+		if c.mlog != nil {
+			c.logMutation(logInsert, n, key)
+		}
+	}
+	c.mu.Unlock()
+
+	return c.Touch(key)
+}
+
+// InvalidateAll invalidates all entries in constant time by starting a new
+// epoch. Entries written before are treated as expired: lookups miss them
+// and they are reclaimed lazily when accessed, evicted or swept, see
+// SetExpiration. Until then they still occupy their slots. The mutation
+// log does not record the invalidation.
+func (c *SLRUCache[K, V]) InvalidateAll() {
+	c.mu.Lock()
+	c.epoch++
+	if c.victims != nil {
+		c.victims.reset()
+	}
+	c.mu.Unlock()
+}
+
+// SetIdleTTL sets an idle timeout: entries not looked up for ttl expire,
+// every hit extends the lifetime of an entry by ttl. It applies to all
+// entries except negative ones and combines with SetTTL, whichever
+// deadline comes first expires the entry. 0 disables the idle timeout.
+// While set, lookups take the exclusive lock also with concurrent reads.
+func (c *SLRUCache[K, V]) SetIdleTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.idleTTL = ttl
+	c.mu.Unlock()
+}
+
+// deadline returns the time the entry at index n expires, the earlier of
+// its write and idle deadline, or zero if it does not expire.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) deadline(n int) time.Time {
+	return c.deadlineAt(n, c.entries[n].accessed)
+}
+
+// hitDeadline returns the deadline of the entry at index n after a hit,
+// which renews the idle deadline. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) hitDeadline(n int) time.Time {
+	return c.deadlineAt(n, c.now())
+}
+
+// deadlineAt returns the deadline of the entry at index n if it was last
+// accessed at accessed. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) deadlineAt(n int, accessed time.Time) time.Time {
+	e := &c.entries[n]
+	if c.idleTTL <= 0 || e.negative {
+		return e.expires
+	}
+	idle := accessed.Add(c.idleTTL)
+	if e.expires.IsZero() || idle.Before(e.expires) {
+		return idle
+	}
+	return e.expires
+}
+
+// expired reports whether the entry at index n has expired or was
+// invalidated by InvalidateAll.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) expired(n int) bool {
+	if c.entries[n].epoch != c.epoch {
+		return true
+	}
+	d := c.deadline(n)
+	return !d.IsZero() && !c.now().Before(d)
+}
+
+// GetWithExpiry is Get that also returns the time the entry expires after
+// this hit, for example to derive a Cache-Control max-age. The time is
+// zero if the entry does not expire or was not found.
+func (c *SLRUCache[K, V]) GetWithExpiry(key K) (V, time.Time, bool) {
+	var out lookupOut[V]
+	_, state := c.lookup(key, &out)
+	return out.value, out.expires, state == LookupHit
+}
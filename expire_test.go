@@ -0,0 +1,177 @@
+package slrucache
+
+import (
+	"testing"
+	"time"
+)
+
+// TestIdleTTL tests that hits keep entries alive and idle entries expire.
+func TestIdleTTL(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	clock := newTestClock(c)
+	c.SetIdleTTL(time.Minute)
+	c.SetConcurrentReads(true)
+
+	c.Insert("active", "a")
+	c.Insert("idle", "i")
+	for i := 0; i < 5; i++ {
+		clock.advance(30 * time.Second)
+		if c.Lookup("active") == nil {
+			t.Fatalf("active entry expired after %d hits", i)
+		}
+	}
+	if c.Lookup("idle") != nil {
+		t.Error("idle entry should have expired")
+	}
+	if info, ok := c.EntryInfo("active"); !ok || info.TTL != time.Minute {
+		t.Errorf("remaining TTL %v", info.TTL)
+	}
+
+	// The write TTL still bounds the lifetime of active entries
+	c.SetTTL(90 * time.Second)
+	c.Insert("bounded", "b")
+	for i := 0; i < 3; i++ {
+		clock.advance(40 * time.Second)
+		if got := c.Lookup("bounded") != nil; got != (i < 2) {
+			t.Errorf("step %d: found %v", i, got)
+		}
+	}
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestGetWithExpiry tests the reported expiration times.
+func TestGetWithExpiry(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	clock := newTestClock(c)
+
+	c.Insert("eternal", "e")
+	if v, exp, ok := c.GetWithExpiry("eternal"); !ok || v != "e" || !exp.IsZero() {
+		t.Errorf("got %q, %v, %v", v, exp, ok)
+	}
+
+	c.SetTTL(time.Hour)
+	c.Insert("k", "v")
+	start := clock.now()
+	if _, exp, ok := c.GetWithExpiry("k"); !ok || !exp.Equal(start.Add(time.Hour)) {
+		t.Errorf("expires %v", exp)
+	}
+
+	// The idle deadline counts from the hit
+	c.SetIdleTTL(time.Minute)
+	clock.advance(30 * time.Second)
+	c.SetConcurrentReads(true)
+	for i := 0; i < 2; i++ {
+		if _, exp, _ := c.GetWithExpiry("k"); !exp.Equal(clock.now().Add(time.Minute)) {
+			t.Errorf("idle expires %v", exp)
+		}
+	}
+
+	if _, exp, ok := c.GetWithExpiry("missing"); ok || !exp.IsZero() {
+		t.Error("miss should report no expiry")
+	}
+}
+
+// TestExpireAt tests absolute deadlines set on insert and afterwards.
+func TestExpireAt(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	clock := newTestClock(c)
+	deadline := clock.now().Add(time.Minute)
+
+	c.InsertWithOptions("k", "v", WithDeadline(deadline))
+	if _, exp, _ := c.GetWithExpiry("k"); !exp.Equal(deadline) {
+		t.Errorf("expires %v", exp)
+	}
+	if !c.ExpireAt("k", time.Time{}) {
+		t.Fatal("ExpireAt failed")
+	}
+	clock.advance(time.Hour)
+	if c.Lookup("k") == nil {
+		t.Error("expiration should be removed")
+	}
+
+	c.ExpireAt("k", clock.now().Add(-time.Second))
+	if c.Lookup("k") != nil || c.ExpireAt("k", deadline) {
+		t.Error("past deadline should expire the entry")
+	}
+}
+
+// TestTouchTTL tests that TouchTTL restarts the time to live.
+func TestTouchTTL(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	clock := newTestClock(c)
+	c.SetTTL(time.Minute)
+
+	c.Insert("touched", "t")
+	c.Insert("plain", "p")
+	clock.advance(40 * time.Second)
+	if !c.TouchTTL("touched") || !c.Touch("plain") {
+		t.Fatal("Touch should hit both entries")
+	}
+	clock.advance(40 * time.Second)
+	if c.Lookup("touched") == nil {
+		t.Error("touched entry should live on")
+	}
+	if c.Lookup("plain") != nil {
+		t.Error("Touch should not extend the TTL")
+	}
+	if c.TouchTTL("plain") {
+		t.Error("TouchTTL should miss expired entries")
+	}
+}
+
+// TestTouchTTLEntry tests that TouchTTL restarts the own TTL of an entry and keeps deadlines.
+func TestTouchTTLEntry(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	clock := newTestClock(c)
+
+	c.InsertWithOptions("ttl", "t", WithTTL(time.Minute))
+	c.InsertWithOptions("deadline", "d", WithDeadline(clock.now().Add(time.Minute)))
+	clock.advance(40 * time.Second)
+	if !c.TouchTTL("ttl") || !c.TouchTTL("deadline") {
+		t.Fatal("TouchTTL should hit both entries")
+	}
+	clock.advance(40 * time.Second)
+	if c.Lookup("ttl") == nil {
+		t.Error("own TTL should be restarted")
+	}
+	if c.Lookup("deadline") != nil {
+		t.Error("deadline should be kept")
+	}
+}
+
+// TestInvalidateAll tests that entries of older epochs miss and are
+// reclaimed lazily.
+func TestInvalidateAll(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.Insert("a", "a")
+	c.Insert("b", "b")
+	c.Lookup("b")
+	c.Insert("c", "c")
+
+	c.InvalidateAll()
+	c.Insert("c", "c2")
+	c.Insert("d", "d")
+
+	if c.Lookup("a") != nil || c.Lookup("b") != nil {
+		t.Error("invalidated entries should miss")
+	}
+	if v := c.Lookup("c"); v == nil || *v != "c2" {
+		t.Error("entries written after InvalidateAll should hit")
+	}
+	if c.Lookup("d") == nil {
+		t.Error("new entries should hit")
+	}
+	if c.indexLen() != 2 {
+		t.Errorf("%d entries left, want the invalidated ones reclaimed", c.indexLen())
+	}
+
+	clone := c.Clone()
+	if clone.Lookup("d") == nil {
+		t.Error("clone should keep the epoch")
+	}
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,144 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// Hasher hashes and compares keys. It lets sharding and indexing use a
+// user controlled hash, for example for keys where generic hashing is a
+// bottleneck. Equal keys must have equal hashes.
+type Hasher[K comparable] interface {
+	Hash(key K) uint64
+	Equal(a, b K) bool
+}
+
+// HasherFunc adapts a hash function to a Hasher comparing keys with ==.
+type HasherFunc[K comparable] func(key K) uint64
+
+// Hash calls f.
+func (f HasherFunc[K]) Hash(key K) uint64 {
+	return f(key)
+}
+
+// Equal compares a and b with ==.
+func (f HasherFunc[K]) Equal(a, b K) bool {
+	return a == b
+}
+
+// DefaultHasher hashes strings, numbers and booleans directly and any other
+// key by walking its fields with reflection, which is slower; supply a
+// Hasher for such keys on hot paths. Floats are hashed by value, so -0 and
+// +0 hash alike, as do all NaNs.
+type DefaultHasher[K comparable] struct {
+	seed maphash.Seed
+}
+
+// NewDefaultHasher creates a DefaultHasher with a random seed.
+func NewDefaultHasher[K comparable]() DefaultHasher[K] {
+	return DefaultHasher[K]{seed: maphash.MakeSeed()}
+}
+
+// Hash returns the hash of key.
+func (h DefaultHasher[K]) Hash(key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(h.seed, k)
+	case int:
+		return mix64(uint64(k))
+	case int64:
+		return mix64(uint64(k))
+	case uint64:
+		return mix64(k)
+	case int32:
+		return mix64(uint64(k))
+	case uint32:
+		return mix64(uint64(k))
+	case float64:
+		return mix64(floatBits(k))
+	case float32:
+		return mix64(floatBits(float64(k)))
+	}
+	var mh maphash.Hash
+	mh.SetSeed(h.seed)
+	hashValue(&mh, reflect.ValueOf(key))
+	return mh.Sum64()
+}
+
+// hashValue writes the parts of v compared by == to mh.
+func hashValue(mh *maphash.Hash, v reflect.Value) {
+	var b [8]byte
+	word := func(x uint64) {
+		binary.LittleEndian.PutUint64(b[:], x)
+		mh.Write(b[:])
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		word(uint64(v.Len()))
+		mh.WriteString(v.String())
+	case reflect.Bool:
+		if v.Bool() {
+			word(1)
+		} else {
+			word(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		word(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		word(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		word(floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		word(floatBits(real(v.Complex())))
+		word(floatBits(imag(v.Complex())))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(mh, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(mh, v.Field(i))
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			word(0)
+			return
+		}
+		mh.WriteString(v.Elem().Type().String())
+		hashValue(mh, v.Elem())
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		word(uint64(v.Pointer()))
+	}
+}
+
+// floatBits returns the bits of f with -0 mapped to +0 and all NaNs to one
+// NaN, so floats equal by == have equal bits.
+func floatBits(f float64) uint64 {
+	switch {
+	case f == 0:
+		return 0
+	case f != f:
+		return math.Float64bits(math.NaN())
+	}
+	return math.Float64bits(f)
+}
+
+// Equal compares a and b with ==.
+func (h DefaultHasher[K]) Equal(a, b K) bool {
+	return a == b
+}
+
+// mix64 scrambles the bits of an integer key (splitmix64 finalizer).
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
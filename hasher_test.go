@@ -0,0 +1,90 @@
+package slrucache
+
+import (
+	"math"
+	"testing"
+)
+
+type point struct{ x, y int }
+
+// TestDefaultHasher tests hash stability for built-in and other keys.
+func TestDefaultHasher(t *testing.T) {
+	h := NewDefaultHasher[string]()
+	if h.Hash("a") != h.Hash("a") {
+		t.Error("equal keys hash differently")
+	}
+	if h.Hash("a") == h.Hash("b") {
+		t.Error("hash collision for a and b")
+	}
+	if !h.Equal("a", "a") || h.Equal("a", "b") {
+		t.Error("Equal failure")
+	}
+
+	p := NewDefaultHasher[point]()
+	if p.Hash(point{1, 2}) != p.Hash(point{1, 2}) || p.Hash(point{1, 2}) == p.Hash(point{2, 1}) {
+		t.Error("struct keys hash wrongly")
+	}
+}
+
+// TestDefaultHasherFloats tests that floats equal by == hash alike, also inside other keys.
+func TestDefaultHasherFloats(t *testing.T) {
+	negZero, nan := math.Copysign(0, -1), math.NaN()
+
+	f := NewDefaultHasher[float64]()
+	if f.Hash(negZero) != f.Hash(0) || f.Hash(nan) != f.Hash(-nan) || f.Hash(1) == f.Hash(2) {
+		t.Error("float keys hash wrongly")
+	}
+
+	type pos struct {
+		name string
+		x    float32
+	}
+	p := NewDefaultHasher[pos]()
+	if p.Hash(pos{"a", float32(negZero)}) != p.Hash(pos{"a", 0}) || p.Hash(pos{"a", 0}) == p.Hash(pos{"b", 0}) {
+		t.Error("struct keys with floats hash wrongly")
+	}
+
+	a := NewDefaultHasher[any]()
+	if a.Hash(negZero) != a.Hash(0.0) || a.Hash(1.0) == a.Hash(1) {
+		t.Error("interface keys hash wrongly")
+	}
+}
+
+// TestStripedCacheHasher tests routing by a custom hasher.
+func TestStripedCacheHasher(t *testing.T) {
+	// Route keys by x only, so keys with equal x share a stripe
+	h := HasherFunc[point](func(k point) uint64 { return uint64(k.x) })
+	s := NewStripedCacheHasher[point, int](4, 16, 16, h)
+
+	for i := 0; i < 8; i++ {
+		s.Insert(point{i, i}, i)
+	}
+	for i := 0; i < 8; i++ {
+		if s.Stripe(point{i, i}) != s.Stripes()[i%4] {
+			t.Errorf("key %d in wrong stripe", i)
+		}
+		if s.Stripe(point{i, 100}) != s.Stripe(point{i, i}) {
+			t.Errorf("keys with x %d in different stripes", i)
+		}
+		if v, ok := s.Get(point{i, i}); !ok || v != i {
+			t.Errorf("Get(%d) = %d, %v", i, v, ok)
+		}
+	}
+	if s.Stripes()[0].hasher == nil {
+		t.Error("hasher not passed to the stripes")
+	}
+}
+
+// TestSetHasher tests setting, cloning and resetting the hasher.
+func TestSetHasher(t *testing.T) {
+	c := NewSLRUCache[string, int](4, 4)
+	h := NewDefaultHasher[string]()
+	c.SetHasher(h)
+	if c.Clone().hasher == nil {
+		t.Error("hasher not cloned")
+	}
+	c.SetHasher(nil)
+	if c.hasher != nil {
+		t.Error("hasher not reset")
+	}
+}
@@ -0,0 +1,89 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Hooks receives notifications for cross-cutting instrumentation such as
+// metrics, tracing or logging. Hooks are called after the cache mutex has
+// been released, from the goroutine performing the operation.
+type Hooks[K comparable, V any] interface {
+	// OnHit is called when a lookup finds key, including negative entries.
+	OnHit(key K)
+	// OnMiss is called when a lookup does not find key.
+	OnMiss(key K)
+	// OnInsert is called when a value is inserted or updated.
+	OnInsert(key K, value V)
+	// OnEvict is called when an entry is evicted for capacity.
+	OnEvict(key K, value V)
+}
+
+// SetHooks attaches hooks to the cache. Pass nil to detach them.
+func (c *SLRUCache[K, V]) SetHooks(h Hooks[K, V]) {
+	c.mu.Lock()
+	c.hooks = h
+	c.mu.Unlock()
+}
+
+// evictions are the evicted entries of an operation, delivered after the
+// mutex has been released.
+type evictions[K comparable, V any] struct {
+	entries  []KV[K, V] // entries for the hooks
+	recycled []V        // values for the recycler
+	spilled  []KV[K, V] // entries to write to the tier
+	tier     Tier[K, V]
+}
+
+// takeEvicted returns and clears the evictions pending delivery.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) takeEvicted() evictions[K, V] {
+	ev := evictions[K, V]{entries: c.evicted, recycled: c.recycled, spilled: c.spilled, tier: c.tier}
+	c.evicted, c.recycled, c.spilled = nil, nil, nil
+	return ev
+}
+
+// deliverEvicted writes the spilled entries to the tier, then calls
+// OnEvict for evicted entries and hands the values no longer referenced
+// to the recycler. h and recycle may be nil. The calls are queued if
+// SetAsyncCallbacks is enabled. Must be called without the mutex held.
+func (c *SLRUCache[K, V]) deliverEvicted(h Hooks[K, V], recycle func(V), ev evictions[K, V]) {
+	if len(ev.spilled) > 0 {
+		c.spill(ev.tier, ev.spilled)
+	}
+	if h == nil {
+		ev.entries = nil
+	}
+	if recycle == nil {
+		ev.recycled = nil
+	}
+	if len(ev.entries) == 0 && len(ev.recycled) == 0 {
+		return
+	}
+	if q := c.async.Load(); q != nil && q.enqueue(callbackBatch[K, V]{hooks: h, recycle: recycle, evicted: ev.entries, recycled: ev.recycled}) {
+		return
+	}
+	c.deliverNow(h, recycle, ev.entries, ev.recycled)
+}
+
+// deliverRemoved calls removeCb for the removed keys, from the callback
+// queue if SetAsyncCallbacks is enabled. Must be called without the mutex
+// held.
+func (c *SLRUCache[K, V]) deliverRemoved(removeCb func(K), keys ...K) {
+	if removeCb == nil || len(keys) == 0 {
+		return
+	}
+	if q := c.async.Load(); q != nil && q.enqueue(callbackBatch[K, V]{removeCb: removeCb, removed: keys}) {
+		return
+	}
+	for _, key := range keys {
+		removeCb(key)
+	}
+}
+
+// deliverNow is deliverEvicted calling the callbacks synchronously.
+func (c *SLRUCache[K, V]) deliverNow(h Hooks[K, V], recycle func(V), evicted []KV[K, V], recycled []V) {
+	for _, kv := range evicted {
+		h.OnEvict(kv.Key, kv.Value)
+	}
+	for _, v := range recycled {
+		recycle(v)
+	}
+}
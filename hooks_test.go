@@ -0,0 +1,44 @@
+package slrucache
+
+import (
+	"fmt"
+	"testing"
+)
+
+// recordingHooks records all hook calls.
+type recordingHooks struct {
+	calls []string
+}
+
+func (h *recordingHooks) OnHit(key string)  { h.calls = append(h.calls, "hit "+key) }
+func (h *recordingHooks) OnMiss(key string) { h.calls = append(h.calls, "miss "+key) }
+func (h *recordingHooks) OnInsert(key string, value string) {
+	h.calls = append(h.calls, fmt.Sprintf("insert %s=%s", key, value))
+}
+func (h *recordingHooks) OnEvict(key string, value string) {
+	h.calls = append(h.calls, fmt.Sprintf("evict %s=%s", key, value))
+}
+
+// TestHooks tests the order and content of hook calls.
+func TestHooks(t *testing.T) {
+	h := &recordingHooks{}
+	c := NewSLRUCache[string, string](1, 1)
+	c.SetHooks(h)
+
+	c.Insert("a", "1")
+	c.Lookup("a")
+	c.Insert("b", "2")
+	c.Lookup("b") // evicts a
+	c.Lookup("a")
+	c.Insert("c", "3")
+	c.Insert("d", "4") // evicts c
+	c.Insert("d", "5")
+
+	want := []string{
+		"insert a=1", "hit a", "insert b=2", "evict a=1", "hit b", "miss a",
+		"insert c=3", "evict c=3", "insert d=4", "insert d=5",
+	}
+	if !equalKeys(h.calls, want) {
+		t.Errorf("got %v, want %v", h.calls, want)
+	}
+}
@@ -0,0 +1,191 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// openIndex is an open-addressed hash index over the entries array, used
+// instead of the map if enabled by SetOpenIndex. Slots hold an entry index
+// plus one, 0 marks an empty slot. Keys are not stored in the index but
+// read from the entries, so each key is held only once. The table has at
+// least twice as many slots as the cache has entries and never grows.
+// Collisions are resolved by linear probing with backward shift deletion.
+type openIndex[K comparable] struct {
+	slots  []uint32
+	mask   uint64
+	count  int
+	hasher Hasher[K]
+}
+
+// newOpenIndex creates an empty openIndex for a cache of capacity entries.
+func newOpenIndex[K comparable](capacity int, hasher Hasher[K]) *openIndex[K] {
+	size := 8
+	for size < 2*capacity {
+		size <<= 1
+	}
+	if hasher == nil {
+		hasher = NewDefaultHasher[K]()
+	}
+	return &openIndex[K]{
+		slots:  make([]uint32, size),
+		mask:   uint64(size - 1),
+		hasher: hasher,
+	}
+}
+
+// SetOpenIndex switches the key index between the built-in map (the
+// default) and an open-addressed table over the entries array. The table
+// avoids the per key overhead of the map and the second copy of each key,
+// at the price of hashing keys with the hasher set by SetHasher. Supply a
+// hasher for composite key types, the DefaultHasher hashes them by
+// reflection. SetExpectedLoad has no effect on the table.
+func (c *SLRUCache[K, V]) SetOpenIndex(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if enabled == (c.index != nil) {
+		return
+	}
+
+	if !enabled {
+		mapping := make(map[K]int, max(c.mapSize, c.indexLen()))
+		c.rangeIndex(func(key K, n int) {
+			mapping[key] = n
+		})
+		c.index, c.mapping = nil, mapping
+		return
+	}
+
+	mapping := c.mapping
+	c.index, c.mapping = newOpenIndex[K](c.cnum, c.hasher), nil
+	for key, n := range mapping {
+		c.setIndex(key, n)
+	}
+}
+
+// find returns the entry index of key.
+// Must be called with the mutex held, shared suffices.
+func (c *SLRUCache[K, V]) find(key K) (int, bool) {
+	if c.index == nil {
+		n, ok := c.mapping[key]
+		return n, ok
+	}
+
+	x := c.index
+	i := x.hasher.Hash(key) & x.mask
+	for range x.slots {
+		s := x.slots[i]
+		if s == 0 {
+			break
+		}
+		if x.hasher.Equal(c.entries[s-1].key, key) {
+			return int(s - 1), true
+		}
+		i = (i + 1) & x.mask
+	}
+	return SLRU_EOF, false
+}
+
+// setIndex maps key to the entry at index n, whose key and value must
+// already be set.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) setIndex(key K, n int) {
+	for _, x := range c.valueIndexes {
+		x.add(key, c.entries[n].value)
+	}
+	if c.index == nil {
+		c.mapping[key] = n
+		return
+	}
+
+	x := c.index
+	i := x.hasher.Hash(key) & x.mask
+	for range x.slots {
+		s := x.slots[i]
+		if s == 0 {
+			x.count++
+			break
+		}
+		if x.hasher.Equal(c.entries[s-1].key, key) {
+			break
+		}
+		i = (i + 1) & x.mask
+	}
+	x.slots[i] = uint32(n + 1)
+}
+
+// deleteIndex removes key from the index. The entry holding key must still
+// be set, as must the entries of the other keys.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) deleteIndex(key K) {
+	if c.valueIndexes != nil {
+		if n, ok := c.find(key); ok {
+			for _, x := range c.valueIndexes {
+				x.remove(key, c.entries[n].value)
+			}
+		}
+	}
+	if c.index == nil {
+		delete(c.mapping, key)
+		return
+	}
+
+	x := c.index
+	i := x.hasher.Hash(key) & x.mask
+	for {
+		s := x.slots[i]
+		if s == 0 {
+			return
+		}
+		if x.hasher.Equal(c.entries[s-1].key, key) {
+			break
+		}
+		i = (i + 1) & x.mask
+	}
+
+	// Shift following entries back unless that moves them before their home
+	for j := (i + 1) & x.mask; x.slots[j] != 0; j = (j + 1) & x.mask {
+		home := x.hasher.Hash(c.entries[x.slots[j]-1].key) & x.mask
+		if (j-home)&x.mask >= (j-i)&x.mask {
+			x.slots[i] = x.slots[j]
+			i = j
+		}
+	}
+	x.slots[i] = 0
+	x.count--
+}
+
+// indexLen returns the number of keys in the index.
+func (c *SLRUCache[K, V]) indexLen() int {
+	if c.index == nil {
+		return len(c.mapping)
+	}
+	return c.index.count
+}
+
+// rangeIndex calls fn for each key and its entry index.
+// fn must not modify the index.
+func (c *SLRUCache[K, V]) rangeIndex(fn func(key K, n int)) {
+	if c.index == nil {
+		for k, n := range c.mapping {
+			fn(k, n)
+		}
+		return
+	}
+	for _, s := range c.index.slots {
+		if s != 0 {
+			fn(c.entries[s-1].key, int(s-1))
+		}
+	}
+}
+
+// resetIndex replaces the index with an empty one of the same kind.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) resetIndex() {
+	for _, x := range c.valueIndexes {
+		x.reset()
+	}
+	if c.index == nil {
+		c.mapping = make(map[K]int, c.mapSize)
+		return
+	}
+	c.index = newOpenIndex[K](c.cnum, c.index.hasher)
+}
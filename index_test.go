@@ -0,0 +1,118 @@
+package slrucache
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"testing"
+)
+
+// TestOpenIndex tests the open-addressed index against the map under a
+// random workload of inserts, lookups and removals.
+func TestOpenIndex(t *testing.T) {
+	ref := NewSLRUCache[int, int](20, 12)
+	c := NewSLRUCache[int, int](20, 12)
+	c.SetOpenIndex(true)
+	if c.index == nil || c.mapping != nil {
+		t.Fatal("open index not enabled")
+	}
+
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 20000; i++ {
+		k := r.IntN(100)
+		switch r.IntN(4) {
+		case 0, 1:
+			ref.Insert(k, i)
+			c.Insert(k, i)
+		case 2:
+			if (ref.Lookup(k) == nil) != (c.Lookup(k) == nil) {
+				t.Fatalf("step %d: lookup of %d differs", i, k)
+			}
+		case 3:
+			if ref.Remove(k) != c.Remove(k) {
+				t.Fatalf("step %d: removal of %d differs", i, k)
+			}
+		}
+	}
+	if c.indexLen() != ref.indexLen() || checkSLRUCacheSanity(c) {
+		t.Fatal("index out of sync")
+	}
+	for k := 0; k < 100; k++ {
+		if (ref.Lookup(k) == nil) != (c.Lookup(k) == nil) {
+			t.Errorf("key %d differs", k)
+		}
+	}
+}
+
+// TestOpenIndexCollisions tests deletion with all keys in one chain.
+func TestOpenIndexCollisions(t *testing.T) {
+	c := NewSLRUCache[int, int](16, 16)
+	c.SetHasher(HasherFunc[int](func(k int) uint64 { return uint64(k % 3) }))
+	c.SetOpenIndex(true)
+
+	for k := 0; k < 16; k++ {
+		c.Insert(k, k)
+	}
+	for k := 0; k < 16; k += 2 {
+		c.Remove(k)
+	}
+	for k := 0; k < 16; k++ {
+		if got := c.Lookup(k) != nil; got != (k%2 == 1) {
+			t.Errorf("key %d found %v", k, got)
+		}
+	}
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestOpenIndexSwitch tests switching the index kind on a filled cache.
+func TestOpenIndexSwitch(t *testing.T) {
+	c := NewSLRUCache[string, string](20, 20)
+	insertN(c, 15, 0)
+	c.SetOpenIndex(true)
+	c.SetHasher(NewDefaultHasher[string]())
+	if c.Clone().index == nil {
+		t.Error("clone should use the open index")
+	}
+	c.SetOpenIndex(false)
+	if c.index != nil || len(c.mapping) != 15 {
+		t.Error("keys lost switching back to the map")
+	}
+	for i := 0; i < 15; i++ {
+		if c.Lookup(strconv.Itoa(i)) == nil {
+			t.Errorf("key %d lost", i)
+		}
+	}
+}
+
+func benchmarkLookup(b *testing.B, open bool) {
+	c := NewSLRUCache[int, int](8192, 8192)
+	c.SetOpenIndex(open)
+	for k := 0; k < 16384; k++ {
+		c.Insert(k, k)
+		c.Lookup(k)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Lookup(i % 16384)
+	}
+}
+
+func benchmarkInsert(b *testing.B, open bool) {
+	c := NewSLRUCache[int, int](1024, 1024)
+	c.SetOpenIndex(open)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := i % 4096
+		c.Insert(k, k)
+	}
+}
+
+// BenchmarkLookupMap and BenchmarkLookupOpenIndex compare hits on both
+// index kinds, the Insert benchmarks inserts into a cache at capacity.
+func BenchmarkLookupMap(b *testing.B)       { benchmarkLookup(b, false) }
+func BenchmarkLookupOpenIndex(b *testing.B) { benchmarkLookup(b, true) }
+func BenchmarkInsertMap(b *testing.B)       { benchmarkInsert(b, false) }
+func BenchmarkInsertOpenIndex(b *testing.B) { benchmarkInsert(b, true) }
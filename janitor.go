@@ -0,0 +1,139 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"time"
+)
+
+// ExpirationMode selects when expired entries are removed. In every mode
+// lookups never return expired entries; the modes differ in how soon the
+// memory of expired entries that are not looked up again is reclaimed.
+type ExpirationMode int
+
+// Expiration modes.
+const (
+	// ExpireLazy removes expired entries only when they are accessed or
+	// evicted. No goroutine is started, suitable for embedded use.
+	ExpireLazy ExpirationMode = iota
+	// ExpireActive additionally sweeps the whole cache every interval,
+	// bounding the staleness of memory use by the interval.
+	ExpireActive
+	// ExpireHybrid additionally sweeps a bounded batch of entries every
+	// interval, spreading the sweep cost over time.
+	ExpireHybrid
+)
+
+// DefaultJanitorInterval is the sweep interval used if none is given.
+const DefaultJanitorInterval = time.Minute
+
+// janitorBatch is the number of entries a hybrid sweep examines per run.
+const janitorBatch = 256
+
+// janitor is the background goroutine sweeping expired entries.
+type janitor struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// SetExpiration sets the expiration mode. The active and hybrid modes
+// start a janitor goroutine sweeping every interval, DefaultJanitorInterval
+// if interval is 0 or less; ExpireLazy stops it. Call Close to stop the
+// janitor when the cache is no longer used.
+func (c *SLRUCache[K, V]) SetExpiration(mode ExpirationMode, interval time.Duration) {
+	c.stopJanitor()
+	if mode == ExpireLazy {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultJanitorInterval
+	}
+
+	batch := len(c.entries)
+	if mode == ExpireHybrid {
+		batch = janitorBatch
+	}
+
+	j := &janitor{stop: make(chan struct{}), done: make(chan struct{})}
+	c.mu.Lock()
+	c.janitor = j
+	c.mu.Unlock()
+
+	go func() {
+		defer close(j.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.stop:
+				return
+			case <-ticker.C:
+				c.sweepExpired(batch)
+			}
+		}
+	}()
+}
+
+// Close stops the janitor goroutine, the callback queue and write-behind,
+// if any, after delivering pending callbacks and writes. The cache stays
+// usable with lazy expiration and synchronous callbacks.
+func (c *SLRUCache[K, V]) Close() {
+	c.stopJanitor()
+	c.SetAsyncCallbacks(0)
+	c.SetWriteBehind(nil, WriteBehindConfig{})
+}
+
+// stopJanitor stops the janitor goroutine and waits for it to exit.
+func (c *SLRUCache[K, V]) stopJanitor() {
+	c.mu.Lock()
+	j := c.janitor
+	c.janitor = nil
+	c.mu.Unlock()
+
+	if j != nil {
+		close(j.stop)
+		<-j.done
+	}
+}
+
+// DeleteExpired removes all expired entries and returns their number,
+// a manual sweep for caches in lazy mode.
+func (c *SLRUCache[K, V]) DeleteExpired() int {
+	return c.sweepExpired(len(c.entries))
+}
+
+// sweepExpired examines up to limit entries, continuing where the last
+// sweep stopped, removes the expired ones and returns their number.
+func (c *SLRUCache[K, V]) sweepExpired(limit int) int {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+
+	var keys []K
+	for i := 0; i < min(limit, len(c.entries)); i++ {
+		n := c.sweepPos
+		c.sweepPos = (c.sweepPos + 1) % len(c.entries)
+
+		e := &c.entries[n]
+		if e.tag != tagProbation && e.tag != tagProtected || !c.expired(n) {
+			continue
+		}
+		key := e.key
+		c.removeEntry(n)
+		c.stats.Expirations++
+		if c.events != nil {
+			c.emit(EventExpire, key)
+		}
+		keys = append(keys, key)
+	}
+
+	removeCb, tier := c.removeCb, c.tier
+	c.mu.Unlock()
+
+	if tier != nil {
+		for _, key := range keys {
+			tier.Delete(key)
+		}
+	}
+	c.deliverRemoved(removeCb, keys...)
+	return len(keys)
+}
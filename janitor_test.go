@@ -0,0 +1,94 @@
+package slrucache
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDeleteExpired tests the manual sweep of expired entries.
+func TestDeleteExpired(t *testing.T) {
+	c := NewSLRUCache[string, int](5, 5)
+	clock := newTestClock(c)
+	c.SetTTL(time.Minute)
+
+	var removed []string
+	c.removeCb = func(key string) { removed = append(removed, key) }
+
+	c.Insert("a", 1)
+	c.Insert("b", 2)
+	c.Lookup("b")
+	clock.advance(30 * time.Second)
+	c.Insert("c", 3)
+
+	if n := c.DeleteExpired(); n != 0 {
+		t.Fatalf("DeleteExpired() = %d before expiry, want 0", n)
+	}
+
+	clock.advance(45 * time.Second)
+	if n := c.DeleteExpired(); n != 2 {
+		t.Fatalf("DeleteExpired() = %d, want 2", n)
+	}
+	if c.indexLen() != 1 || c.Lookup("c") == nil {
+		t.Errorf("%d entries left, want only c", c.indexLen())
+	}
+	if len(removed) != 2 {
+		t.Errorf("remove callback called for %v, want a and b", removed)
+	}
+	if s := c.Stats(); s.Expirations != 2 {
+		t.Errorf("Expirations = %d, want 2", s.Expirations)
+	}
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSweepExpiredBatch tests that hybrid sweeps examine a bounded batch
+// and continue where the last one stopped.
+func TestSweepExpiredBatch(t *testing.T) {
+	c := NewSLRUCache[int, int](0, 10)
+	clock := newTestClock(c)
+	c.SetTTL(time.Second)
+	for i := 0; i < 10; i++ {
+		c.Insert(i, i)
+	}
+	clock.advance(2 * time.Second)
+
+	total := 0
+	for i := 0; i < 3; i++ {
+		total += c.sweepExpired(4)
+	}
+	if total != 10 || c.indexLen() != 0 {
+		t.Errorf("swept %d entries, %d left, want 10 and 0", total, c.indexLen())
+	}
+}
+
+// TestExpirationModes tests that the janitor runs in active mode and stops
+// when switching back to lazy.
+func TestExpirationModes(t *testing.T) {
+	c := NewSLRUCache[string, int](5, 5)
+	c.mu = new(sync.RWMutex)
+	c.SetTTL(time.Millisecond)
+	c.Insert("a", 1)
+
+	c.SetExpiration(ExpireActive, time.Millisecond)
+	defer c.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Expirations != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("janitor did not remove the expired entry")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.SetExpiration(ExpireLazy, 0)
+	if c.janitor != nil {
+		t.Error("janitor still set in lazy mode")
+	}
+	c.Insert("b", 2)
+	time.Sleep(10 * time.Millisecond)
+	if s := c.Stats(); s.Expirations != 1 {
+		t.Errorf("Expirations = %d in lazy mode, want the expired entry kept", s.Expirations)
+	}
+}
@@ -0,0 +1,51 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// KeyFunc maps a lookup type, possibly not comparable, to a cache key.
+type KeyFunc[T any, K comparable] func(T) K
+
+// KeyFuncCache is a view of an SLRUCache accessed by values of type T,
+// for example structs holding slices, which are mapped to the comparable
+// cache keys by a KeyFunc, so callers do not serialize keys at every call
+// site. Values mapped to the same key share one entry.
+type KeyFuncCache[T any, K comparable, V any] struct {
+	cache *SLRUCache[K, V]
+	key   KeyFunc[T, K]
+}
+
+// NewKeyFuncCache creates a KeyFuncCache with given sizes for survivor and probe segments.
+func NewKeyFuncCache[T any, K comparable, V any](lruEntries int, probeEntries int, key KeyFunc[T, K]) *KeyFuncCache[T, K, V] {
+	return WithKeyFunc[T](NewSLRUCache[K, V](lruEntries, probeEntries), key)
+}
+
+// WithKeyFunc returns a KeyFuncCache view of c mapping lookup values by key.
+func WithKeyFunc[T any, K comparable, V any](c *SLRUCache[K, V], key KeyFunc[T, K]) *KeyFuncCache[T, K, V] {
+	return &KeyFuncCache[T, K, V]{cache: c, key: key}
+}
+
+// Cache returns the underlying cache.
+func (c *KeyFuncCache[T, K, V]) Cache() *SLRUCache[K, V] {
+	return c.cache
+}
+
+// Lookup returns a pointer to the value for t, or nil if not found.
+func (c *KeyFuncCache[T, K, V]) Lookup(t T) *V {
+	return c.cache.Lookup(c.key(t))
+}
+
+// Get returns a copy of the value for t and whether it was found.
+func (c *KeyFuncCache[T, K, V]) Get(t T) (V, bool) {
+	return c.cache.Get(c.key(t))
+}
+
+// Insert adds or updates the value for t.
+func (c *KeyFuncCache[T, K, V]) Insert(t T, value V) {
+	c.cache.Insert(c.key(t), value)
+}
+
+// Remove deletes the entry for t.
+// Returns true if the entry was found and removed.
+func (c *KeyFuncCache[T, K, V]) Remove(t T) bool {
+	return c.cache.Remove(c.key(t))
+}
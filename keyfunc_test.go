@@ -0,0 +1,30 @@
+package slrucache
+
+import (
+	"strings"
+	"testing"
+)
+
+// query is a lookup type that is not comparable.
+type query struct {
+	table  string
+	fields []string
+}
+
+// TestKeyFuncCache tests access by a non-comparable lookup type.
+func TestKeyFuncCache(t *testing.T) {
+	c := NewKeyFuncCache[query, string, int](10, 10, func(q query) string {
+		return q.table + ":" + strings.Join(q.fields, ",")
+	})
+
+	c.Insert(query{"users", []string{"id", "name"}}, 1)
+	if v, ok := c.Get(query{"users", []string{"id", "name"}}); !ok || v != 1 {
+		t.Error("equal lookup values should find the entry")
+	}
+	if c.Lookup(query{"users", []string{"id"}}) != nil {
+		t.Error("different lookup values should miss")
+	}
+	if !c.Remove(query{"users", []string{"id", "name"}}) || c.Cache().indexLen() != 0 {
+		t.Error("remove failed")
+	}
+}
@@ -0,0 +1,55 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"sync"
+)
+
+// DefaultKeyLocks is the size of the key lock table unless SetKeyLocks is
+// called.
+const DefaultKeyLocks = 256
+
+// keyLocks is a fixed table of mutexes keys are hashed to.
+type keyLocks[K comparable] struct {
+	hasher Hasher[K]
+	locks  []sync.Mutex
+}
+
+// SetKeyLocks sets the size of the lock table used by LockKey, bounding
+// its memory independent of the number of keys. It must be called before
+// LockKey is used.
+func (c *SLRUCache[K, V]) SetKeyLocks(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keyLocks = c.newKeyLocks(max(n, 1))
+}
+
+// LockKey locks the mutex of key and returns the func unlocking it, so
+// callers can serialize expensive recomputation per key without their own
+// lock maps. Keys share a table of DefaultKeyLocks mutexes by hash, see
+// SetKeyLocks, so unrelated keys may occasionally wait for each other.
+// The lock is independent of the cache mutex and must not be locked twice
+// by the same goroutine.
+func (c *SLRUCache[K, V]) LockKey(key K) func() {
+	c.mu.Lock()
+	if c.keyLocks == nil {
+		c.keyLocks = c.newKeyLocks(DefaultKeyLocks)
+	}
+	kl := c.keyLocks
+	c.mu.Unlock()
+
+	m := &kl.locks[kl.hasher.Hash(key)%uint64(len(kl.locks))]
+	m.Lock()
+	return m.Unlock
+}
+
+// newKeyLocks creates a key lock table of n mutexes.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) newKeyLocks(n int) *keyLocks[K] {
+	h := c.hasher
+	if h == nil {
+		h = NewDefaultHasher[K]()
+	}
+	return &keyLocks[K]{hasher: h, locks: make([]sync.Mutex, n)}
+}
@@ -0,0 +1,36 @@
+package slrucache
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestLockKey tests that LockKey serializes callers per key.
+func TestLockKey(t *testing.T) {
+	c := NewSLRUCache[string, int](10, 10)
+	c.mu = new(sync.RWMutex)
+	c.SetKeyLocks(4)
+
+	var wg sync.WaitGroup
+	counter := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := c.LockKey("a")
+			defer unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+	if counter != 50 {
+		t.Errorf("counter %d", counter)
+	}
+
+	// the table is bounded, all keys share its mutexes
+	unlock := c.LockKey("a")
+	unlock()
+	if len(c.keyLocks.locks) != 4 {
+		t.Errorf("lock table size %d", len(c.keyLocks.locks))
+	}
+}
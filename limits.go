@@ -0,0 +1,52 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// softEvictions is the number of entries a lookup evicts at most while the
+// cache is above its soft limit.
+const softEvictions = 2
+
+// SetLimits sets a soft and a hard limit of the number of entries, to
+// smooth the eviction cost of insert bursts. Inserts evict synchronously
+// only above the hard limit; while the cache is above the soft limit,
+// lookups evict a few entries each if the mutex is free. A hard limit of 0
+// selects the capacity, a soft limit of 0 or not below the hard limit
+// disables the opportunistic evictions. Inserts still evict if the
+// segment they insert into is full.
+func (c *SLRUCache[K, V]) SetLimits(soft, hard int) {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+
+	c.hardLimit = max(hard, 0)
+	c.softLimit = max(soft, 0)
+	if c.hardLimit > 0 && c.softLimit >= c.hardLimit {
+		c.softLimit = 0
+	}
+	c.enforceEntryLimit()
+
+	hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+}
+
+// softEvict evicts a few entries above the soft limit unless another
+// goroutine holds the mutex. Called by lookups after releasing the mutex.
+func (c *SLRUCache[K, V]) softEvict() {
+	if !c.mu.TryLock() {
+		return
+	}
+	defer c.recoverCorruption(true)
+
+	for i := 0; i < softEvictions && c.indexLen() > c.softLimit; i++ {
+		if !c.evictForLimit() {
+			break
+		}
+	}
+	c.overSoft.Store(c.softLimit > 0 && c.indexLen() > c.softLimit)
+
+	hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+}
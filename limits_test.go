@@ -0,0 +1,37 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestLimits tests that inserts evict above the hard limit only and
+// lookups evict down to the soft limit.
+func TestLimits(t *testing.T) {
+	c := NewSLRUCache[int, int](10, 10)
+	c.SetLimits(4, 8)
+	insertInts(c, 0, 12)
+	if c.indexLen() != 8 {
+		t.Fatalf("%d entries after inserts, want the hard limit of 8", c.indexLen())
+	}
+
+	c.Lookup(100)
+	if c.indexLen() != 6 {
+		t.Errorf("%d entries after a lookup, want 6", c.indexLen())
+	}
+	c.Lookup(100)
+	c.Lookup(100)
+	if c.indexLen() != 4 || c.overSoft.Load() {
+		t.Errorf("%d entries, want the soft limit of 4", c.indexLen())
+	}
+	if s := c.Stats(); s.Evictions != 8 {
+		t.Errorf("%d evictions, want 8", s.Evictions)
+	}
+
+	c.SetLimits(0, 2)
+	if c.indexLen() != 2 {
+		t.Errorf("%d entries after lowering the hard limit, want 2", c.indexLen())
+	}
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+}
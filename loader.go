@@ -0,0 +1,135 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoLoader is returned if neither a loader is passed nor configured.
+var ErrNoLoader = errors.New("slrucache: no loader configured")
+
+// LoaderFunc loads the value for key on a cache miss.
+type LoaderFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// loadCall is a load in flight, shared by all callers asking for the same key.
+type loadCall[V any] struct {
+	done  chan struct{} // closed when the load has finished
+	value V
+	err   error
+}
+
+// SetLoader configures the loader used by GetOrCompute calls without a loader.
+func (c *SLRUCache[K, V]) SetLoader(f LoaderFunc[K, V]) {
+	c.mu.Lock()
+	c.loader = f
+	c.mu.Unlock()
+}
+
+// GetOrCompute is GetOrComputeCtx with a background context.
+func (c *SLRUCache[K, V]) GetOrCompute(key K, loader LoaderFunc[K, V]) (V, error) {
+	return c.GetOrComputeCtx(context.Background(), key, loader)
+}
+
+// GetOrComputeCtx returns the cached value for key or loads it with loader,
+// or the configured loader or read-through store if loader is nil, and
+// caches the result.
+// Concurrent calls for the same key share one load. ctx is passed to the
+// loader; a caller whose ctx is done stops waiting and returns ctx.Err().
+// If the load fails because the context of the loading caller is done,
+// waiting callers with live contexts retry the load.
+// Negative entries are reported as ErrNotFound.
+func (c *SLRUCache[K, V]) GetOrComputeCtx(ctx context.Context, key K, loader LoaderFunc[K, V]) (V, error) {
+	return c.compute(ctx, key, loader, false)
+}
+
+// compute implements GetOrComputeCtx. If missed is true, the caller has
+// looked up key already and the first lookup is skipped.
+func (c *SLRUCache[K, V]) compute(ctx context.Context, key K, loader LoaderFunc[K, V], missed bool) (V, error) {
+	var zero V
+	for {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+
+		if !missed {
+			var out lookupOut[V]
+			switch _, state := c.lookup(key, &out); state {
+			case LookupHit:
+				return out.value, nil
+			case LookupNegative:
+				return zero, ErrNotFound
+			}
+		}
+		missed = false
+
+		c.mu.Lock()
+		if loader == nil {
+			loader = c.loader
+		}
+		if loader == nil && c.readThrough != nil {
+			loader = c.readThrough.Get
+		}
+		if loader == nil {
+			c.mu.Unlock()
+			return zero, ErrNoLoader
+		}
+
+		if call, ok := c.calls[key]; ok {
+			// Wait for the load in flight
+			c.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return zero, ctx.Err()
+			}
+			if isContextError(call.err) && ctx.Err() == nil {
+				// The loading caller gave up, try again
+				continue
+			}
+			return call.value, call.err
+		}
+
+		call := &loadCall[V]{done: make(chan struct{})}
+		if c.calls == nil {
+			c.calls = make(map[K]*loadCall[V])
+		}
+		c.calls[key] = call
+		c.mu.Unlock()
+
+		c.load(ctx, key, loader, call)
+		return call.value, call.err
+	}
+}
+
+// load runs loader for a registered call, caches the result and releases
+// the waiters. The loader is not run if ctx is done already.
+func (c *SLRUCache[K, V]) load(ctx context.Context, key K, loader LoaderFunc[K, V], call *loadCall[V]) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	if call.err = ctx.Err(); call.err == nil {
+		call.value, call.err = loader(ctx, key)
+	}
+	switch {
+	case call.err == nil:
+		c.insert(key, call.value, entryOptions{stored: true})
+	case errors.Is(call.err, ErrNotFound):
+		c.mu.Lock()
+		negative := c.negativeTTL > 0
+		c.mu.Unlock()
+		if negative {
+			c.InsertNegative(key)
+		}
+	}
+}
+
+// isContextError reports whether err is caused by a done context.
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
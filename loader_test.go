@@ -0,0 +1,116 @@
+package slrucache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGetOrCompute tests loading, caching and the configured loader.
+func TestGetOrCompute(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	if _, err := c.GetOrCompute("a", nil); err != ErrNoLoader {
+		t.Errorf("expected ErrNoLoader, got %v", err)
+	}
+
+	loads := 0
+	c.SetLoader(func(ctx context.Context, key string) (string, error) {
+		loads++
+		if key == "missing" {
+			return "", ErrNotFound
+		}
+		return "value-" + key, nil
+	})
+	c.SetNegativeTTL(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if v, err := c.GetOrCompute("a", nil); err != nil || v != "value-a" {
+			t.Errorf("unexpected result %q %v", v, err)
+		}
+		if _, err := c.GetOrCompute("missing", nil); err != ErrNotFound {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("expected 2 loads, got %d", loads)
+	}
+}
+
+// TestGetOrComputeShared tests that concurrent callers share one load.
+func TestGetOrComputeShared(t *testing.T) {
+	c := NewSLRUCache[string, int](5, 5)
+	release := make(chan struct{})
+	var loads int32
+	loader := func(ctx context.Context, key string) (int, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.GetOrCompute("k", loader); err != nil || v != 42 {
+				t.Errorf("unexpected result %d %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("expected 1 load, got %d", loads)
+	}
+}
+
+// TestGetOrComputeCtx tests cancellation of waiting and loading callers.
+func TestGetOrComputeCtx(t *testing.T) {
+	c := NewSLRUCache[string, int](5, 5)
+	started := make(chan struct{})
+	var loads int32
+	loader := func(ctx context.Context, key string) (int, error) {
+		if atomic.AddInt32(&loads, 1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 7, nil
+	}
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error)
+	go func() {
+		_, err := c.GetOrComputeCtx(leaderCtx, "k", loader)
+		leaderErr <- err
+	}()
+	<-started
+
+	// a waiter whose context expires gives up
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelWait()
+	if _, err := c.GetOrComputeCtx(waitCtx, "k", loader); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	// a waiter with a live context retries after the leader is cancelled
+	result := make(chan int)
+	go func() {
+		v, _ := c.GetOrComputeCtx(context.Background(), "k", loader)
+		result <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancelLeader()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+	if v := <-result; v != 7 {
+		t.Errorf("waiter should retry the load, got %d", v)
+	}
+}
@@ -0,0 +1,48 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// SetExpectedLoad reallocates the key index for n keys. By default the
+// index is allocated for the full capacity at construction, so a cache at
+// capacity never pays for incremental map growth. Caches that usually
+// stay far below capacity can pass a smaller n to save memory; the index
+// then grows on demand beyond n. n <= 0 restores the default. The index
+// is rebuilt, which also releases memory of a map that grew before.
+func (c *SLRUCache[K, V]) SetExpectedLoad(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n <= 0 {
+		n = c.cnum
+	}
+	c.mapSize = n
+	if c.index != nil {
+		return
+	}
+
+	mapping := make(map[K]int, max(n, c.indexLen()))
+	for k, i := range c.mapping {
+		mapping[k] = i
+	}
+	c.mapping = mapping
+}
+
+// SetHasher sets the hasher used by components hashing keys themselves,
+// such as sketches and the open-addressed index. Pass nil to use a
+// DefaultHasher. An open-addressed index is rebuilt with the new hasher.
+func (c *SLRUCache[K, V]) SetHasher(h Hasher[K]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hasher = h
+	if c.index == nil {
+		return
+	}
+	old := c.index
+	c.index = newOpenIndex[K](c.cnum, h)
+	for _, s := range old.slots {
+		if s != 0 {
+			c.setIndex(c.entries[s-1].key, int(s-1))
+		}
+	}
+}
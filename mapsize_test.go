@@ -0,0 +1,38 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestSetExpectedLoad tests that the index is rebuilt without losing keys.
+func TestSetExpectedLoad(t *testing.T) {
+	c := NewSLRUCache[string, string](50, 50)
+	if c.mapSize != 100 {
+		t.Error("index should be sized for the capacity")
+	}
+	insertN(c, 30, 0)
+
+	c.SetExpectedLoad(10)
+	if c.mapSize != 10 || len(c.mapping) != 30 || c.Lookup("29") == nil {
+		t.Error("rebuilt index lost keys")
+	}
+	c.SetExpectedLoad(0)
+	if c.mapSize != 100 || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// BenchmarkInsertSteadyState measures inserts into a cache at capacity.
+func BenchmarkInsertSteadyState(b *testing.B) {
+	keys := make([]int, 4096)
+	for i := range keys {
+		keys[i] = i
+	}
+	c := NewSLRUCache[int, int](1024, 1024)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := keys[i%len(keys)]
+		c.Insert(k, k)
+	}
+}
@@ -0,0 +1,45 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"unsafe"
+)
+
+// MemoryUsage is an estimate of the memory held by a cache in bytes.
+type MemoryUsage struct {
+	Entries int64 // entries array, including the inline parts of keys and values
+	Index   int64 // key index, estimated for the map
+	Values  int64 // memory referenced by values as reported by the sizer, see Sizer
+}
+
+// Total returns the sum of all parts.
+func (m MemoryUsage) Total() int64 {
+	return m.Entries + m.Index + m.Values
+}
+
+// EstimatedBytes estimates the memory held by the cache, to right-size it
+// against container memory limits. The entries array is allocated for the
+// full capacity up front. The map size is estimated from the number of
+// keys it was allocated for; memory referenced by keys, such as the
+// contents of string keys, is not included.
+func (c *SLRUCache[K, V]) EstimatedBytes() MemoryUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var e SLRUCacheEntry[K, V]
+	m := MemoryUsage{
+		Entries: int64(len(c.entries)) * int64(unsafe.Sizeof(e)),
+		Values:  c.probelist.bytes + c.lrulist.bytes,
+	}
+
+	if c.index != nil {
+		m.Index = int64(len(c.index.slots)) * int64(unsafe.Sizeof(c.index.slots[0]))
+	} else {
+		// Slots hold key, entry index and a control byte at a load factor
+		// of at most 7/8
+		slots := int64(max(c.mapSize, len(c.mapping))) * 8 / 7
+		m.Index = slots * int64(unsafe.Sizeof(e.key)+unsafe.Sizeof(0)+1)
+	}
+	return m
+}
@@ -0,0 +1,31 @@
+package slrucache
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// TestEstimatedBytes tests the parts of the memory estimate.
+func TestEstimatedBytes(t *testing.T) {
+	c := NewSLRUCache[int, sizedValue](50, 50)
+	c.Insert(1, "12345")
+
+	m := c.EstimatedBytes()
+	if want := 100 * int64(unsafe.Sizeof(c.entries[0])); m.Entries != want {
+		t.Errorf("Entries = %d, want %d", m.Entries, want)
+	}
+	if m.Index < 100*(8+8) {
+		t.Errorf("Index = %d is too small for 100 keys", m.Index)
+	}
+	if m.Values != 5 {
+		t.Errorf("Values = %d, want 5", m.Values)
+	}
+
+	c.SetOpenIndex(true)
+	if m := c.EstimatedBytes(); m.Index != 256*4 {
+		t.Errorf("open index Index = %d, want %d", m.Index, 256*4)
+	}
+	if m.Total() != m.Entries+m.Index+m.Values {
+		t.Error("Total should sum the parts")
+	}
+}
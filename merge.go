@@ -0,0 +1,162 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"sort"
+)
+
+// mergeCandidate is an entry competing for a place in a merged segment,
+// either an entry of the cache itself (n >= 0) or one of the other cache.
+type mergeCandidate[K comparable, V any] struct {
+	n        int // index in the cache, SLRU_EOF for entries of the other cache
+	entry    SLRUCacheEntry[K, V]
+	accessed int64
+}
+
+// Merge folds the entries of other into c. Entries keep their segment;
+// each segment of c keeps the most recently accessed entries of both
+// caches up to its capacity, the others are evicted or not taken over.
+// For keys present in both caches the more recently accessed value wins.
+// Pinned entries of c are always kept. Expired entries of other are
+// skipped. other is not modified.
+func (c *SLRUCache[K, V]) Merge(other *SLRUCache[K, V]) {
+	if other == c {
+		return
+	}
+	if other.mu != c.mu {
+		// Work on a private copy instead of holding two locks
+		other = other.Clone()
+	}
+
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+	c.drainReads()
+	other.drainReads()
+
+	for _, seg := range []Segment{SegmentProbation, SegmentProtected} {
+		c.mergeSegment(other, seg)
+	}
+
+	hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+}
+
+// mergeSegment merges segment seg of other into the same segment of c.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) mergeSegment(other *SLRUCache[K, V], seg Segment) {
+	l := c.segmentList(seg)
+	capacity := c.pnum
+	if seg == SegmentProtected {
+		capacity = c.snum
+	}
+
+	var cands []mergeCandidate[K, V]
+	for n := l.head; n >= 0; n = int(c.entries[n].next) {
+		cands = append(cands, mergeCandidate[K, V]{n: n})
+	}
+
+	ol := other.segmentList(seg)
+	for n := ol.head; n >= 0; n = int(other.entries[n].next) {
+		oe := &other.entries[n]
+		if other.expired(n) {
+			continue
+		}
+		if m, ok := c.find(oe.key); ok {
+			// Key in both caches, keep the more recent value
+			if e := &c.entries[m]; oe.accessed.After(e.accessed) {
+				c.setValue(m, oe.value)
+				e.expires = oe.expires
+				delete(c.entryTTLs, m)
+				e.negative = oe.negative
+				e.epoch = c.epoch
+				e.accessed = oe.accessed
+				if c.mlog != nil {
+					c.logMutation(logOp(e.negative), m, e.key)
+				}
+			}
+			continue
+		}
+		cands = append(cands, mergeCandidate[K, V]{n: SLRU_EOF, entry: *oe, accessed: oe.accessed.UnixNano()})
+	}
+
+	// Order by last access, entries of c by their possibly updated time
+	for i := range cands {
+		if n := cands[i].n; n >= 0 {
+			cands[i].accessed = c.entries[n].accessed.UnixNano()
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool {
+		return cands[i].accessed > cands[j].accessed
+	})
+
+	// Keep pinned entries and the most recent others up to capacity
+	keep := make([]bool, len(cands))
+	kept := 0
+	for i, cd := range cands {
+		if cd.n >= 0 && c.entries[cd.n].pins > 0 {
+			keep[i] = true
+			kept++
+		}
+	}
+	for i := range cands {
+		if !keep[i] && kept < capacity {
+			keep[i] = true
+			kept++
+		}
+	}
+
+	// Evict the entries of c that lost, unlink the others for reordering
+	for i, cd := range cands {
+		if cd.n < 0 {
+			continue
+		}
+		if keep[i] {
+			l.remove(cd.n)
+		} else {
+			c.evictEntry(l, cd.n, "Merge")
+			c.freelist.insertHead(cd.n)
+		}
+	}
+
+	// Relink from the oldest to the newest entry
+	for i := len(cands) - 1; i >= 0; i-- {
+		if !keep[i] {
+			continue
+		}
+		n := cands[i].n
+		if n < 0 {
+			n = c.freelist.removeTail()
+			if n == SLRU_EOF {
+				c.doPanic("Merge: no free entry available")
+			}
+			oe := &cands[i].entry
+			e := &c.entries[n]
+			e.key = oe.key
+			e.value = oe.value
+			e.prio = oe.prio
+			e.weight = oe.weight
+			e.expires = oe.expires
+			e.negative = oe.negative
+			e.epoch = c.epoch
+			e.inserted = oe.inserted
+			e.accessed = oe.accessed
+			e.accesses = oe.accesses
+			c.setIndex(e.key, n)
+			c.stats.Inserts++
+			if c.events != nil {
+				c.emit(EventInsert, e.key)
+			}
+			l.insertHead(n)
+			c.policyOf(l).Inserted(l, n)
+			if c.mlog != nil {
+				c.logMutation(logOp(e.negative), n, e.key)
+			}
+			continue
+		}
+		l.insertHead(n)
+		c.policyOf(l).Inserted(l, n)
+	}
+}
@@ -0,0 +1,48 @@
+package slrucache
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMerge tests that merged segments keep the most recent entries.
+func TestMerge(t *testing.T) {
+	a := NewSLRUCache[string, string](2, 3)
+	b := NewSLRUCache[string, string](2, 3)
+	clock := newTestClock(a)
+	b.now = clock.now
+
+	insertN(a, 2, 0) // a: 0 1
+	clock.advance(time.Second)
+	insertN(b, 2, 1) // b: 1 2, newer
+	clock.advance(time.Second)
+	a.Insert("3", "3") // a: 0 1 3
+	b.Insert("1", "b")
+	b.entries[b.mapping["1"]].accessed = clock.now().Add(time.Second)
+
+	a.Merge(b)
+
+	if k := listKeys(a, a.probelist); !equalKeys(k, []string{"1", "3", "2"}) {
+		t.Errorf("unexpected probation order %v", k)
+	}
+	if v := a.Lookup("1"); v == nil || *v != "b" {
+		t.Error("more recent value should win")
+	}
+	if a.Stats().Evictions != 1 || len(b.mapping) != 2 || checkSLRUCacheSanity(a) || checkSLRUCacheSanity(b) {
+		t.Fail()
+	}
+}
+
+// TestMergeProtected tests that protected entries stay protected.
+func TestMergeProtected(t *testing.T) {
+	a := NewSLRUCache[string, string](2, 2)
+	b := NewSLRUCache[string, string](2, 2)
+	insertN(b, 2, 0)
+	lookupN(b, 2, 0)
+
+	a.Merge(b)
+	a.Merge(a)
+	if a.lrulist.count != 2 || a.probelist.count != 0 || checkSLRUCacheSanity(a) {
+		t.Error("protected entries should be merged into lrulist")
+	}
+}
@@ -0,0 +1,177 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CachedResponse is a response stored by the caching middleware.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// MiddlewareOptions configures NewMiddleware.
+type MiddlewareOptions struct {
+	// KeyFunc derives the cache key of a request, default is method + " " + URL.
+	// Returning "" bypasses the cache.
+	KeyFunc func(r *http.Request) string
+	// TTL is the time to live of cached responses, 0 keeps the TTL of the cache.
+	TTL time.Duration
+	// Cacheable decides whether a response is stored, default are GET and
+	// HEAD requests answered with status 200.
+	Cacheable func(r *http.Request, status int) bool
+}
+
+// DefaultKeyFunc keys requests by method and URL.
+func DefaultKeyFunc(r *http.Request) string {
+	return r.Method + " " + r.URL.String()
+}
+
+// defaultCacheable caches successful GET and HEAD responses.
+func defaultCacheable(r *http.Request, status int) bool {
+	return (r.Method == http.MethodGet || r.Method == http.MethodHead) && status == http.StatusOK
+}
+
+// privateResponse reports whether the response with headers h to r must
+// not be shared: it sets a cookie, varies by request headers, is marked
+// no-store or private, or answers a request with credentials without being
+// marked public.
+func privateResponse(r *http.Request, h http.Header) bool {
+	switch {
+	case len(h.Values("Set-Cookie")) > 0 || len(h.Values("Vary")) > 0:
+		return true
+	case cacheDirective(h, "no-store") || cacheDirective(h, "private"):
+		return true
+	case r.Header.Get("Authorization") != "" || len(r.Header.Values("Cookie")) > 0:
+		return !cacheDirective(h, "public")
+	}
+	return false
+}
+
+// cacheDirective reports whether the Cache-Control headers of h contain
+// directive, ignoring case.
+func cacheDirective(h http.Header, directive string) bool {
+	for _, v := range h.Values("Cache-Control") {
+		for _, d := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(d), directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NewMiddleware wraps next with a response cache backed by c. Responses are
+// served from the cache if present, otherwise next handles the request and
+// its response is stored, unless it sets a cookie, has a Vary header or is
+// marked Cache-Control no-store or private. Responses to requests with an
+// Authorization or Cookie header are only stored if marked public.
+// Responses known not to be stored once their header is written are
+// streamed through without buffering. The TTL of the options applies to
+// the stored responses only, the settings of c are left unchanged.
+func NewMiddleware(c *SLRUCache[string, CachedResponse], opts MiddlewareOptions, next http.Handler) http.Handler {
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = DefaultKeyFunc
+	}
+	if opts.Cacheable == nil {
+		opts.Cacheable = defaultCacheable
+	}
+	var entryOpts []EntryOption
+	if opts.TTL > 0 {
+		entryOpts = append(entryOpts, WithTTL(opts.TTL))
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := opts.KeyFunc(r)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if cr, ok := c.Get(key); ok {
+			writeCachedResponse(w, &cr)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		rec.cacheable = func(status int) bool {
+			return opts.Cacheable(r, status) && !privateResponse(r, w.Header())
+		}
+		next.ServeHTTP(rec, r)
+
+		if !rec.wroteHeader {
+			rec.record = rec.cacheable(rec.status)
+		}
+		if rec.record {
+			c.InsertWithOptions(key, CachedResponse{
+				Status: rec.status,
+				Header: w.Header().Clone(),
+				Body:   rec.body.Bytes(),
+			}, entryOpts...)
+		}
+	})
+}
+
+// writeCachedResponse replays a cached response.
+func writeCachedResponse(w http.ResponseWriter, cr *CachedResponse) {
+	h := w.Header()
+	for k, v := range cr.Header {
+		h[k] = append([]string(nil), v...)
+	}
+	w.WriteHeader(cr.Status)
+	w.Write(cr.Body)
+}
+
+// responseRecorder passes a response through while recording it. The body
+// is only recorded if the response is cacheable when its header is written.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	record      bool                  // response is cacheable, the body is recorded
+	cacheable   func(status int) bool // decides record when the header is written
+	body        bytes.Buffer
+}
+
+// WriteHeader records the status code and whether to record the body.
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+		r.record = r.cacheable(status)
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the body if the response is cacheable.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if r.record {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data to the client if the underlying writer
+// supports it.
+func (r *responseRecorder) Flush() {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,152 @@
+package slrucache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestMiddleware tests caching of responses and the TTL.
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-Call", fmt.Sprint(calls))
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "body %d", calls)
+	})
+
+	c := NewSLRUCache[string, CachedResponse](10, 10)
+	clock := newTestClock(c)
+	h := NewMiddleware(c, MiddlewareOptions{TTL: time.Minute}, next)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	if rec := get("/a"); rec.Body.String() != "body 1" {
+		t.Errorf("unexpected body %q", rec.Body.String())
+	}
+	if rec := get("/a"); rec.Body.String() != "body 1" || rec.Header().Get("X-Call") != "1" || rec.Code != 200 {
+		t.Error("second request should be served from the cache")
+	}
+
+	get("/missing")
+	get("/missing")
+	if calls != 3 {
+		t.Errorf("errors should not be cached, %d calls", calls)
+	}
+
+	clock.advance(2 * time.Minute)
+	if rec := get("/a"); rec.Body.String() != "body 4" {
+		t.Error("expired response should be refreshed")
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/a", nil))
+	if calls != 5 {
+		t.Error("POST should not be served from the cache")
+	}
+}
+
+// TestMiddlewarePrivate tests that private responses are not cached.
+func TestMiddlewarePrivate(t *testing.T) {
+	headers := map[string][2]string{
+		"/cookie":   {"Set-Cookie", "session=1"},
+		"/vary":     {"Vary", "Accept-Encoding"},
+		"/no-store": {"Cache-Control", "max-age=60, no-store"},
+		"/private":  {"Cache-Control", "Private"},
+		"/public":   {"Cache-Control", "public, max-age=60"},
+	}
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		h := headers[r.URL.Path]
+		w.Header().Set(h[0], h[1])
+		fmt.Fprint(w, "body")
+	})
+	c := NewSLRUCache[string, CachedResponse](10, 10)
+	h := NewMiddleware(c, MiddlewareOptions{}, next)
+
+	for path := range headers {
+		calls = 0
+		for i := 0; i < 2; i++ {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+		}
+		if want := map[bool]int{true: 1, false: 2}[path == "/public"]; calls != want {
+			t.Errorf("%s: %d calls, want %d", path, calls, want)
+		}
+	}
+}
+
+// TestMiddlewareKeepsCacheTTL tests that the middleware leaves the TTL of the cache alone.
+func TestMiddlewareKeepsCacheTTL(t *testing.T) {
+	c := NewSLRUCache[string, CachedResponse](10, 10)
+	c.SetTTL(time.Hour)
+	NewMiddleware(c, MiddlewareOptions{}, http.NotFoundHandler())
+	NewMiddleware(c, MiddlewareOptions{TTL: time.Minute}, http.NotFoundHandler())
+	if c.ttl != time.Hour {
+		t.Errorf("cache TTL changed to %v", c.ttl)
+	}
+}
+
+// TestMiddlewareCredentials tests that responses to requests with credentials are only cached if public.
+func TestMiddlewareCredentials(t *testing.T) {
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public")
+		}
+		fmt.Fprint(w, "body")
+	})
+	c := NewSLRUCache[string, CachedResponse](10, 10)
+	h := NewMiddleware(c, MiddlewareOptions{}, next)
+
+	for _, tc := range []struct {
+		path, header string
+		calls        int
+	}{
+		{"/auth", "Authorization", 2},
+		{"/cookie", "Cookie", 2},
+		{"/public", "Authorization", 1},
+	} {
+		calls = 0
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", tc.path, nil)
+			req.Header.Set(tc.header, "secret")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		if calls != tc.calls {
+			t.Errorf("%s with %s: %d calls, want %d", tc.path, tc.header, calls, tc.calls)
+		}
+	}
+}
+
+// TestMiddlewareStreaming tests that uncacheable responses are flushed and not buffered.
+func TestMiddlewareStreaming(t *testing.T) {
+	var rec *responseRecorder
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec = w.(*responseRecorder)
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, "chunk")
+		w.(http.Flusher).Flush()
+	})
+	c := NewSLRUCache[string, CachedResponse](10, 10)
+	w := httptest.NewRecorder()
+	NewMiddleware(c, MiddlewareOptions{}, next).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if !w.Flushed || w.Body.String() != "chunk" {
+		t.Error("response should be streamed through")
+	}
+	if rec.body.Len() != 0 {
+		t.Error("uncacheable body should not be buffered")
+	}
+}
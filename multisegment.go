@@ -0,0 +1,171 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MaxSegments is the maximum number of segments of a MultiSegmentSLRU,
+// bounded by the list tags of the entries.
+const MaxSegments = 254
+
+// MultiSegmentSLRU generalizes SLRUCache to N segments.
+// New entries go into segment 0 (probation). A hit promotes an entry one
+// segment up; if that segment is full its tail is demoted into the segment
+// the hit entry came from. Only segment 0 evicts entries from the cache.
+// Hits in the top segment move the entry to the head of that segment.
+type MultiSegmentSLRU[K comparable, V any] struct {
+	mu      sync.Mutex // guards the cache
+	entries []SLRUCacheEntry[K, V]
+	mapping map[K]int // key to entry index
+
+	sizes    []int             // capacity per segment
+	segments []*SLRUList[K, V] // segment 0 is probation, the last one is the most protected
+	freelist *SLRUList[K, V]   // list of free entries
+}
+
+// NewMultiSegmentSLRU creates a new MultiSegmentSLRU with the given segment sizes,
+// ordered from the probationary segment up to the most protected segment.
+// 1 to MaxSegments segments of at least one entry each are supported,
+// other sizes are reported as an error wrapping ErrInvalidConfig.
+func NewMultiSegmentSLRU[K comparable, V any](sizes []int) (*MultiSegmentSLRU[K, V], error) {
+	if len(sizes) == 0 || len(sizes) > MaxSegments {
+		return nil, fmt.Errorf("%w: %d segments, want 1 to %d", ErrInvalidConfig, len(sizes), MaxSegments)
+	}
+	cnum := 0
+	for i, s := range sizes {
+		if s < 1 || s > MaxEntries-cnum {
+			return nil, fmt.Errorf("%w: size %d of segment %d must be positive and fit MaxEntries", ErrInvalidConfig, s, i)
+		}
+		cnum += s
+	}
+
+	cache := &MultiSegmentSLRU[K, V]{
+		mapping: make(map[K]int),
+		sizes:   append([]int(nil), sizes...),
+	}
+
+	cache.entries = make([]SLRUCacheEntry[K, V], cnum)
+	cache.freelist = NewSLRUList(&cache.entries, 1)
+	cache.segments = make([]*SLRUList[K, V], len(sizes))
+	for i := range cache.segments {
+		cache.segments[i] = NewSLRUList(&cache.entries, uint8(i+2))
+	}
+
+	// Initialize freelist with all entries
+	for i := 0; i < cnum; i++ {
+		cache.freelist.insertHead(i)
+	}
+
+	return cache, nil
+}
+
+// segmentOf returns the segment number of the entry at index n or -1.
+func (c *MultiSegmentSLRU[K, V]) segmentOf(n int) int {
+	if tag := int(c.entries[n].tag); tag >= 2 {
+		return tag - 2
+	}
+	return -1
+}
+
+// Lookup returns a pointer to the value for the given key, or nil if not found.
+// A hit promotes the entry one segment up.
+func (c *MultiSegmentSLRU[K, V]) Lookup(key K) *V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.mapping[key]
+	if !ok {
+		return nil
+	}
+
+	seg := c.segmentOf(n)
+	if seg < 0 {
+		panic(fmt.Sprintf("Lookup: entry %d is not part of a segment", n))
+	}
+
+	top := len(c.segments) - 1
+	if seg == top {
+		c.segments[top].MoveToHead(n)
+		return &c.entries[n].value
+	}
+
+	c.segments[seg].remove(n)
+	up := c.segments[seg+1]
+	if up.count >= c.sizes[seg+1] {
+		// Segment above is full, demote its tail into the segment we left
+		if t := up.removeTail(); t != SLRU_EOF {
+			c.segments[seg].insertHead(t)
+		}
+	}
+	up.insertHead(n)
+
+	return &c.entries[n].value
+}
+
+// Insert adds or updates a key-value pair in the cache.
+// New entries go into segment 0, evicting its tail if it is full.
+func (c *MultiSegmentSLRU[K, V]) Insert(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if n, ok := c.mapping[key]; ok {
+		c.entries[n].value = value
+		return
+	}
+
+	probe := c.segments[0]
+	var n int
+	if probe.count >= c.sizes[0] {
+		n = probe.removeTail()
+		if n == SLRU_EOF {
+			panic(fmt.Sprintf("Insert: no entry to evict in segment 0 for key %v", key))
+		}
+		delete(c.mapping, c.entries[n].key)
+	} else {
+		n = c.freelist.removeTail()
+		if n == SLRU_EOF {
+			panic(fmt.Sprintf("Insert: no free entry available for key %v", key))
+		}
+	}
+
+	c.entries[n].key = key
+	c.entries[n].value = value
+	c.mapping[key] = n
+	probe.insertHead(n)
+}
+
+// Remove deletes an entry by key from the cache.
+// Returns true if the entry was found and removed.
+func (c *MultiSegmentSLRU[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.mapping[key]
+	if !ok {
+		return false
+	}
+
+	e := &c.entries[n]
+	if s := c.segmentOf(n); s >= 0 {
+		c.segments[s].remove(n)
+	}
+	delete(c.mapping, key)
+
+	var zeroK K
+	var zeroV V
+	e.key = zeroK
+	e.value = zeroV
+	c.freelist.insertHead(n)
+
+	return true
+}
+
+// SegmentLen returns the number of entries in segment seg.
+func (c *MultiSegmentSLRU[K, V]) SegmentLen(seg int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.segments[seg].count
+}
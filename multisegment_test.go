@@ -0,0 +1,96 @@
+package slrucache
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+// newMultiSegment creates a MultiSegmentSLRU with valid sizes.
+func newMultiSegment(t *testing.T, sizes ...int) *MultiSegmentSLRU[string, string] {
+	c, err := NewMultiSegmentSLRU[string, string](sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// TestMultiSegmentSizes tests that invalid segment sizes are reported.
+func TestMultiSegmentSizes(t *testing.T) {
+	for _, sizes := range [][]int{nil, {0, 2}, {2, -1}, make([]int, MaxSegments+1)} {
+		if _, err := NewMultiSegmentSLRU[string, string](sizes); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("sizes %v should be invalid, got %v", sizes, err)
+		}
+	}
+	sizes := make([]int, MaxSegments)
+	for i := range sizes {
+		sizes[i] = 1
+	}
+	if _, err := NewMultiSegmentSLRU[string, string](sizes); err != nil {
+		t.Errorf("%d segments should be valid: %v", MaxSegments, err)
+	}
+}
+
+// TestMultiSegmentPromotion tests that hits move entries up one segment at a time.
+func TestMultiSegmentPromotion(t *testing.T) {
+	c := newMultiSegment(t, 2, 2, 2)
+	c.Insert("a", "a")
+	c.Insert("b", "b")
+
+	c.Lookup("a")
+	if c.SegmentLen(0) != 1 || c.SegmentLen(1) != 1 || c.SegmentLen(2) != 0 {
+		t.Error("first hit should promote into segment 1")
+	}
+	c.Lookup("a")
+	if c.SegmentLen(1) != 0 || c.SegmentLen(2) != 1 {
+		t.Error("second hit should promote into segment 2")
+	}
+	if v := c.Lookup("a"); v == nil || *v != "a" {
+		t.Error("hit in top segment should return the value")
+	}
+}
+
+// TestMultiSegmentDemotion tests that full segments demote their tail downward.
+func TestMultiSegmentDemotion(t *testing.T) {
+	c := newMultiSegment(t, 2, 1)
+	c.Insert("a", "a")
+	c.Insert("b", "b")
+
+	c.Lookup("a")
+	c.Lookup("b") // demotes a back into segment 0
+	if c.SegmentLen(0) != 1 || c.SegmentLen(1) != 1 {
+		t.Error("promotion into a full segment should demote its tail")
+	}
+
+	// two inserts push the demoted entry out of the cache
+	c.Insert("c", "c")
+	c.Insert("d", "d")
+	if c.Lookup("a") != nil || c.Lookup("b") == nil {
+		t.Error("demoted entry should be evicted from segment 0")
+	}
+}
+
+// TestMultiSegmentWindow runs a mixed workload and checks the segment sizes.
+func TestMultiSegmentWindow(t *testing.T) {
+	sizes := []int{5, 3, 2}
+	c := newMultiSegment(t, sizes...)
+	for n := 0; n < 200; n++ {
+		s := strconv.Itoa(n % 17)
+		if c.Lookup(s) == nil {
+			c.Insert(s, s)
+		}
+		if c.Remove(strconv.Itoa(n%23)) && c.Lookup(strconv.Itoa(n%23)) != nil {
+			t.Fatal("removed entry still present")
+		}
+	}
+	total := c.freelist.count
+	for i, s := range sizes {
+		if c.SegmentLen(i) > s {
+			t.Errorf("segment %d overflow", i)
+		}
+		total += c.SegmentLen(i)
+	}
+	if total != 10 || len(c.mapping) != 10-c.freelist.count {
+		t.Error("entries lost")
+	}
+}
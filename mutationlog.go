@@ -0,0 +1,375 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Mutation log record operations.
+const (
+	logInsert     byte = iota + 1 // Insert or InsertWithPriority
+	logNegative                   // InsertNegative
+	logRemove                     // Remove
+	logEntry                      // cache entry written by compaction
+	logPromote                    // move from probelist to lrulist
+	logDemote                     // move from lrulist to the probelist head
+	logDemoteTail                 // move from lrulist to the probelist tail
+)
+
+// logRecord is one record of a MutationLog.
+type logRecord[K comparable, V any] struct {
+	Op        byte
+	Key       K
+	Value     V
+	Priority  Priority
+	Expires   time.Time
+	Protected bool // entry was in lrulist
+}
+
+// MutationLog is an append-only log of cache mutations. Once attached with
+// SetMutationLog, every Insert, InsertNegative and Remove and every move
+// between the segments is appended to the log file, so Replay can
+// reconstruct the cache contents after a crash. Evictions are not logged,
+// replaying into a cache of the same size reproduces them. Compaction
+// rewrites the log from the current cache contents; with CompactEvery set
+// it happens automatically.
+//
+// Records are encoded with the cache locked and written to the file by a
+// background goroutine, so mutations do not wait for the disk. Flush,
+// Compact, Replay and Close wait for the queued records.
+//
+// Each record is framed by its length and checksum, a torn record at the
+// end of the file is ignored by Replay.
+type MutationLog[K comparable, V any] struct {
+	CompactEvery int // compact after this many appended records, 0 disables
+
+	path    string
+	records int // records appended since the last compaction, guarded by the cache mutex
+
+	qmu   sync.Mutex
+	queue []logChunk // encoded records and compactions not written yet
+	err   error      // first write error
+
+	wmu  sync.Mutex // serializes writing the queue
+	f    *os.File
+	wake chan struct{}
+	done chan struct{}
+	quit sync.WaitGroup
+}
+
+// logChunk is a piece of the log queued for writing.
+type logChunk struct {
+	data    []byte // framed records
+	compact bool   // data replaces the log file
+}
+
+// OpenMutationLog opens or creates the mutation log at path for appending.
+func OpenMutationLog[K comparable, V any](path string) (*MutationLog[K, V], error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	l := &MutationLog[K, V]{path: path, f: f, wake: make(chan struct{}, 1), done: make(chan struct{})}
+	l.quit.Add(1)
+	go l.run()
+	return l, nil
+}
+
+// SetMutationLog attaches a mutation log to the cache. Pass nil to detach it.
+// Call Replay before attaching the log, replayed mutations are not logged again.
+func (c *SLRUCache[K, V]) SetMutationLog(l *MutationLog[K, V]) {
+	c.mu.Lock()
+	c.mlog = l
+	c.mu.Unlock()
+}
+
+// logMutation queues a record for the attached log and compacts it if due.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) logMutation(op byte, n int, key K) {
+	l := c.mlog
+	if l == nil || l.Err() != nil {
+		return
+	}
+
+	rec := logRecord[K, V]{Op: op, Key: key}
+	if op != logRemove {
+		e := &c.entries[n]
+		rec.Value = e.value
+		rec.Priority = e.prio
+		rec.Expires = e.expires
+		rec.Protected = e.tag == tagProtected
+	}
+	var buf bytes.Buffer
+	if err := writeLogRecord(&buf, &rec); err != nil {
+		l.fail(err)
+		return
+	}
+	l.enqueue(logChunk{data: buf.Bytes()})
+
+	l.records++
+	if l.CompactEvery > 0 && l.records >= l.CompactEvery {
+		l.compact(c)
+	}
+}
+
+// logOp returns the log operation of an insert.
+func logOp(negative bool) byte {
+	if negative {
+		return logNegative
+	}
+	return logInsert
+}
+
+// writeLogRecord writes a framed record to w: length, checksum, gob payload.
+func writeLogRecord[K comparable, V any](w io.Writer, rec *logRecord[K, V]) error {
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(rec); err != nil {
+		return err
+	}
+
+	var frame [8]byte
+	binary.BigEndian.PutUint32(frame[:4], uint32(payload.Len()))
+	binary.BigEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(payload.Bytes()))
+	if _, err := w.Write(frame[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(payload.Bytes())
+	return err
+}
+
+// enqueue queues a chunk and wakes the writer. Must be called with the
+// cache mutex held, so chunks are queued in the order of the mutations.
+func (l *MutationLog[K, V]) enqueue(ch logChunk) {
+	l.qmu.Lock()
+	l.queue = append(l.queue, ch)
+	l.qmu.Unlock()
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// fail records the first error, logging stops after it.
+func (l *MutationLog[K, V]) fail(err error) {
+	l.qmu.Lock()
+	if l.err == nil {
+		l.err = err
+	}
+	l.qmu.Unlock()
+}
+
+// run writes the queue whenever it is woken until Close.
+func (l *MutationLog[K, V]) run() {
+	defer l.quit.Done()
+	for {
+		select {
+		case <-l.wake:
+			l.flush()
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// flush writes the queued chunks to the file in order and returns the
+// first write error. Must be called without the cache mutex held.
+func (l *MutationLog[K, V]) flush() error {
+	l.wmu.Lock()
+	defer l.wmu.Unlock()
+	for {
+		l.qmu.Lock()
+		queue, err := l.queue, l.err
+		l.queue = nil
+		l.qmu.Unlock()
+		if len(queue) == 0 || err != nil {
+			return err
+		}
+
+		for _, ch := range queue {
+			if ch.compact {
+				err = l.replace(ch.data)
+			} else {
+				_, err = l.f.Write(ch.data)
+			}
+			if err != nil {
+				l.fail(err)
+				return err
+			}
+		}
+	}
+}
+
+// replace writes data to a new log file, syncs it and renames it into
+// place. Must be called with wmu held.
+func (l *MutationLog[K, V]) replace(data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, l.path)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	l.f.Close()
+	l.f = f
+	return nil
+}
+
+// Flush waits until the queued records are written to the file.
+func (l *MutationLog[K, V]) Flush() error {
+	return l.flush()
+}
+
+// Compact rewrites the log from the current contents of c.
+func (l *MutationLog[K, V]) Compact(c *SLRUCache[K, V]) error {
+	c.mu.Lock()
+	l.compact(c)
+	c.mu.Unlock()
+	return l.flush()
+}
+
+// compact queues the entries of c as the new contents of the log file.
+// Protected entries come first, so replaying them does not push probationary
+// entries out. Must be called with the mutex held.
+func (l *MutationLog[K, V]) compact(c *SLRUCache[K, V]) {
+	var buf bytes.Buffer
+	for _, list := range []*SLRUList[K, V]{c.lrulist, c.probelist} {
+		for n := list.tail; n >= 0; n = int(c.entries[n].prev) {
+			e := &c.entries[n]
+			err := writeLogRecord(&buf, &logRecord[K, V]{
+				Op:        logEntry,
+				Key:       e.key,
+				Value:     e.value,
+				Priority:  e.prio,
+				Expires:   e.expires,
+				Protected: list == c.lrulist,
+			})
+			if err != nil {
+				l.fail(err)
+				return
+			}
+		}
+	}
+	l.enqueue(logChunk{data: buf.Bytes(), compact: true})
+	l.records = 0
+}
+
+// Replay applies the records of the log to c, starting from the beginning of the file.
+func (l *MutationLog[K, V]) Replay(c *SLRUCache[K, V]) error {
+	if err := l.flush(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for {
+		rec, err := readLogRecord[K, V](r)
+		if err == io.EOF || errors.Is(err, errTornRecord) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch rec.Op {
+		case logInsert, logEntry:
+			c.insert(rec.Key, rec.Value, entryOptions{prio: rec.Priority, setPrio: true, bypass: true, stored: true})
+			if rec.Protected {
+				c.Promote(rec.Key)
+			}
+		case logNegative:
+			c.InsertNegative(rec.Key)
+		case logRemove:
+			c.Remove(rec.Key)
+		case logPromote:
+			c.Promote(rec.Key)
+		case logDemote:
+			c.Demote(rec.Key)
+		case logDemoteTail:
+			c.DemoteTail(rec.Key)
+		}
+		if rec.Op != logRemove {
+			c.mu.Lock()
+			if n, ok := c.find(rec.Key); ok {
+				c.entries[n].expires = rec.Expires
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// errTornRecord reports a record cut short by a crash.
+var errTornRecord = errors.New("slrucache: torn mutation log record")
+
+// readLogRecord reads one framed record from r.
+func readLogRecord[K comparable, V any](r io.Reader) (*logRecord[K, V], error) {
+	var frame [8]byte
+	if _, err := io.ReadFull(r, frame[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errTornRecord
+		}
+		return nil, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(frame[:4]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errTornRecord
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(frame[4:]) {
+		return nil, errTornRecord
+	}
+
+	var rec logRecord[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// Err returns the first error writing the log. Logging stops after an error.
+func (l *MutationLog[K, V]) Err() error {
+	l.qmu.Lock()
+	defer l.qmu.Unlock()
+	return l.err
+}
+
+// Close writes the queued records and closes the log file.
+func (l *MutationLog[K, V]) Close() error {
+	close(l.done)
+	l.quit.Wait()
+	err := l.flush()
+
+	l.wmu.Lock()
+	defer l.wmu.Unlock()
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package slrucache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestMutationLogReplay tests that replaying the log reconstructs the cache.
+func TestMutationLogReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+
+	l, err := OpenMutationLog[string, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewSLRUCache[string, string](5, 5)
+	c.SetMutationLog(l)
+	insertN(c, 8, 0)
+	c.Remove("5")
+	c.InsertNegative("gone")
+	c.Insert("6", "six")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// append a torn record
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.Write([]byte{0, 0, 0, 99, 1, 2})
+	f.Close()
+
+	l, err = OpenMutationLog[string, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	r := NewSLRUCache[string, string](5, 5)
+	if err := l.Replay(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalKeys(listKeys(c, c.probelist), listKeys(r, r.probelist)) || checkSLRUCacheSanity(r) {
+		t.Errorf("replayed cache differs: %v %v", listKeys(c, c.probelist), listKeys(r, r.probelist))
+	}
+	if v := r.Lookup("6"); v == nil || *v != "six" {
+		t.Error("update should be replayed")
+	}
+	if _, state := r.LookupResult("gone"); state != LookupNegative {
+		t.Error("negative entry should be replayed")
+	}
+}
+
+// TestMutationLogCompact tests automatic compaction.
+func TestMutationLogCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+
+	l, err := OpenMutationLog[string, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.CompactEvery = 23
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetMutationLog(l)
+	insertN(c, 3, 0)
+	lookupN(c, 3, 0)
+	insertN(c, 16, 100)
+	c.Insert("x", "x") // 23rd record with 3 promotions triggers compaction
+	c.Remove("x")
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if l.records != 1 {
+		t.Errorf("expected 1 record after compaction, got %d", l.records)
+	}
+	l.Close()
+
+	l, _ = OpenMutationLog[string, string](path)
+	defer l.Close()
+	r := NewSLRUCache[string, string](3, 3)
+	if err := l.Replay(r); err != nil {
+		t.Fatal(err)
+	}
+	if !equalKeys(listKeys(c, c.lrulist), listKeys(r, r.lrulist)) ||
+		!equalKeys(listKeys(c, c.probelist), listKeys(r, r.probelist)) {
+		t.Error("compacted log should reproduce segments")
+	}
+}
+
+// TestMutationLogSegments tests that promotions and demotions are replayed.
+func TestMutationLogSegments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+
+	l, err := OpenMutationLog[string, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetMutationLog(l)
+	insertN(c, 3, 0)
+	c.Lookup("0")
+	c.Lookup("1")
+	c.DemoteTail("1")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, _ = OpenMutationLog[string, string](path)
+	defer l.Close()
+	r := NewSLRUCache[string, string](3, 3)
+	if err := l.Replay(r); err != nil {
+		t.Fatal(err)
+	}
+	if !equalKeys(listKeys(c, c.lrulist), listKeys(r, r.lrulist)) ||
+		!equalKeys(listKeys(c, c.probelist), listKeys(r, r.probelist)) {
+		t.Errorf("segments differ: %v %v, replayed %v %v",
+			listKeys(c, c.lrulist), listKeys(c, c.probelist), listKeys(r, r.lrulist), listKeys(r, r.probelist))
+	}
+}
+
+// TestMutationLogFlush tests that queued records reach the file.
+func TestMutationLogFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.log")
+
+	l, err := OpenMutationLog[string, string](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetMutationLog(l)
+	c.Insert("a", "1")
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Size() == 0 {
+		t.Errorf("record should be written: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// NamespacedKey is the key of an entry in a NamespacedCache.
+type NamespacedKey[K comparable] struct {
+	Namespace string
+	Key       K
+}
+
+// NamespacedCache partitions the keyspace of one SLRUCache into namespaces
+// sharing its capacity. Entries of all namespaces compete for the same
+// segments, each namespace can be invalidated on its own.
+type NamespacedCache[K comparable, V any] struct {
+	cache *SLRUCache[NamespacedKey[K], V]
+}
+
+// Namespace is a view of a NamespacedCache restricted to one namespace.
+type Namespace[K comparable, V any] struct {
+	name  string
+	cache *SLRUCache[NamespacedKey[K], V]
+}
+
+// NewNamespacedCache creates a NamespacedCache with given sizes for survivor and probe segments.
+func NewNamespacedCache[K comparable, V any](lruEntries int, probeEntries int) *NamespacedCache[K, V] {
+	return &NamespacedCache[K, V]{
+		cache: NewSLRUCache[NamespacedKey[K], V](lruEntries, probeEntries),
+	}
+}
+
+// Cache returns the underlying cache shared by all namespaces.
+func (c *NamespacedCache[K, V]) Cache() *SLRUCache[NamespacedKey[K], V] {
+	return c.cache
+}
+
+// Namespace returns the view of namespace name.
+func (c *NamespacedCache[K, V]) Namespace(name string) *Namespace[K, V] {
+	return &Namespace[K, V]{name: name, cache: c.cache}
+}
+
+// Name returns the name of the namespace.
+func (ns *Namespace[K, V]) Name() string {
+	return ns.name
+}
+
+// Lookup returns a pointer to the value for key in the namespace, or nil if not found.
+func (ns *Namespace[K, V]) Lookup(key K) *V {
+	return ns.cache.Lookup(NamespacedKey[K]{Namespace: ns.name, Key: key})
+}
+
+// Insert adds or updates a key-value pair in the namespace.
+func (ns *Namespace[K, V]) Insert(key K, value V) {
+	ns.cache.Insert(NamespacedKey[K]{Namespace: ns.name, Key: key}, value)
+}
+
+// Remove deletes key from the namespace.
+// Returns true if the entry was found and removed.
+func (ns *Namespace[K, V]) Remove(key K) bool {
+	return ns.cache.Remove(NamespacedKey[K]{Namespace: ns.name, Key: key})
+}
+
+// Invalidate removes all entries of the namespace and returns their number.
+// It scans the whole cache.
+func (ns *Namespace[K, V]) Invalidate() int {
+	ns.cache.mu.Lock()
+	var keys []NamespacedKey[K]
+	ns.cache.rangeIndex(func(k NamespacedKey[K], _ int) {
+		if k.Namespace == ns.name {
+			keys = append(keys, k)
+		}
+	})
+	ns.cache.mu.Unlock()
+
+	removed := 0
+	for _, k := range keys {
+		if ns.cache.Remove(k) {
+			removed++
+		}
+	}
+	return removed
+}
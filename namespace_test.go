@@ -0,0 +1,38 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestNamespaces tests key partitioning and per-namespace invalidation.
+func TestNamespaces(t *testing.T) {
+	c := NewNamespacedCache[string, int](5, 5)
+	users := c.Namespace("users")
+	groups := c.Namespace("groups")
+
+	users.Insert("a", 1)
+	users.Insert("b", 2)
+	groups.Insert("a", 3)
+
+	if v := users.Lookup("a"); v == nil || *v != 1 {
+		t.Error("users/a should be 1")
+	}
+	if v := groups.Lookup("a"); v == nil || *v != 3 {
+		t.Error("groups/a should be 3")
+	}
+
+	if n := users.Invalidate(); n != 2 {
+		t.Errorf("expected 2 invalidated entries, got %d", n)
+	}
+	if users.Lookup("a") != nil || groups.Lookup("a") == nil {
+		t.Error("invalidation should only affect its namespace")
+	}
+
+	// namespaces share the capacity
+	for i := 0; i < 10; i++ {
+		users.Insert(string(rune('c'+i)), i)
+	}
+	if len(c.Cache().mapping) != 6 || checkSLRUCacheSanity(c.Cache()) {
+		t.Error("namespaces should share one cache")
+	}
+}
@@ -0,0 +1,68 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"time"
+)
+
+// LookupState describes the result of a lookup.
+type LookupState int
+
+const (
+	// LookupMiss means the key is not cached or has expired.
+	LookupMiss LookupState = iota
+	// LookupHit means a value is cached for the key.
+	LookupHit
+	// LookupNegative means the key is cached as "not found".
+	LookupNegative
+)
+
+// String returns the name of the lookup state.
+func (s LookupState) String() string {
+	switch s {
+	case LookupHit:
+		return "hit"
+	case LookupNegative:
+		return "negative"
+	}
+	return "miss"
+}
+
+// SetNegativeTTL sets the time to live of negative entries inserted afterwards.
+// It is usually shorter than the TTL of positive entries. 0 disables expiration.
+func (c *SLRUCache[K, V]) SetNegativeTTL(ttl time.Duration) {
+	c.mu.Lock()
+	c.negativeTTL = ttl
+	c.mu.Unlock()
+}
+
+// InsertNegative caches a "not found" result for key, replacing any value.
+// Negative entries take part in promotion and eviction like other entries,
+// Lookup reports them as not found and LookupResult as LookupNegative.
+func (c *SLRUCache[K, V]) InsertNegative(key K) {
+	var zero V
+	c.insert(key, zero, entryOptions{negative: true})
+}
+
+// LookupResult is Lookup that distinguishes misses from negative entries.
+// The returned pointer is nil unless the state is LookupHit.
+func (c *SLRUCache[K, V]) LookupResult(key K) (*V, LookupState) {
+	return c.lookup(key, nil)
+}
+
+// LookupInfo describes the outcome of a lookup, see LookupDetail.
+type LookupInfo struct {
+	State    LookupState
+	Segment  Segment // segment the entry was found in, SegmentNone on a miss
+	Promoted bool    // the hit promoted the entry to the protected segment
+}
+
+// LookupDetail is LookupResult also reporting the segment of the hit and
+// whether it promoted the entry, so callers and tests can follow the
+// policy without inspecting the cache internals.
+func (c *SLRUCache[K, V]) LookupDetail(key K) (*V, LookupInfo) {
+	var out lookupOut[V]
+	v, state := c.lookup(key, &out)
+	return v, LookupInfo{State: state, Segment: out.segment, Promoted: out.promoted}
+}
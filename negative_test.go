@@ -0,0 +1,92 @@
+package slrucache
+
+import (
+	"testing"
+	"time"
+)
+
+// testClock is a manually advanced clock for expiration tests.
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time {
+	return c.t
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+// newTestClock returns a clock installed into c.
+func newTestClock[K comparable, V any](c *SLRUCache[K, V]) *testClock {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	c.now = clock.now
+	return clock
+}
+
+// TestNegativeCaching tests negative entries and their shorter TTL.
+func TestNegativeCaching(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	clock := newTestClock(c)
+	c.SetTTL(time.Minute)
+	c.SetNegativeTTL(time.Second)
+
+	c.Insert("found", "value")
+	c.InsertNegative("missing")
+
+	if v, state := c.LookupResult("missing"); v != nil || state != LookupNegative {
+		t.Errorf("expected negative hit, got %v", state)
+	}
+	if c.Lookup("missing") != nil {
+		t.Error("Lookup should report negative entries as not found")
+	}
+	if v, state := c.LookupResult("found"); v == nil || *v != "value" || state != LookupHit {
+		t.Errorf("expected hit, got %v", state)
+	}
+	if _, state := c.LookupResult("other"); state != LookupMiss {
+		t.Errorf("expected miss, got %v", state)
+	}
+
+	clock.advance(2 * time.Second)
+	if _, state := c.LookupResult("missing"); state != LookupMiss {
+		t.Errorf("negative entry should have expired, got %v", state)
+	}
+	if _, state := c.LookupResult("found"); state != LookupHit {
+		t.Errorf("positive entry should still be cached, got %v", state)
+	}
+
+	clock.advance(time.Minute)
+	if c.Lookup("found") != nil || len(c.mapping) != 0 || checkSLRUCacheSanity(c) {
+		t.Error("positive entry should have expired")
+	}
+}
+
+// TestNegativeReplaced tests that inserting a value replaces a negative entry.
+func TestNegativeReplaced(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.InsertNegative("k")
+	c.Insert("k", "v")
+	if v, state := c.LookupResult("k"); v == nil || *v != "v" || state != LookupHit {
+		t.Errorf("expected hit, got %v", state)
+	}
+}
+
+// TestLookupDetail tests that lookups report the segment and promotion of a hit.
+func TestLookupDetail(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.Insert("k", "v")
+
+	want := []LookupInfo{
+		{LookupHit, SegmentProbation, true},
+		{LookupHit, SegmentProtected, false},
+	}
+	for i, w := range want {
+		if v, info := c.LookupDetail("k"); v == nil || info != w {
+			t.Errorf("lookup %d: %+v, want %+v", i, info, w)
+		}
+	}
+	if _, info := c.LookupDetail("missing"); info != (LookupInfo{}) {
+		t.Errorf("miss: %+v", info)
+	}
+}
@@ -0,0 +1,80 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// GetOldest returns the key and value at the tail of segment s, the entry
+// the LRU policy evicts next, without removing or touching it.
+// Returns false if the segment is empty.
+func (c *SLRUCache[K, V]) GetOldest(s Segment) (K, V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainReads()
+	return c.peekEntry(s, func(l *SLRUList[K, V]) int { return l.tail })
+}
+
+// GetNewest returns the key and value at the head of segment s, the most
+// recently inserted or used entry, without removing or touching it.
+// Returns false if the segment is empty.
+func (c *SLRUCache[K, V]) GetNewest(s Segment) (K, V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.drainReads()
+	return c.peekEntry(s, func(l *SLRUList[K, V]) int { return l.head })
+}
+
+// peekEntry returns the entry of segment s at the index selected by end.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) peekEntry(s Segment, end func(*SLRUList[K, V]) int) (K, V, bool) {
+	var zeroK K
+	var zeroV V
+
+	l := c.segmentList(s)
+	if l == nil {
+		return zeroK, zeroV, false
+	}
+	n := end(l)
+	if n < 0 {
+		return zeroK, zeroV, false
+	}
+	return c.entries[n].key, c.entries[n].value, true
+}
+
+// PeekVictim returns the key and value of the entry the next eviction for
+// capacity would remove, so applications can persist or log it in advance.
+// Pinned and higher priority entries are passed over as by an eviction,
+// but left in place. The prediction follows the LRU order, with another
+// policy the next eviction may pick another entry. Returns false if there
+// is no evictable entry.
+func (c *SLRUCache[K, V]) PeekVictim() (K, V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l := c.probelist
+	if c.pnum == 0 {
+		l = c.lrulist
+		c.drainReads()
+	}
+	if n := c.peekVictim(l); n != SLRU_EOF {
+		return c.entries[n].key, c.entries[n].value, true
+	}
+	var zeroK K
+	var zeroV V
+	return zeroK, zeroV, false
+}
+
+// peekVictim returns the index of the entry victim selects from l in LRU
+// order, walking the list without moving the entries passed over.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) peekVictim(l *SLRUList[K, V]) int {
+	for p := PriorityLow; p <= PriorityHigh; p++ {
+		if l.prios[p.level()] == 0 {
+			continue
+		}
+		for n := l.tail; n >= 0; n = int(c.entries[n].prev) {
+			if e := &c.entries[n]; e.pins == 0 && e.prio <= p {
+				return n
+			}
+		}
+	}
+	return SLRU_EOF
+}
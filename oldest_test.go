@@ -0,0 +1,61 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestGetOldestNewest tests the ends of both segments.
+func TestGetOldestNewest(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	if _, _, ok := c.GetOldest(SegmentProtected); ok {
+		t.Error("empty segment should have no oldest entry")
+	}
+
+	insertN(c, 3, 0)
+	lookupN(c, 2, 0)
+
+	if k, v, ok := c.GetOldest(SegmentProtected); !ok || k != "0" || v != "0" {
+		t.Errorf("oldest protected should be 0, got %s", k)
+	}
+	if k, _, _ := c.GetNewest(SegmentProtected); k != "1" {
+		t.Errorf("newest protected should be 1, got %s", k)
+	}
+	if k, _, _ := c.GetOldest(SegmentProbation); k != "2" {
+		t.Errorf("oldest probation should be 2, got %s", k)
+	}
+	if _, _, ok := c.GetNewest(SegmentNone); ok {
+		t.Error("SegmentNone has no entries")
+	}
+
+	// peeking does not promote
+	if checkListCount(c, 3, 2, 1, "peek") {
+		t.Fail()
+	}
+}
+
+// TestPeekVictim tests that PeekVictim predicts the next eviction.
+func TestPeekVictim(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	if _, _, ok := c.PeekVictim(); ok {
+		t.Error("empty cache should have no victim")
+	}
+
+	insertN(c, 2, 0)
+	c.Pin("0")
+	k, v, ok := c.PeekVictim()
+	if !ok || k != "1" || v != "1" {
+		t.Errorf("victim should skip the pinned entry, got %s", k)
+	}
+	if c.entries[c.probelist.Tail()].key != "0" {
+		t.Error("peeking should not move the pinned entry")
+	}
+	c.Insert("2", "2")
+	if c.Lookup(k) != nil || c.Lookup("0") == nil {
+		t.Error("peeked victim should be evicted next")
+	}
+
+	c.Pin("2")
+	if _, _, ok := c.PeekVictim(); ok {
+		t.Error("pinned entries are no victims")
+	}
+}
@@ -0,0 +1,171 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"time"
+)
+
+// EntryOption configures a single insert, see InsertWithOptions.
+type EntryOption func(*entryOptions)
+
+// entryOptions are the per entry settings of an insert.
+type entryOptions struct {
+	ttl      time.Duration // time to live, used if hasTTL is set
+	hasTTL   bool
+	deadline time.Time // absolute expiration, used if hasTTL is set and not zero
+	weight   int32     // weight, 0 keeps the current weight or uses 1
+	prio     Priority  // priority, of new entries or if setPrio is set
+	setPrio  bool
+	pin      bool // pin the entry
+	negative bool // cache a "not found" result
+	bypass   bool // skip the admission control
+	stored   bool // value is persisted already, skip write-through
+	noEvict  bool // drop a new entry instead of evicting, see TryInsert
+}
+
+// WithTTL sets the time to live of the entry, overriding SetTTL.
+// 0 means the entry does not expire.
+func WithTTL(ttl time.Duration) EntryOption {
+	return func(o *entryOptions) {
+		o.ttl = max(ttl, 0)
+		o.deadline = time.Time{}
+		o.hasTTL = true
+	}
+}
+
+// WithDeadline sets the absolute expiration time of the entry, overriding
+// SetTTL, for values carrying an absolute expiry from upstream. The zero
+// time means the entry does not expire.
+func WithDeadline(t time.Time) EntryOption {
+	return func(o *entryOptions) {
+		o.ttl = 0
+		o.deadline = t
+		o.hasTTL = true
+	}
+}
+
+// WithWeight sets the weight of the entry, 1 by default. Weights count
+// against the limit set by SetMaxWeight. Weights below 1 are ignored.
+func WithWeight(weight int) EntryOption {
+	return func(o *entryOptions) {
+		o.weight = int32(min(max(weight, 0), MaxEntries))
+	}
+}
+
+// WithPriority sets the eviction priority of the entry like
+// InsertWithPriority.
+func WithPriority(prio Priority) EntryOption {
+	return func(o *entryOptions) {
+		o.prio = clampPriority(prio)
+		o.setPrio = true
+	}
+}
+
+// WithPin pins the entry like Pin, it needs a matching Unpin.
+func WithPin() EntryOption {
+	return func(o *entryOptions) {
+		o.pin = true
+	}
+}
+
+// InsertWithOptions adds or updates a key-value pair like Insert with the
+// given per entry options. Options not given keep the current settings of
+// an existing entry, except the TTL which is renewed like by Insert.
+// Returns false if the entry was dropped because its segment is pinned
+// completely, a pin requested by WithPin is then not taken, because the
+// admission control rejected it or the write-through store failed.
+func (c *SLRUCache[K, V]) InsertWithOptions(key K, value V, opts ...EntryOption) bool {
+	var o entryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return c.insert(key, value, o) == nil
+}
+
+// SetMaxWeight limits the total weight of the cached entries. Inserts
+// evict probationary and then protected entries until the total weight is
+// within the limit again, in addition to the limits of the segment sizes.
+// An entry heavier than the limit evicts everything else and itself.
+// 0 disables the limit.
+func (c *SLRUCache[K, V]) SetMaxWeight(weight int64) {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+
+	c.maxWeight = max(weight, 0)
+	c.enforceWeight()
+
+	hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+}
+
+// Weight returns the total weight of the cached entries.
+func (c *SLRUCache[K, V]) Weight() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.probelist.weight + c.lrulist.weight
+}
+
+// setWeight changes the weight of the entry at index n.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) setWeight(n int, weight int32) {
+	e := &c.entries[n]
+	if l := c.listOf(n); l != nil {
+		l.weight += int64(weight - e.weight)
+	}
+	e.weight = weight
+}
+
+// SetEvictionBatch sets the minimum number of entries evicted at once when
+// an insert exceeds the weight limit set by SetMaxWeight. Evicting more
+// entries than needed leaves headroom, so the following inserts need no
+// eviction, and the bookkeeping is done once per batch. Entries beyond the
+// ones needed are only taken from the list new entries are inserted into,
+// never its head. 1 or less, the default, evicts just enough entries.
+// Stats.WeightEvictions counts the evictions for the weight limit.
+func (c *SLRUCache[K, V]) SetEvictionBatch(n int) {
+	c.mu.Lock()
+	c.evictBatch = max(n, 1)
+	c.mu.Unlock()
+}
+
+// enforceWeight evicts entries until the total weight is within the limit,
+// at least a batch of them, and returns their number.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) enforceWeight() int {
+	if c.maxWeight <= 0 || c.probelist.weight+c.lrulist.weight <= c.maxWeight {
+		return 0
+	}
+
+	target := c.probelist
+	if c.pnum == 0 {
+		target = c.lrulist
+	}
+
+	evicted := 0
+	for _, l := range []*SLRUList[K, V]{c.probelist, c.lrulist} {
+		for l.count > 0 {
+			over := c.probelist.weight+c.lrulist.weight > c.maxWeight
+			if !over && (l != target || evicted >= c.evictBatch || l.count == 1) {
+				break
+			}
+			n := c.victim(l)
+			if n == SLRU_EOF {
+				// Rest of the list is pinned
+				break
+			}
+			c.dropVictim(l, n, "Weight")
+			c.freelist.insertHead(n)
+			evicted++
+		}
+	}
+
+	c.stats.Evictions += uint64(evicted)
+	c.stats.WeightEvictions += uint64(evicted)
+	if c.webhook != nil {
+		c.webhook.evicted(evicted)
+	}
+	return evicted
+}
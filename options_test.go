@@ -0,0 +1,93 @@
+package slrucache
+
+import (
+	"testing"
+	"time"
+)
+
+// TestInsertWithOptions tests per entry TTL, priority and pinning.
+func TestInsertWithOptions(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	clock := newTestClock(c)
+	c.SetTTL(time.Hour)
+
+	c.InsertWithOptions("short", "s", WithTTL(time.Second))
+	c.InsertWithOptions("forever", "f", WithTTL(0), WithPriority(PriorityHigh))
+	if info, _ := c.EntryInfo("forever"); info.TTL != 0 || info.Priority != PriorityHigh {
+		t.Errorf("info %+v", info)
+	}
+	clock.advance(2 * time.Second)
+	if c.Lookup("short") != nil || c.Lookup("forever") == nil {
+		t.Error("per entry TTL not applied")
+	}
+
+	if !c.InsertWithOptions("pinned", "p", WithPin()) || !c.Pinned("pinned") {
+		t.Fatal("entry should be pinned")
+	}
+	// Fill the probelist, the pinned entry must survive
+	for _, k := range []string{"a", "b", "c"} {
+		c.Insert(k, k)
+	}
+	if c.Lookup("pinned") == nil {
+		t.Error("pinned entry evicted")
+	}
+	c.Unpin("pinned")
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestMaxWeight tests weight accounting and eviction by weight.
+func TestMaxWeight(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	c.SetMaxWeight(10)
+
+	c.InsertWithOptions("a", "a", WithWeight(4))
+	c.InsertWithOptions("b", "b", WithWeight(4))
+	c.Insert("c", "c")
+	if c.Weight() != 9 {
+		t.Errorf("weight %d", c.Weight())
+	}
+
+	// Exceeding the limit evicts the oldest probationary entries
+	c.InsertWithOptions("d", "d", WithWeight(3))
+	if c.Lookup("a") != nil || c.Weight() != 8 {
+		t.Errorf("a should be evicted, weight %d", c.Weight())
+	}
+
+	// Updates change the weight of existing entries
+	c.InsertWithOptions("d", "d", WithWeight(1))
+	if c.Weight() != 6 || c.Stats().Evictions != 1 {
+		t.Errorf("weight %d after update", c.Weight())
+	}
+
+	c.SetMaxWeight(2)
+	if c.Weight() > 2 || checkSLRUCacheSanity(c) {
+		t.Errorf("weight %d above lowered limit", c.Weight())
+	}
+}
+
+// TestEvictionBatch tests that exceeding the weight limit evicts a batch.
+func TestEvictionBatch(t *testing.T) {
+	c := NewSLRUCache[int, int](10, 20)
+	c.SetMaxWeight(10)
+	c.SetEvictionBatch(4)
+	for i := 0; i < 10; i++ {
+		c.Insert(i, i)
+	}
+
+	// One entry over the limit evicts 4, leaving room for 3 more inserts
+	c.Insert(10, 10)
+	if c.Weight() != 7 || c.Lookup(3) != nil || c.Lookup(4) == nil {
+		t.Errorf("weight %d after batch eviction", c.Weight())
+	}
+	for i := 11; i < 14; i++ {
+		c.Insert(i, i)
+	}
+	if s := c.Stats(); s.Evictions != 4 || s.WeightEvictions != 4 {
+		t.Errorf("evictions %d/%d, want 4", s.Evictions, s.WeightEvictions)
+	}
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,149 @@
+// author: (c) Gunter Hartmann
+
+// Package otelcache instruments slrucache caches with OpenTelemetry. It is a
+// separate module, so the cache itself does not depend on OpenTelemetry.
+//
+// Metrics are recorded with the attribute cache.name:
+//
+//	cache.hits               lookups finding an entry
+//	cache.misses             lookups finding no entry
+//	cache.inserts            inserted or updated entries
+//	cache.evictions          entries evicted for capacity
+//	cache.loader.duration    duration of loader calls in seconds
+//
+// Loader calls wrapped by WrapLoader are traced as spans named cache.load.
+package otelcache
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"slrucache"
+)
+
+// scope is the instrumentation scope name.
+const scope = "slrucache/otelcache"
+
+// Option configures an Instrumentation.
+type Option func(*config)
+
+type config struct {
+	meterProvider  metric.MeterProvider
+	tracerProvider trace.TracerProvider
+}
+
+// WithMeterProvider sets the meter provider, the global one by default.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) { c.meterProvider = mp }
+}
+
+// WithTracerProvider sets the tracer provider, the global one by default.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) { c.tracerProvider = tp }
+}
+
+// Instrumentation records the metrics of one cache. It implements
+// slrucache.Hooks.
+type Instrumentation[K comparable, V any] struct {
+	attrs  metric.MeasurementOption
+	name   attribute.KeyValue
+	tracer trace.Tracer
+
+	hits      metric.Int64Counter
+	misses    metric.Int64Counter
+	inserts   metric.Int64Counter
+	evictions metric.Int64Counter
+	loads     metric.Float64Histogram
+}
+
+// New creates the instruments for a cache called name.
+func New[K comparable, V any](name string, opts ...Option) (*Instrumentation[K, V], error) {
+	cfg := config{
+		meterProvider:  otel.GetMeterProvider(),
+		tracerProvider: otel.GetTracerProvider(),
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+
+	meter := cfg.meterProvider.Meter(scope)
+	i := &Instrumentation[K, V]{
+		name:   attribute.String("cache.name", name),
+		tracer: cfg.tracerProvider.Tracer(scope),
+	}
+	i.attrs = metric.WithAttributes(i.name)
+
+	var err error
+	if i.hits, err = meter.Int64Counter("cache.hits", metric.WithDescription("Lookups finding an entry")); err != nil {
+		return nil, err
+	}
+	if i.misses, err = meter.Int64Counter("cache.misses", metric.WithDescription("Lookups finding no entry")); err != nil {
+		return nil, err
+	}
+	if i.inserts, err = meter.Int64Counter("cache.inserts", metric.WithDescription("Inserted or updated entries")); err != nil {
+		return nil, err
+	}
+	if i.evictions, err = meter.Int64Counter("cache.evictions", metric.WithDescription("Entries evicted for capacity")); err != nil {
+		return nil, err
+	}
+	if i.loads, err = meter.Float64Histogram("cache.loader.duration", metric.WithDescription("Duration of loader calls"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Instrument creates an Instrumentation for c and attaches it as hooks,
+// replacing hooks set before. Wrap the loader with WrapLoader to trace
+// loads as well.
+func Instrument[K comparable, V any](c *slrucache.SLRUCache[K, V], name string, opts ...Option) (*Instrumentation[K, V], error) {
+	i, err := New[K, V](name, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.SetHooks(i)
+	return i, nil
+}
+
+// OnHit counts a hit.
+func (i *Instrumentation[K, V]) OnHit(key K) {
+	i.hits.Add(context.Background(), 1, i.attrs)
+}
+
+// OnMiss counts a miss.
+func (i *Instrumentation[K, V]) OnMiss(key K) {
+	i.misses.Add(context.Background(), 1, i.attrs)
+}
+
+// OnInsert counts an insert.
+func (i *Instrumentation[K, V]) OnInsert(key K, value V) {
+	i.inserts.Add(context.Background(), 1, i.attrs)
+}
+
+// OnEvict counts an eviction.
+func (i *Instrumentation[K, V]) OnEvict(key K, value V) {
+	i.evictions.Add(context.Background(), 1, i.attrs)
+}
+
+// WrapLoader returns a loader calling load in a cache.load span and
+// recording its duration.
+func (i *Instrumentation[K, V]) WrapLoader(load slrucache.LoaderFunc[K, V]) slrucache.LoaderFunc[K, V] {
+	return func(ctx context.Context, key K) (V, error) {
+		ctx, span := i.tracer.Start(ctx, "cache.load", trace.WithAttributes(i.name))
+		defer span.End()
+
+		start := time.Now()
+		v, err := load(ctx, key)
+		i.loads.Record(ctx, time.Since(start).Seconds(), i.attrs)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return v, err
+	}
+}
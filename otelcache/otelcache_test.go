@@ -0,0 +1,81 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"slrucache"
+)
+
+// sums returns the values of the integer sums collected by r by name.
+func sums(t *testing.T, r *sdkmetric.ManualReader) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+	if err := r.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[string]int64)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, p := range d.DataPoints {
+					out[m.Name] += p.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, p := range d.DataPoints {
+					out[m.Name] += int64(p.Count)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// TestInstrument tests the recorded metrics and loader spans.
+func TestInstrument(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	spans := tracetest.NewSpanRecorder()
+	c := slrucache.NewSLRUCache[string, string](1, 1)
+	i, err := Instrument(c, "test",
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetLoader(i.WrapLoader(func(ctx context.Context, key string) (string, error) {
+		if key == "bad" {
+			return "", errors.New("load failed")
+		}
+		return key, nil
+	}))
+
+	c.Insert("a", "a")
+	c.Lookup("a")
+	c.Lookup("b")
+	c.Insert("b", "b")
+	c.GetOrComputeCtx(context.Background(), "c", nil) // evicts b
+	c.GetOrComputeCtx(context.Background(), "bad", nil)
+
+	got := sums(t, reader)
+	for name, want := range map[string]int64{
+		"cache.hits":            1,
+		"cache.misses":          3,
+		"cache.evictions":       1,
+		"cache.loader.duration": 2,
+	} {
+		if got[name] != want {
+			t.Errorf("%s = %d, want %d", name, got[name], want)
+		}
+	}
+
+	ended := spans.Ended()
+	if len(ended) != 2 || ended[0].Name() != "cache.load" || ended[1].Status().Description != "load failed" {
+		t.Errorf("unexpected spans %v", ended)
+	}
+}
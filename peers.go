@@ -0,0 +1,181 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultPeerPath is the URL path prefix served by a PeerGroup.
+const DefaultPeerPath = "/_slrucache/"
+
+// peerReplicas is the number of points per peer on the hash ring.
+const peerReplicas = 50
+
+// ErrPeerNotFound is returned when the owning peer does not know a key.
+// It wraps ErrNotFound, so errors.Is(err, ErrNotFound) holds for it.
+var ErrPeerNotFound = fmt.Errorf("%w on peer", ErrNotFound)
+
+// hashRing maps keys consistently to peers.
+type hashRing struct {
+	points []uint32          // sorted hash points
+	owners map[uint32]string // point to peer
+}
+
+// newHashRing places peerReplicas points per peer on the ring.
+func newHashRing(peers []string) *hashRing {
+	r := &hashRing{owners: make(map[uint32]string)}
+	for _, p := range peers {
+		for i := 0; i < peerReplicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + p))
+			r.points = append(r.points, h)
+			r.owners[h] = p
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return r
+}
+
+// owner returns the peer owning key, or "" if the ring is empty.
+func (r *hashRing) owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// PeerGroup turns the caches of several processes into a simple distributed
+// cache, in the style of groupcache. Keys are consistently hashed to an
+// owning peer. A local miss for a key owned by another peer is fetched from
+// that peer over HTTP; the owner serves it from its cache or loads it with
+// the load function. Fetched values are cached locally, so hot keys are
+// served without a round trip. Concurrent misses of a key share one fetch
+// or load, like GetOrCompute. Values are transferred using encoding/gob.
+// The load function reports unknown keys with ErrNotFound.
+type PeerGroup[V any] struct {
+	self   string // base URL of this process, as listed in the peers
+	path   string // URL path prefix
+	cache  *SLRUCache[string, V]
+	load   func(ctx context.Context, key string) (V, error)
+	client *http.Client
+
+	mu   sync.RWMutex
+	ring *hashRing
+}
+
+// NewPeerGroup creates a PeerGroup for the process reachable at self,
+// e.g. "http://10.0.0.1:8080", using cache for local entries and load
+// to produce values of owned keys. The group must be mounted with
+// http.Handle(DefaultPeerPath, group).
+func NewPeerGroup[V any](self string, cache *SLRUCache[string, V], load func(ctx context.Context, key string) (V, error)) *PeerGroup[V] {
+	g := &PeerGroup[V]{
+		self:   strings.TrimSuffix(self, "/"),
+		path:   DefaultPeerPath,
+		cache:  cache,
+		load:   load,
+		client: http.DefaultClient,
+	}
+	g.SetPeers(self)
+	return g
+}
+
+// SetPeers replaces the set of peers, including this process.
+func (g *PeerGroup[V]) SetPeers(peers ...string) {
+	trimmed := make([]string, len(peers))
+	for i, p := range peers {
+		trimmed[i] = strings.TrimSuffix(p, "/")
+	}
+	ring := newHashRing(trimmed)
+
+	g.mu.Lock()
+	g.ring = ring
+	g.mu.Unlock()
+}
+
+// Owner returns the base URL of the peer owning key.
+func (g *PeerGroup[V]) Owner(key string) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.ring.owner(key)
+}
+
+// Get returns the value for key from the local cache, the owning peer or the load function.
+func (g *PeerGroup[V]) Get(ctx context.Context, key string) (V, error) {
+	return g.cache.GetOrComputeCtx(ctx, key, g.fetchOrLoad)
+}
+
+// fetchOrLoad fetches key from its owner, or loads it if owned by this process.
+func (g *PeerGroup[V]) fetchOrLoad(ctx context.Context, key string) (V, error) {
+	owner := g.Owner(key)
+	if owner == "" || owner == g.self {
+		return g.load(ctx, key)
+	}
+	return g.fetch(ctx, owner, key)
+}
+
+// fetch requests key from peer.
+func (g *PeerGroup[V]) fetch(ctx context.Context, peer string, key string) (V, error) {
+	var v V
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, peer+g.path+url.PathEscape(key), nil)
+	if err != nil {
+		return v, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return v, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return v, ErrPeerNotFound
+	default:
+		return v, fmt.Errorf("slrucache: peer %s returned %s", peer, resp.Status)
+	}
+
+	err = gob.NewDecoder(resp.Body).Decode(&v)
+	return v, err
+}
+
+// ServeHTTP answers requests of other peers for keys owned by this process.
+func (g *PeerGroup[V]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.EscapedPath(), g.path) {
+		http.NotFound(w, r)
+		return
+	}
+	key, err := url.PathUnescape(strings.TrimPrefix(r.URL.EscapedPath(), g.path))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	v, err := g.cache.GetOrComputeCtx(r.Context(), key, g.load)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	gob.NewEncoder(w).Encode(&v)
+}
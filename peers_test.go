@@ -0,0 +1,122 @@
+package slrucache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestHashRing tests that keys map consistently and spread across peers.
+func TestHashRing(t *testing.T) {
+	r := newHashRing([]string{"a", "b", "c"})
+	counts := map[string]int{}
+	for i := 0; i < 300; i++ {
+		k := strconv.Itoa(i)
+		if r.owner(k) != r.owner(k) {
+			t.Fatal("owner not stable")
+		}
+		counts[r.owner(k)]++
+	}
+	if len(counts) != 3 {
+		t.Errorf("keys should spread over all peers: %v", counts)
+	}
+}
+
+// TestPeerGroup tests fetching keys from the owning peer.
+func TestPeerGroup(t *testing.T) {
+	var loads [2]int32
+	groups := make([]*PeerGroup[string], 2)
+	servers := make([]*httptest.Server, 2)
+	for i := range groups {
+		i := i
+		servers[i] = httptest.NewUnstartedServer(nil)
+		self := "http://" + servers[i].Listener.Addr().String()
+		groups[i] = NewPeerGroup(self, NewSLRUCache[string, string](10, 10),
+			func(ctx context.Context, key string) (string, error) {
+				atomic.AddInt32(&loads[i], 1)
+				if key == "missing" {
+					return "", ErrPeerNotFound
+				}
+				return "value-" + key, nil
+			})
+		servers[i].Config.Handler = groups[i]
+		servers[i].Start()
+		defer servers[i].Close()
+	}
+	peers := []string{servers[0].URL, servers[1].URL}
+	for _, g := range groups {
+		g.SetPeers(peers...)
+	}
+
+	// find a key owned by peer 1
+	key := ""
+	for i := 0; key == ""; i++ {
+		if k := strconv.Itoa(i); groups[0].Owner(k) == peers[1] {
+			key = k
+		}
+	}
+
+	for n := 0; n < 3; n++ {
+		v, err := groups[0].Get(context.Background(), key)
+		if err != nil || v != "value-"+key {
+			t.Fatalf("unexpected result %q %v", v, err)
+		}
+	}
+	if loads[0] != 0 || loads[1] != 1 {
+		t.Errorf("key should be loaded once by its owner: %v", loads)
+	}
+
+	// ask the other peer for a key it cannot load
+	groups[0].SetPeers(peers[1])
+	if _, err := groups[0].Get(context.Background(), "missing"); !errors.Is(err, ErrPeerNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrPeerNotFound, got %v", err)
+	}
+}
+
+// TestPeerGroupShared tests that concurrent misses of a key share one fetch.
+func TestPeerGroupShared(t *testing.T) {
+	var requests int32
+	started, release := make(chan struct{}), make(chan struct{})
+	owner := NewPeerGroup("http://owner", NewSLRUCache[string, string](10, 10),
+		func(ctx context.Context, key string) (string, error) {
+			close(started)
+			<-release
+			return "value", nil
+		})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		owner.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	cache := NewSLRUCache[string, string](10, 10)
+	cache.mu = new(sync.RWMutex)
+	g := NewPeerGroup("http://self", cache, nil)
+	g.SetPeers(server.URL)
+
+	var wg sync.WaitGroup
+	get := func() {
+		defer wg.Done()
+		if v, err := g.Get(context.Background(), "k"); err != nil || v != "value" {
+			t.Errorf("unexpected result %q %v", v, err)
+		}
+	}
+	wg.Add(5)
+	go get()
+	<-started // the first fetch is in flight
+	for i := 0; i < 4; i++ {
+		go get()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if requests != 1 {
+		t.Errorf("%d requests, want 1", requests)
+	}
+}
@@ -0,0 +1,91 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Pin exempts the entry for key from eviction until it is unpinned.
+// Pins are counted, every Pin needs a matching Unpin. The evictor skips
+// pinned entries and picks the next candidate; if a segment is pinned
+// completely, Insert drops the new entry and Lookup does not promote.
+// Remove still removes pinned entries.
+// Returns false if the key is not cached.
+func (c *SLRUCache[K, V]) Pin(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.find(key)
+	if !ok {
+		return false
+	}
+	c.entries[n].pins++
+	return true
+}
+
+// Unpin releases one pin of the entry for key.
+// Returns false if the key is not cached or not pinned.
+func (c *SLRUCache[K, V]) Unpin(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.find(key)
+	if !ok || c.entries[n].pins == 0 {
+		return false
+	}
+	c.entries[n].pins--
+	return true
+}
+
+// Pinned reports whether the entry for key is pinned.
+func (c *SLRUCache[K, V]) Pinned(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n, ok := c.find(key)
+	return ok && c.entries[n].pins > 0
+}
+
+// Acquire looks up key like Lookup and pins the entry on a hit, returning
+// the value and a func releasing the pin. The entry is skipped by eviction
+// until released, so the returned pointer can be used without copying the
+// value; it stays valid unless the key is updated or removed meanwhile.
+// Release may be called more than once, only the first call unpins, and
+// only the acquired entry, not a later entry for the same key.
+// On a miss Acquire returns nil and a release func doing nothing.
+func (c *SLRUCache[K, V]) Acquire(key K) (*V, func()) {
+	out := lookupOut[V]{pin: true}
+	v, state := c.lookup(key, &out)
+	if state != LookupHit {
+		return nil, func() {}
+	}
+
+	released := false
+	return v, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		e := &c.entries[out.n]
+		if released || e.tag == tagFree || e.key != key || c.leases[out.n] != out.lease || e.pins == 0 {
+			// Released already or the entry was removed meanwhile, maybe
+			// reinserted in the same slot
+			released = true
+			return
+		}
+		released = true
+		e.pins--
+	}
+}
+
+// lease returns the generation of the entry at index n, which stays the
+// same until the entry is removed, so a release func can tell the entry
+// it pinned from a later one reusing the slot.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) lease(n int) uint64 {
+	if gen, ok := c.leases[n]; ok {
+		return gen
+	}
+	if c.leases == nil {
+		c.leases = make(map[int]uint64)
+	}
+	c.leaseGen++
+	c.leases[n] = c.leaseGen
+	return c.leaseGen
+}
@@ -0,0 +1,118 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestPinProbe tests that pinned probationary entries survive inserts.
+func TestPinProbe(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	insertN(c, 3, 0)
+	if !c.Pin("0") || !c.Pinned("0") || c.Pin("x") {
+		t.Fatal("pin failed")
+	}
+
+	insertN(c, 2, 10)
+	if _, ok := c.mapping["0"]; !ok {
+		t.Error("pinned entry was evicted")
+	}
+	if _, ok := c.mapping["1"]; ok {
+		t.Error("next candidate should have been evicted")
+	}
+
+	// pin everything, inserts are dropped
+	c.Pin("10")
+	c.Pin("11")
+	c.Insert("new", "new")
+	if _, ok := c.mapping["new"]; ok || checkSLRUCacheSanity(c) {
+		t.Error("insert into pinned probe should be dropped")
+	}
+
+	if !c.Unpin("0") || c.Unpin("0") || c.Pinned("0") {
+		t.Error("unpin failed")
+	}
+	c.Insert("new", "new")
+	if _, ok := c.mapping["0"]; ok || checkSLRUCacheSanity(c) {
+		t.Error("unpinned entry should be evicted")
+	}
+}
+
+// TestPinProtected tests that pinned protected entries survive promotions.
+func TestPinProtected(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	insertN(c, 2, 0)
+	lookupN(c, 2, 0)
+	c.Pin("0")
+	c.Pin("1")
+
+	// lrulist pinned completely, hit stays in probe
+	c.Insert("2", "2")
+	c.Lookup("2")
+	if checkListCount(c, 1, 2, 1, "pinned lrulist") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+
+	c.Unpin("1")
+	c.Lookup("2")
+	if c.Lookup("1") != nil || c.Lookup("0") == nil || checkSLRUCacheSanity(c) {
+		t.Error("unpinned protected entry should be evicted")
+	}
+
+	// removing a pinned entry clears the pin
+	if !c.Remove("0") || c.Pinned("0") {
+		t.Error("remove of pinned entry failed")
+	}
+}
+
+// TestAcquire tests that acquired entries survive eviction until released.
+func TestAcquire(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	c.SetConcurrentReads(true)
+	c.Insert("0", "0")
+	c.Lookup("0")
+
+	v, release := c.Acquire("0")
+	if v == nil || *v != "0" || !c.Pinned("0") {
+		t.Fatal("acquire should pin the entry")
+	}
+	c.Insert("1", "1")
+	c.Lookup("1")
+	if *v != "0" || c.Lookup("0") == nil {
+		t.Error("acquired entry should not be evicted")
+	}
+
+	release()
+	release()
+	if c.Pinned("0") {
+		t.Error("release should unpin the entry")
+	}
+	c.Lookup("1")
+	if c.Lookup("0") != nil {
+		t.Error("released entry should be evicted")
+	}
+
+	if v, release := c.Acquire("missing"); v != nil || release == nil {
+		t.Error("miss should return nil and a release func")
+	}
+}
+
+// TestAcquireReinsert tests that a release does not unpin a reinserted entry in the same slot.
+func TestAcquireReinsert(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	c.Insert("a", "a")
+	c.Lookup("a")
+	c.Insert("0", "0")
+	c.SetPromotionThreshold(2) // keep the acquired entry in its slot
+	n, _ := c.find("0")
+	_, release := c.Acquire("0")
+	c.Remove("0")
+	c.Insert("0", "1")
+	if m, _ := c.find("0"); m != n || !c.Pin("0") {
+		t.Fatal("reinserted entry should reuse the only free slot")
+	}
+
+	release()
+	if !c.Pinned("0") {
+		t.Error("release of the removed entry should not unpin the new one")
+	}
+}
@@ -0,0 +1,217 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"math/rand"
+)
+
+// Policy decides which entry of a list is evicted next and how hits and
+// inserts affect the order of the list. The cache owns the lists; a policy
+// only inspects and reorders them through the exported SLRUList methods.
+// Policies are called with the cache mutex held.
+type Policy[K comparable, V any] interface {
+	// Victim returns the index of the entry to evict from l, or SLRU_EOF if l is empty.
+	// The cache removes the returned entry from l.
+	Victim(l *SLRUList[K, V]) int
+	// Hit records a hit on the entry at index n of l.
+	Hit(l *SLRUList[K, V], n int)
+	// Inserted records that the entry at index n was inserted at the head of l.
+	Inserted(l *SLRUList[K, V], n int)
+}
+
+// Skipper is implemented by policies that decide how the cache passes over
+// a victim it cannot evict because it is pinned or of higher priority.
+// Without Skipper the cache moves such entries to the head of the list.
+type Skipper[K comparable, V any] interface {
+	// Skip moves the entry at index n of l out of the way of Victim.
+	Skip(l *SLRUList[K, V], n int)
+}
+
+// LRUPolicy evicts the least recently used entry. It is the default policy.
+type LRUPolicy[K comparable, V any] struct{}
+
+// Victim returns the tail of the list.
+func (LRUPolicy[K, V]) Victim(l *SLRUList[K, V]) int {
+	return l.Tail()
+}
+
+// Hit moves the entry to the head of the list.
+func (LRUPolicy[K, V]) Hit(l *SLRUList[K, V], n int) {
+	l.MoveToHead(n)
+}
+
+// Inserted does nothing, new entries already are at the head.
+func (LRUPolicy[K, V]) Inserted(l *SLRUList[K, V], n int) {}
+
+// FIFOPolicy evicts the entry inserted first, hits do not reorder the list.
+// Set as probation policy it makes the probelist strictly FIFO: updates of
+// existing keys never move entries, so inserts touch the list only once.
+type FIFOPolicy[K comparable, V any] struct{}
+
+// Victim returns the tail of the list.
+func (FIFOPolicy[K, V]) Victim(l *SLRUList[K, V]) int {
+	return l.Tail()
+}
+
+// Hit does nothing.
+func (FIFOPolicy[K, V]) Hit(l *SLRUList[K, V], n int) {}
+
+// Inserted does nothing.
+func (FIFOPolicy[K, V]) Inserted(l *SLRUList[K, V], n int) {}
+
+// MRUPolicy evicts the most recently inserted or used entry. Used for the
+// probelist it keeps older entries of cyclic scans larger than the cache.
+type MRUPolicy[K comparable, V any] struct{}
+
+// Victim returns the head of the list.
+func (MRUPolicy[K, V]) Victim(l *SLRUList[K, V]) int {
+	return l.Head()
+}
+
+// Hit moves the entry to the head of the list.
+func (MRUPolicy[K, V]) Hit(l *SLRUList[K, V], n int) {
+	l.MoveToHead(n)
+}
+
+// Inserted does nothing, new entries already are at the head.
+func (MRUPolicy[K, V]) Inserted(l *SLRUList[K, V], n int) {}
+
+// Skip moves the entry to the tail of the list.
+func (MRUPolicy[K, V]) Skip(l *SLRUList[K, V], n int) {
+	l.MoveToTail(n)
+}
+
+// RandomPolicy evicts a uniformly chosen entry of the list.
+// Victim selection walks the list and is O(n).
+type RandomPolicy[K comparable, V any] struct {
+	rnd *rand.Rand
+}
+
+// NewRandomPolicy creates a RandomPolicy using the given seed.
+func NewRandomPolicy[K comparable, V any](seed int64) *RandomPolicy[K, V] {
+	return &RandomPolicy[K, V]{rnd: rand.New(rand.NewSource(seed))}
+}
+
+// Victim returns a random entry of the list.
+func (p *RandomPolicy[K, V]) Victim(l *SLRUList[K, V]) int {
+	if l.Len() == 0 {
+		return SLRU_EOF
+	}
+	n := l.Tail()
+	for i := p.rnd.Intn(l.Len()); i > 0; i-- {
+		n = l.Prev(n)
+	}
+	return n
+}
+
+// Hit does nothing.
+func (p *RandomPolicy[K, V]) Hit(l *SLRUList[K, V], n int) {}
+
+// Inserted does nothing.
+func (p *RandomPolicy[K, V]) Inserted(l *SLRUList[K, V], n int) {}
+
+// ClockPolicy implements the CLOCK (second chance) algorithm.
+// Hits only set a reference bit; the victim search gives referenced
+// entries at the tail a second chance by moving them to the head.
+type ClockPolicy[K comparable, V any] struct {
+	ref map[int]bool // reference bits by entry index
+}
+
+// NewClockPolicy creates a ClockPolicy.
+func NewClockPolicy[K comparable, V any]() *ClockPolicy[K, V] {
+	return &ClockPolicy[K, V]{ref: make(map[int]bool)}
+}
+
+// Victim returns the first unreferenced entry from the tail, clearing
+// reference bits of the entries passed over.
+func (p *ClockPolicy[K, V]) Victim(l *SLRUList[K, V]) int {
+	for {
+		t := l.Tail()
+		if t == SLRU_EOF || !p.ref[t] {
+			return t
+		}
+		delete(p.ref, t)
+		l.MoveToHead(t)
+	}
+}
+
+// Hit sets the reference bit of the entry.
+func (p *ClockPolicy[K, V]) Hit(l *SLRUList[K, V], n int) {
+	p.ref[n] = true
+}
+
+// Inserted clears the reference bit of a reused entry.
+func (p *ClockPolicy[K, V]) Inserted(l *SLRUList[K, V], n int) {
+	delete(p.ref, n)
+}
+
+// DefaultSamples is the sample size of a SampledPolicy created with 0 samples.
+const DefaultSamples = 5
+
+// SampledPolicy approximates LRU like Redis does: it samples a few random
+// entries of the list and evicts the least recently used among them.
+// Hits and inserts only record a logical timestamp, the list is never
+// reordered, which saves list maintenance on very large caches.
+type SampledPolicy[K comparable, V any] struct {
+	samples int
+	rnd     *rand.Rand
+	tick    uint64
+	stamp   map[int]uint64 // logical time of the last hit or insert by entry index
+}
+
+// NewSampledPolicy creates a SampledPolicy drawing samples entries per
+// eviction, DefaultSamples if samples is not positive, using the given seed.
+func NewSampledPolicy[K comparable, V any](samples int, seed int64) *SampledPolicy[K, V] {
+	if samples <= 0 {
+		samples = DefaultSamples
+	}
+	return &SampledPolicy[K, V]{
+		samples: samples,
+		rnd:     rand.New(rand.NewSource(seed)),
+		stamp:   make(map[int]uint64),
+	}
+}
+
+// Victim returns the least recently used of the sampled entries. Samples
+// are drawn from the backing array, slots of other lists are skipped.
+// If the list is too sparse to draw any sample, the tail is returned.
+func (p *SampledPolicy[K, V]) Victim(l *SLRUList[K, V]) int {
+	if l.Len() == 0 {
+		return SLRU_EOF
+	}
+
+	entries := *l.entries
+	victim := SLRU_EOF
+	found := 0
+	for i := 0; i < 4*p.samples && found < p.samples; i++ {
+		n := p.rnd.Intn(len(entries))
+		if entries[n].tag != l.tag {
+			continue
+		}
+		found++
+		if victim == SLRU_EOF || p.stamp[n] < p.stamp[victim] {
+			victim = n
+		}
+	}
+
+	if victim == SLRU_EOF {
+		return l.Tail()
+	}
+	return victim
+}
+
+// Hit records the access time of the entry.
+func (p *SampledPolicy[K, V]) Hit(l *SLRUList[K, V], n int) {
+	p.tick++
+	p.stamp[n] = p.tick
+}
+
+// Inserted records the insert time of the entry.
+func (p *SampledPolicy[K, V]) Inserted(l *SLRUList[K, V], n int) {
+	p.tick++
+	p.stamp[n] = p.tick
+}
+
+// Skip does nothing, the next Victim call draws new samples.
+func (p *SampledPolicy[K, V]) Skip(l *SLRUList[K, V], n int) {}
@@ -0,0 +1,128 @@
+package slrucache
+
+import (
+	"strconv"
+	"testing"
+)
+
+// TestPolicyFIFO tests that hits do not protect entries under the FIFO policy.
+func TestPolicyFIFO(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetPolicy(FIFOPolicy[string, string]{})
+	insertN(c, 3, 0)
+	lookupN(c, 3, 0)
+
+	// hit the oldest protected entry, FIFO keeps it at the tail
+	c.Lookup("0")
+	insertN(c, 1, 3)
+	lookupN(c, 1, 3)
+
+	if c.Lookup("0") != nil || c.Lookup("1") == nil {
+		t.Error("FIFO policy should evict the first promoted entry")
+	}
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestPolicyClock tests that referenced entries get a second chance.
+func TestPolicyClock(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetPolicy(NewClockPolicy[string, string]())
+	insertN(c, 3, 0)
+	lookupN(c, 3, 0)
+
+	// reference the oldest protected entry
+	c.Lookup("0")
+	insertN(c, 1, 3)
+	lookupN(c, 1, 3)
+
+	if c.Lookup("0") == nil || c.Lookup("1") != nil {
+		t.Error("CLOCK policy should spare the referenced entry")
+	}
+	if checkListCount(c, 3, 3, 0, "clock") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestPolicyRandom tests that the random policy keeps the cache consistent.
+func TestPolicyRandom(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	c.SetPolicy(NewRandomPolicy[string, string](1))
+	movingWindow(c, 10, 21, 7, 1, true)
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if c.lrulist.count != 10 || c.probelist.count+c.lrulist.count+c.freelist.count != c.cnum {
+		t.Error("random policy lost entries")
+	}
+}
+
+// TestPolicyMRUProbation tests that an MRU probelist keeps the start of a scan.
+func TestPolicyMRUProbation(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 3)
+	c.SetProbationPolicy(MRUPolicy[string, string]{})
+	insertN(c, 5, 0)
+
+	if c.Lookup("0") == nil || c.Lookup("1") == nil || c.Lookup("2") != nil || c.Lookup("3") != nil {
+		t.Error("MRU probation should evict the most recent inserts")
+	}
+
+	// pinned heads are passed over
+	insertN(c, 2, 5)
+	c.Pin("6")
+	c.Insert("7", "7")
+	if _, ok := c.mapping["6"]; !ok {
+		t.Error("pinned head should not be evicted")
+	}
+	if _, ok := c.mapping["5"]; ok || checkListCount(c, 0, 2, 3, "mru pin") || checkSLRUCacheSanity(c) {
+		t.Error("next most recent entry should be evicted")
+	}
+}
+
+// TestPolicyFIFOProbation tests that a FIFO probelist is never reordered.
+func TestPolicyFIFOProbation(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 3)
+	c.SetProbationPolicy(FIFOPolicy[string, string]{})
+	insertN(c, 3, 0)
+
+	// updating the oldest entry does not save it
+	c.Insert("0", "x")
+	if k := listKeys(c, c.probelist); !equalKeys(k, []string{"2", "1", "0"}) {
+		t.Errorf("update should not reorder, got %v", k)
+	}
+	c.Insert("3", "3")
+	if k := listKeys(c, c.probelist); !equalKeys(k, []string{"3", "2", "1"}) || checkSLRUCacheSanity(c) {
+		t.Errorf("first inserted entry should be evicted, got %v", k)
+	}
+}
+
+// TestPolicySampled tests that sampled eviction prefers old entries.
+func TestPolicySampled(t *testing.T) {
+	c := NewSLRUCache[string, string](50, 50)
+	c.SetPolicy(NewSampledPolicy[string, string](10, 1))
+	insertN(c, 50, 0)
+	lookupN(c, 50, 0)
+
+	// keep the upper half recently used, then push new entries in
+	lookupN(c, 25, 25)
+	insertN(c, 20, 100)
+	lookupN(c, 20, 100)
+
+	hot := 0
+	for i := 25; i < 50; i++ {
+		if _, ok := c.mapping[strconv.Itoa(i)]; ok {
+			hot++
+		}
+	}
+	if hot < 20 {
+		t.Errorf("sampled eviction should keep most recent entries, kept %d of 25", hot)
+	}
+	if checkListCount(c, 50, 50, 0, "sampled") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	movingWindow(c, 10, 21, 7, 1, true)
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,101 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// prefetchWorkers is the number of loads a Prefetch runs concurrently.
+const prefetchWorkers = 8
+
+// PrefetchResult is the outcome of a load scheduled by Prefetch.
+type PrefetchResult[K comparable] struct {
+	Key K
+	Err error // nil if the loaded value was cached
+}
+
+// Prefetch is PrefetchCtx with a background context.
+func (c *SLRUCache[K, V]) Prefetch(keys []K) <-chan PrefetchResult[K] {
+	return c.PrefetchCtx(context.Background(), keys)
+}
+
+// PrefetchCtx schedules background loads for the keys not cached and not
+// being loaded, without blocking the caller. Like GetOrCompute without a
+// loader, it loads with the configured loader, or the read-through store
+// if none is set, and caches the values without writing them through.
+// At most 8 loads of one call run at a time, the others wait in line. The
+// loader receives ctx; loads not started when ctx is done fail with
+// ctx.Err().
+//
+// The returned channel receives the result of each scheduled load and is
+// closed after the last one. It is buffered for all results, so callers
+// not interested in failures may drop it. Without loader and read-through
+// store every scheduled key fails with ErrNoLoader.
+func (c *SLRUCache[K, V]) PrefetchCtx(ctx context.Context, keys []K) <-chan PrefetchResult[K] {
+	c.mu.Lock()
+	loader := c.loader
+	if loader == nil && c.readThrough != nil {
+		loader = c.readThrough.Get
+	}
+
+	var pending []K
+	var calls []*loadCall[V]
+	for _, key := range keys {
+		if n, ok := c.find(key); ok && !c.expired(n) {
+			continue
+		}
+		if _, ok := c.calls[key]; ok {
+			continue
+		}
+		if loader == nil {
+			// Nothing to load, keys are only collected for the results
+			if !slices.Contains(pending, key) {
+				pending = append(pending, key)
+			}
+			continue
+		}
+
+		call := &loadCall[V]{done: make(chan struct{})}
+		if c.calls == nil {
+			c.calls = make(map[K]*loadCall[V])
+		}
+		c.calls[key] = call
+		pending = append(pending, key)
+		calls = append(calls, call)
+	}
+	c.mu.Unlock()
+
+	results := make(chan PrefetchResult[K], len(pending))
+	if loader == nil {
+		for _, key := range pending {
+			results <- PrefetchResult[K]{Key: key, Err: ErrNoLoader}
+		}
+		close(results)
+		return results
+	}
+
+	work := make(chan int, len(pending))
+	for i := range pending {
+		work <- i
+	}
+	close(work)
+	var wg sync.WaitGroup
+	for range min(prefetchWorkers, len(pending)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				c.load(ctx, pending[i], loader, calls[i])
+				results <- PrefetchResult[K]{Key: pending[i], Err: calls[i].err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
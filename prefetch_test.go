@@ -0,0 +1,103 @@
+package slrucache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestPrefetch tests that missing keys are loaded in the background.
+func TestPrefetch(t *testing.T) {
+	c := NewSLRUCache[int, int](10, 10)
+	c.mu = new(sync.RWMutex)
+	c.Insert(1, 1)
+
+	var mu sync.Mutex
+	var loaded []int
+	var wg sync.WaitGroup
+	wg.Add(2)
+	c.SetLoader(func(ctx context.Context, key int) (int, error) {
+		defer wg.Done()
+		mu.Lock()
+		loaded = append(loaded, key)
+		mu.Unlock()
+		return key * 10, nil
+	})
+
+	c.Prefetch([]int{1, 2, 3, 3})
+	wg.Wait()
+
+	// loads still caching their result are joined, not repeated
+	for _, key := range []int{2, 3} {
+		if v, err := c.GetOrCompute(key, nil); err != nil || v != key*10 {
+			t.Errorf("key %d: %v %v", key, v, err)
+		}
+	}
+	if len(loaded) != 2 {
+		t.Errorf("loaded %v, want 2 and 3 once", loaded)
+	}
+}
+
+// TestPrefetchWorkers tests that loads are limited and stop with the context.
+func TestPrefetchWorkers(t *testing.T) {
+	c := NewSLRUCache[int, int](100, 100)
+	c.mu = new(sync.RWMutex)
+
+	release := make(chan struct{})
+	started := make(chan int, 100)
+	c.SetLoader(func(ctx context.Context, key int) (int, error) {
+		started <- key
+		<-release
+		return key, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	keys := make([]int, 50)
+	for i := range keys {
+		keys[i] = i
+	}
+	c.PrefetchCtx(ctx, keys)
+	for i := 0; i < prefetchWorkers; i++ {
+		<-started
+	}
+	cancel()
+	close(release)
+
+	// waiters of dropped loads load again themselves
+	for _, key := range keys {
+		if v, err := c.GetOrCompute(key, nil); err != nil || v != key {
+			t.Fatalf("key %d: %v %v", key, v, err)
+		}
+	}
+	if n := len(started); n != len(keys)-prefetchWorkers {
+		t.Errorf("%d loads after cancel, want only the dropped ones reloaded", n)
+	}
+}
+
+// TestPrefetchResults tests that load failures are reported and the read-through store is used.
+func TestPrefetchResults(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	c.mu = new(sync.RWMutex)
+	if got := collect(c.Prefetch([]string{"a", "a"})); len(got) != 1 || !errors.Is(got["a"].Err, ErrNoLoader) {
+		t.Errorf("without loader: %+v", got)
+	}
+
+	c.SetReadThrough(&mapStore{m: map[string]string{"a": "1"}})
+	got := collect(c.Prefetch([]string{"a", "b"}))
+	if len(got) != 2 || got["a"].Err != nil || !errors.Is(got["b"].Err, ErrNotFound) {
+		t.Errorf("results %+v", got)
+	}
+	if v := c.Lookup("a"); v == nil || *v != "1" {
+		t.Error("read-through value should be cached")
+	}
+}
+
+// collect waits for the prefetch results by key.
+func collect[K comparable](results <-chan PrefetchResult[K]) map[K]PrefetchResult[K] {
+	got := make(map[K]PrefetchResult[K])
+	for r := range results {
+		got[r.Key] = r
+	}
+	return got
+}
@@ -0,0 +1,40 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"strings"
+)
+
+// RemovePrefix removes all entries whose key starts with prefix, e.g. all
+// keys "user:123:..." with prefix "user:123:", and returns their number.
+// Each removal behaves like Remove. It scans the index once under the lock,
+// so the cost grows with the number of cached entries, not with the
+// number of removed ones.
+func RemovePrefix[V any](c *SLRUCache[string, V], prefix string) int {
+	c.mu.Lock()
+
+	var keys []string
+	var idx []int
+	c.rangeIndex(func(key string, n int) {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+			idx = append(idx, n)
+		}
+	})
+	for i, n := range idx {
+		c.removeKey(n, keys[i])
+	}
+	tier, removeCb := c.tier, c.removeCb
+
+	c.mu.Unlock()
+
+	for _, key := range keys {
+		c.record(TraceRemove, key)
+		if tier != nil {
+			tier.Delete(key)
+		}
+	}
+	c.deliverRemoved(removeCb, keys...)
+	return len(keys)
+}
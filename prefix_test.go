@@ -0,0 +1,32 @@
+package slrucache
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestRemovePrefix tests the removal of hierarchical keys.
+func TestRemovePrefix(t *testing.T) {
+	for _, open := range []bool{false, true} {
+		c := NewSLRUCache[string, int](10, 10)
+		c.SetOpenIndex(open)
+		for i := 0; i < 5; i++ {
+			c.Insert(fmt.Sprintf("user:1:%d", i), i)
+			c.Insert(fmt.Sprintf("user:2:%d", i), i)
+		}
+		c.Lookup("user:1:0")
+
+		if n := RemovePrefix(c, "user:1:"); n != 5 {
+			t.Errorf("open %v: removed %d entries, want 5", open, n)
+		}
+		if c.Lookup("user:1:0") != nil || c.Lookup("user:2:0") == nil {
+			t.Errorf("open %v: wrong entries removed", open)
+		}
+		if n := RemovePrefix(c, "none"); n != 0 {
+			t.Errorf("open %v: removed %d entries without match", open, n)
+		}
+		if err := c.Verify(); err != nil {
+			t.Error(err)
+		}
+	}
+}
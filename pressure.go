@@ -0,0 +1,210 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// PressureConfig configures a PressureController. Zero values select the
+// defaults.
+type PressureConfig struct {
+	Limit      uint64        // memory limit in bytes, default the limit set by debug.SetMemoryLimit
+	High       float64       // share of Limit above which the cache shrinks, default 0.9
+	Low        float64       // share of Limit below which the cache grows again, default 0.7
+	Step       float64       // share of the capacity to shrink or grow by per check, default 0.25
+	MinEntries int           // number of entries the cache never shrinks below
+	Interval   time.Duration // time between checks, default 1s
+}
+
+// PressureController watches the memory use of the process and limits the
+// number of entries of a cache while it is close to the memory limit,
+// evicting entries to make room. Once the memory use falls again, the
+// limit is raised step by step until the full capacity is available.
+type PressureController[K comparable, V any] struct {
+	cache *SLRUCache[K, V]
+	cfg   PressureConfig
+	usage func() uint64 // current memory use in bytes
+
+	mu    sync.Mutex
+	limit int // current entry limit, 0 for none
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// NewPressureController creates a controller for c. Call Start to watch
+// the memory use periodically or Check to check it once.
+func NewPressureController[K comparable, V any](c *SLRUCache[K, V], cfg PressureConfig) *PressureController[K, V] {
+	if cfg.Limit == 0 {
+		if l := debug.SetMemoryLimit(-1); l > 0 && l < math.MaxInt64 {
+			cfg.Limit = uint64(l)
+		}
+	}
+	if cfg.High <= 0 {
+		cfg.High = 0.9
+	}
+	if cfg.Low <= 0 {
+		cfg.Low = 0.7
+	}
+	if cfg.Step <= 0 {
+		cfg.Step = 0.25
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	return &PressureController[K, V]{cache: c, cfg: cfg, usage: memoryUsage}
+}
+
+// memoryUsage returns the memory counted against the runtime memory limit.
+func memoryUsage() uint64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return samples[0].Value.Uint64() - samples[1].Value.Uint64()
+}
+
+// Start checks the memory use every interval until Stop is called.
+func (p *PressureController[K, V]) Start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop != nil {
+		return
+	}
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	p.stop, p.done = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(p.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				p.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops watching the memory use. The current entry limit stays in
+// effect; call Reset to lift it.
+func (p *PressureController[K, V]) Stop() {
+	p.mu.Lock()
+	stop, done := p.stop, p.done
+	p.stop, p.done = nil, nil
+	p.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// Check compares the memory use with the thresholds once and shrinks or
+// grows the entry limit of the cache by a step. It returns the entry
+// limit, 0 if the full capacity is available. Without memory limit the
+// controller does nothing.
+func (p *PressureController[K, V]) Check() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cfg.Limit == 0 {
+		return p.limit
+	}
+
+	capacity := p.cache.cnum
+	step := max(int(float64(capacity)*p.cfg.Step), 1)
+	used := float64(p.usage()) / float64(p.cfg.Limit)
+	switch {
+	case used > p.cfg.High:
+		current := p.limit
+		if current == 0 {
+			current = capacity
+		}
+		p.limit = max(current-step, p.cfg.MinEntries, 1)
+	case used < p.cfg.Low && p.limit > 0:
+		p.limit += step
+		if p.limit >= capacity {
+			p.limit = 0
+		}
+	default:
+		return p.limit
+	}
+
+	p.cache.setEntryLimit(p.limit)
+	return p.limit
+}
+
+// Reset lifts the entry limit.
+func (p *PressureController[K, V]) Reset() {
+	p.mu.Lock()
+	p.limit = 0
+	p.cache.setEntryLimit(0)
+	p.mu.Unlock()
+}
+
+// setEntryLimit limits the number of entries, evicting the ones above the
+// limit. 0 lifts the limit.
+func (c *SLRUCache[K, V]) setEntryLimit(n int) {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+
+	c.entryLimit = n
+	c.enforceEntryLimit()
+
+	hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+}
+
+// enforceEntryLimit evicts victims until the number of entries is within
+// the entry limit and the hard limit, see SetLimits.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) enforceEntryLimit() {
+	limit := c.limit()
+	for limit > 0 && c.indexLen() > limit {
+		if !c.evictForLimit() {
+			// The rest is pinned
+			break
+		}
+	}
+	c.overSoft.Store(c.softLimit > 0 && c.indexLen() > c.softLimit)
+}
+
+// limit returns the lower of the entry limit and the hard limit, 0 if
+// neither is set. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) limit() int {
+	limit := c.entryLimit
+	if c.hardLimit > 0 && (limit <= 0 || c.hardLimit < limit) {
+		limit = c.hardLimit
+	}
+	return limit
+}
+
+// evictForLimit evicts one victim to enforce a limit. Probationary entries
+// go first, except the last one, which is usually the entry just inserted.
+// Returns false if all entries are pinned. Must be called with the mutex
+// held.
+func (c *SLRUCache[K, V]) evictForLimit() bool {
+	lists := []*SLRUList[K, V]{c.probelist, c.lrulist}
+	if c.probelist.count <= 1 {
+		lists[0], lists[1] = lists[1], lists[0]
+	}
+	for _, l := range lists {
+		if n := c.victim(l); n != SLRU_EOF {
+			c.evictEntry(l, n, "Limit")
+			c.freelist.insertHead(n)
+			return true
+		}
+	}
+	return false
+}
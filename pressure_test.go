@@ -0,0 +1,63 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestPressureController tests shrinking under memory pressure and growing
+// back once it subsides.
+func TestPressureController(t *testing.T) {
+	c := NewSLRUCache[int, int](4, 4)
+	for i := 0; i < 8; i++ {
+		c.Insert(i, i)
+		c.Lookup(i)
+	}
+	insertInts(c, 8, 12)
+
+	var used uint64
+	p := NewPressureController(c, PressureConfig{Limit: 100, MinEntries: 3})
+	p.usage = func() uint64 { return used }
+
+	used = 95
+	if l := p.Check(); l != 6 || c.indexLen() != 6 {
+		t.Errorf("limit %d with %d entries, want 6", l, c.indexLen())
+	}
+	if l := p.Check(); l != 4 {
+		t.Errorf("limit %d, want 4", l)
+	}
+	if l := p.Check(); l != 3 {
+		t.Errorf("limit %d, want the minimum of 3", l)
+	}
+
+	// Inserts keep within the limit and admit new entries
+	c.Insert(100, 100)
+	if c.indexLen() != 3 || c.Lookup(100) == nil {
+		t.Errorf("%d entries after insert, want 3 including the new one", c.indexLen())
+	}
+
+	used = 80
+	if l := p.Check(); l != 3 {
+		t.Errorf("limit %d between the thresholds, want 3", l)
+	}
+	used = 50
+	p.Check()
+	if l := p.Check(); l != 7 {
+		t.Errorf("limit %d, want 7", l)
+	}
+	if l := p.Check(); l != 0 {
+		t.Errorf("limit %d, want the full capacity", l)
+	}
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+
+	p.Start()
+	p.Stop()
+}
+
+// insertInts inserts the keys from to to-1.
+func insertInts(c *SLRUCache[int, int], from, to int) {
+	for i := from; i < to; i++ {
+		c.Insert(i, i)
+	}
+}
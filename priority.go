@@ -0,0 +1,51 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Priority classifies entries for eviction. Within a segment, entries of
+// a lower priority are evicted before entries of a higher priority.
+type Priority int8
+
+// Priority classes, PriorityNormal is used by Insert.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// priorityLevels is the number of priority classes.
+const priorityLevels = 3
+
+// level maps the priority to an index in [0, priorityLevels).
+// Out of range priorities are clamped to the nearest class.
+func (p Priority) level() int {
+	switch {
+	case p < PriorityLow:
+		return 0
+	case p > PriorityHigh:
+		return priorityLevels - 1
+	}
+	return int(p - PriorityLow)
+}
+
+// String returns the name of the priority class.
+func (p Priority) String() string {
+	switch p.level() {
+	case 0:
+		return "low"
+	case 2:
+		return "high"
+	}
+	return "normal"
+}
+
+// InsertWithPriority adds or updates a key-value pair like Insert and sets
+// the priority of the entry. Updating an existing key changes its priority.
+func (c *SLRUCache[K, V]) InsertWithPriority(key K, value V, prio Priority) {
+	c.insert(key, value, entryOptions{prio: clampPriority(prio), setPrio: true})
+}
+
+// clampPriority limits p to the defined priority classes.
+func clampPriority(p Priority) Priority {
+	return Priority(p.level()) + PriorityLow
+}
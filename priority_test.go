@@ -0,0 +1,55 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestPriorityProbe tests that low priority entries are evicted first.
+func TestPriorityProbe(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	c.InsertWithPriority("high", "high", PriorityHigh)
+	c.Insert("normal", "normal")
+	c.InsertWithPriority("low", "low", PriorityLow)
+
+	c.Insert("a", "a")
+	if _, ok := c.mapping["low"]; ok {
+		t.Error("low priority entry should be evicted first")
+	}
+	c.Insert("b", "b")
+	if _, ok := c.mapping["normal"]; ok {
+		t.Error("normal priority entry should be evicted before high")
+	}
+	c.Insert("c", "c")
+	if _, ok := c.mapping["high"]; !ok {
+		t.Error("high priority entry should be kept while normal ones exist")
+	}
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestPriorityProtected tests priorities in the protected segment and updates.
+func TestPriorityProtected(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	c.InsertWithPriority("0", "0", PriorityHigh)
+	c.Insert("1", "1")
+	lookupN(c, 2, 0)
+
+	// "0" is the LRU entry but has high priority
+	c.Insert("2", "2")
+	c.Lookup("2")
+	if c.Lookup("0") == nil || c.Lookup("1") != nil {
+		t.Error("high priority protected entry should survive")
+	}
+
+	// lowering the priority makes it the preferred victim again
+	c.InsertWithPriority("0", "0", PriorityLow)
+	c.Insert("3", "3")
+	c.Lookup("3")
+	if c.Lookup("0") != nil || checkSLRUCacheSanity(c) {
+		t.Error("low priority protected entry should be evicted")
+	}
+	if c.lrulist.prios != [priorityLevels]int{0, 2, 0} {
+		t.Errorf("unexpected priority counts %v", c.lrulist.prios)
+	}
+}
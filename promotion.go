@@ -0,0 +1,117 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Promotion is the decision of a PromotionPolicy on a probation hit.
+type Promotion int
+
+// Promotion decisions.
+const (
+	// Promote moves the entry to the lrulist.
+	Promote Promotion = iota
+	// KeepProbation keeps the entry in the probelist, reordered by the
+	// probation policy as for any probation hit.
+	KeepProbation
+	// Ignore keeps the entry in the probelist without reordering.
+	Ignore
+)
+
+// PromotionPolicy decides what a hit on an entry in the probelist does,
+// for domain specific heuristics like promoting only values above a size.
+// hits is the number of hits of the entry including this one. The policy
+// is consulted after the promotion threshold, see SetPromotionThreshold,
+// is reached, with the cache mutex held; it must not call the cache.
+type PromotionPolicy[K comparable, V any] interface {
+	Promote(key K, value V, hits uint64) Promotion
+}
+
+// PromotionFunc adapts a function to the PromotionPolicy interface.
+type PromotionFunc[K comparable, V any] func(key K, value V, hits uint64) Promotion
+
+// Promote calls f.
+func (f PromotionFunc[K, V]) Promote(key K, value V, hits uint64) Promotion {
+	return f(key, value, hits)
+}
+
+// SetPromotionPolicy sets the policy deciding on probation hits. Pass nil
+// to promote on every probation hit reaching the promotion threshold.
+func (c *SLRUCache[K, V]) SetPromotionPolicy(p PromotionPolicy[K, V]) {
+	c.mu.Lock()
+	c.promotion = p
+	c.mu.Unlock()
+}
+
+// promotionOf returns the decision on a probation hit on the entry at
+// index n. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) promotionOf(n int) Promotion {
+	e := &c.entries[n]
+	if e.accesses < uint64(c.promoteHits) {
+		return KeepProbation
+	}
+	if c.promotion == nil {
+		return Promote
+	}
+	return c.promotion.Promote(e.key, e.value, e.accesses)
+}
+
+// Promote moves the entry for key from the probelist to the lrulist like a
+// promoting hit, but without counting a hit, for callers knowing out of
+// band that the key is hot. Returns false if the key is not cached in the
+// probelist or the lrulist is pinned completely.
+func (c *SLRUCache[K, V]) Promote(key K) bool {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+
+	n, ok := c.find(key)
+	if !ok || c.expired(n) || c.entries[n].tag != tagProbation {
+		c.mu.Unlock()
+		return false
+	}
+	removedKey, removal, ok := c.promote(n, "Promote")
+	hooks, recycle, evicted, removeCb := c.hooks, c.recycle, c.takeEvicted(), c.removeCb
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+	if !ok {
+		return false
+	}
+	if removal {
+		c.deliverRemoved(removeCb, removedKey)
+	}
+	if c.insertCb != nil {
+		c.insertCb(key)
+	}
+	return true
+}
+
+// Demote moves the entry for key from the lrulist back to the head of the
+// probelist, giving it a second chance like a demoted protected victim.
+// Returns false if the key is not cached in the lrulist, the cache has no
+// probelist or the probelist is full and pinned completely.
+func (c *SLRUCache[K, V]) Demote(key K) bool {
+	return c.demoteKey(key, false)
+}
+
+// DemoteTail is Demote moving the entry to the tail of the probelist, so
+// it is evicted next, for keys known to go cold.
+func (c *SLRUCache[K, V]) DemoteTail(key K) bool {
+	return c.demoteKey(key, true)
+}
+
+// demoteKey implements Demote and DemoteTail.
+func (c *SLRUCache[K, V]) demoteKey(key K, tail bool) bool {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+	c.drainReads()
+
+	n, ok := c.find(key)
+	if !ok || c.expired(n) || c.entries[n].tag != tagProtected || c.pnum == 0 || !c.demoteEntry(n, tail, "Demote") {
+		c.mu.Unlock()
+		return false
+	}
+	hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+	return true
+}
@@ -0,0 +1,97 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestPromotionPolicy tests the decisions of a promotion policy.
+func TestPromotionPolicy(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 3)
+	c.SetPromotionPolicy(PromotionFunc[string, string](func(key, value string, hits uint64) Promotion {
+		switch key {
+		case "keep":
+			return KeepProbation
+		case "ignore":
+			return Ignore
+		}
+		return Promote
+	}))
+	c.Insert("keep", "")
+	c.Insert("ignore", "")
+	c.Insert("hot", "")
+
+	// keep is moved to the head, ignore stays at the tail
+	c.Lookup("keep")
+	c.Lookup("ignore")
+	c.Lookup("hot")
+	if checkListCount(c, 2, 1, 2, "promotion policy") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if k := c.entries[c.probelist.Tail()].key; k != "ignore" {
+		t.Errorf("probelist tail %q", k)
+	}
+	if s := c.Stats(); s.ProbationHits != 3 || s.Promotions != 1 {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+// TestPromote tests manual promotion without a hit.
+func TestPromote(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 2)
+	insertN(c, 2, 0)
+	if !c.Promote("0") || c.Promote("0") || c.Promote("missing") {
+		t.Error("only probationary entries should be promoted")
+	}
+	if !c.Promote("1") || c.SegmentOf("0") != SegmentNone || checkSLRUCacheSanity(c) {
+		t.Error("promotion into a full lrulist should evict its victim")
+	}
+	if s := c.Stats(); s.Hits != 0 || s.Promotions != 2 {
+		t.Errorf("stats %+v", s)
+	}
+
+	c.Insert("2", "2")
+	c.Pin("1")
+	if c.Promote("2") || c.SegmentOf("2") != SegmentProbation {
+		t.Error("promotion into a pinned lrulist should fail")
+	}
+}
+
+// TestDemote tests manual demotion to the head and tail of the probelist.
+func TestDemote(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	insertN(c, 3, 0)
+	lookupN(c, 2, 0)
+
+	if !c.Demote("0") || c.Demote("0") || c.Demote("2") || c.Demote("missing") {
+		t.Error("only protected entries should be demoted")
+	}
+	if k := c.entries[c.probelist.head].key; k != "0" {
+		t.Errorf("probelist head %q", k)
+	}
+	if !c.DemoteTail("1") || c.entries[c.probelist.Tail()].key != "1" {
+		t.Error("DemoteTail should make the entry the next victim")
+	}
+	if checkListCount(c, 3, 0, 3, "demote") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if s := c.Stats(); s.Demotions != 2 {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+// TestDemotePinnedProbation tests that demotion into a full, pinned probelist fails.
+func TestDemotePinnedProbation(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 2)
+	insertN(c, 1, 0)
+	lookupN(c, 1, 0)
+	insertN(c, 2, 1)
+	c.Pin("1")
+	c.Pin("2")
+
+	if c.Demote("0") || c.DemoteTail("0") || c.entries[c.lrulist.head].key != "0" {
+		t.Error("demotion should fail and keep the entry")
+	}
+	if problems := c.verify(); len(problems) > 0 {
+		t.Errorf("problems %v", problems)
+	}
+}
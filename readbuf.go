@@ -0,0 +1,208 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// readStripes is the number of read buffer stripes. Hits are spread over
+// the stripes by a per thread random number, approximating per-P buffers
+// without pinning goroutines.
+const readStripes = 16
+
+// DefaultReadBuffer is the read buffer size set by SetConcurrentReads.
+const DefaultReadBuffer = 1024
+
+// readRecord is a protected hit waiting to be applied to the lrulist.
+type readRecord[K comparable] struct {
+	n   int       // entry index
+	key K         // key at the time of the hit, detects reused entries
+	at  time.Time // time of the hit
+}
+
+// readStripe is one stripe of a readBuffer.
+type readStripe[K comparable] struct {
+	mu   sync.Mutex
+	recs []readRecord[K]
+	_    [32]byte // keep stripes on separate cache lines
+}
+
+// readBuffer collects protected hits made under the read lock. It is lossy:
+// records arriving at a full stripe are dropped, like in Caffeine, since a
+// missed reordering only makes the LRU order slightly less exact.
+type readBuffer[K comparable] struct {
+	stripes    [readStripes]readStripe[K]
+	stripeSize int
+	batch      []readRecord[K] // reused by drainReads
+}
+
+// newReadBuffer creates a readBuffer holding about size records.
+func newReadBuffer[K comparable](size int) *readBuffer[K] {
+	return &readBuffer[K]{stripeSize: max(1, (size+readStripes-1)/readStripes)}
+}
+
+// record adds a hit to a random stripe.
+// Returns false if the stripe is full and the hit was dropped.
+func (b *readBuffer[K]) record(rec readRecord[K]) bool {
+	s := &b.stripes[rand.N(readStripes)]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.recs) >= b.stripeSize {
+		return false
+	}
+	if s.recs == nil {
+		s.recs = make([]readRecord[K], 0, b.stripeSize)
+	}
+	s.recs = append(s.recs, rec)
+	return true
+}
+
+// SetReadBuffer sets up a read buffer of about size records. Hits on
+// protected entries are then recorded in the buffer instead of reordering
+// the lrulist right away; the reorderings are applied in batches when the
+// buffer fills, on the next insert and before the lrulist is evicted from
+// or inspected, following the Caffeine design. 0 disables the buffer and
+// the concurrent read path.
+func (c *SLRUCache[K, V]) SetReadBuffer(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.drainReads()
+	if size > 0 {
+		c.reads = newReadBuffer[K](size)
+	} else {
+		c.reads = nil
+		c.concurrent.Store(false)
+	}
+}
+
+// SetConcurrentReads enables the concurrent read path. Hits on protected
+// entries then only take the shared read lock and record the access in
+// the read buffer, which is set up with DefaultReadBuffer records if
+// SetReadBuffer was not called. Read dominated workloads no longer
+// serialize on promotion bookkeeping. The LRU order becomes approximate
+// because hits arriving at a full buffer stripe may be dropped.
+func (c *SLRUCache[K, V]) SetConcurrentReads(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if enabled && c.reads == nil {
+		c.reads = newReadBuffer[K](DefaultReadBuffer)
+	}
+	c.concurrent.Store(enabled)
+}
+
+// lookupShared serves a protected hit under the read lock. It returns
+// false if the lookup needs the exclusive lock: misses, probation hits,
+// expired and negative entries, and lookups pinning the entry.
+func (c *SLRUCache[K, V]) lookupShared(key K, out *lookupOut[V]) (*V, bool) {
+	if out != nil && out.pin {
+		return nil, false
+	}
+	c.mu.RLock()
+	if c.reads == nil || c.idleTTL > 0 {
+		c.mu.RUnlock()
+		return nil, false
+	}
+
+	n, ok := c.find(key)
+	if !ok {
+		c.mu.RUnlock()
+		return nil, false
+	}
+	e := &c.entries[n]
+	if e.tag != tagProtected || e.negative || c.expired(n) {
+		c.mu.RUnlock()
+		return nil, false
+	}
+
+	atomic.AddUint64(&c.stats.Hits, 1)
+	atomic.AddUint64(&c.stats.ProtectedHits, 1)
+	if w := c.window.Load(); w != nil {
+		w.record(c.now(), true)
+	}
+	value := c.result(n, out)
+	if out != nil {
+		out.segment = SegmentProtected
+	}
+	recorded := c.reads.record(readRecord[K]{n: n, key: key, at: c.now()})
+	hooks, webhook := c.hooks, c.webhook
+	c.mu.RUnlock()
+
+	if !recorded && c.mu.TryLock() {
+		// Buffer stripe full, apply the buffered hits if nobody else works
+		c.drainReads()
+		c.mu.Unlock()
+	}
+	if webhook != nil {
+		webhook.lookup(true)
+	}
+	if hooks != nil {
+		hooks.OnHit(key)
+	}
+	return value, true
+}
+
+// protectedHit records a hit on the protected entry at index n in the read
+// buffer, or applies it right away if there is no buffer or it is full.
+// Must be called with the mutex held exclusively.
+func (c *SLRUCache[K, V]) protectedHit(n int, key K) {
+	now := c.now()
+	e := &c.entries[n]
+	if c.reads != nil {
+		if c.reads.record(readRecord[K]{n: n, key: key, at: now}) {
+			if c.idleTTL > 0 {
+				// The idle deadline must not wait for the buffer to drain
+				e.accessed = now
+			}
+			return
+		}
+		c.drainReads()
+	}
+
+	e.accessed = now
+	e.accesses++
+	c.policy.Hit(c.lrulist, n)
+}
+
+// drainReads applies the buffered protected hits to the lrulist in the
+// order they were made. Must be called with the mutex held exclusively.
+func (c *SLRUCache[K, V]) drainReads() {
+	if c.reads == nil {
+		return
+	}
+
+	batch := c.reads.batch[:0]
+	for i := range c.reads.stripes {
+		s := &c.reads.stripes[i]
+		s.mu.Lock()
+		batch = append(batch, s.recs...)
+		s.recs = s.recs[:0]
+		s.mu.Unlock()
+	}
+	sort.SliceStable(batch, func(i, j int) bool {
+		return batch[i].at.Before(batch[j].at)
+	})
+
+	for _, rec := range batch {
+		e := &c.entries[rec.n]
+		if e.tag != tagProtected || e.key != rec.key {
+			// Entry left the lrulist or was reused meanwhile
+			continue
+		}
+		if rec.at.After(e.accessed) {
+			e.accessed = rec.at
+		}
+		e.accesses++
+		c.policy.Hit(c.lrulist, rec.n)
+	}
+
+	clear(batch)
+	c.reads.batch = batch[:0]
+}
@@ -0,0 +1,97 @@
+package slrucache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentReads tests that buffered protected hits reorder the lrulist.
+func TestConcurrentReads(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetConcurrentReads(true)
+	insertN(c, 3, 0)
+	lookupN(c, 3, 0) // lrulist: 2 1 0
+
+	// the protected hit on 0 is buffered, the lrulist is unchanged
+	if v, ok := c.Get("0"); !ok || v != "0" {
+		t.Fatal("protected hit should be served")
+	}
+	if k := listKeys(c, c.lrulist); !equalKeys(k, []string{"2", "1", "0"}) {
+		t.Errorf("hit should be buffered, got %v", k)
+	}
+
+	// the next promotion applies the hit before picking the victim
+	insertN(c, 1, 3)
+	lookupN(c, 1, 3)
+	if _, ok := c.mapping["1"]; ok {
+		t.Error("1 should be the victim after the buffered hit on 0")
+	}
+	if k := listKeys(c, c.lrulist); !equalKeys(k, []string{"3", "0", "2"}) {
+		t.Errorf("unexpected lrulist order %v", k)
+	}
+	if s := c.Stats(); s.ProtectedHits != 1 || s.Hits != 5 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if info, _ := c.EntryInfo("0"); info.Accesses != 2 {
+		t.Errorf("buffered hit should count as access, got %d", info.Accesses)
+	}
+}
+
+// TestConcurrentReadsParallel runs parallel lookups and inserts, meant for -race.
+func TestConcurrentReadsParallel(t *testing.T) {
+	c := NewSLRUCache[string, string](50, 50)
+	c.SetConcurrentReads(true)
+	insertN(c, 50, 0)
+	lookupN(c, 50, 0)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				k := strconv.Itoa((i * (g + 1)) % 80)
+				if _, ok := c.Get(k); !ok && g%2 == 0 {
+					c.Insert(k, k)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	c.SetConcurrentReads(false)
+	if checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestReadBuffer tests that protected hits are applied in batches.
+func TestReadBuffer(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetReadBuffer(DefaultReadBuffer)
+	clock := newTestClock(c)
+	insertN(c, 3, 0)
+	lookupN(c, 3, 0) // lrulist: 2 1 0
+
+	c.Lookup("0")
+	clock.advance(time.Millisecond)
+	c.Lookup("1")
+	if k := listKeys(c, c.lrulist); !equalKeys(k, []string{"2", "1", "0"}) {
+		t.Errorf("hits should be buffered, got %v", k)
+	}
+
+	// the next write applies the batch
+	c.Insert("x", "x")
+	if k := listKeys(c, c.lrulist); !equalKeys(k, []string{"1", "0", "2"}) {
+		t.Errorf("hits should be applied on insert, got %v", k)
+	}
+
+	// without buffer hits reorder right away
+	c.SetReadBuffer(0)
+	c.Lookup("2")
+	if k := listKeys(c, c.lrulist); !equalKeys(k, []string{"2", "1", "0"}) || checkSLRUCacheSanity(c) {
+		t.Errorf("unbuffered hit should reorder, got %v", k)
+	}
+}
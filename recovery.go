@@ -0,0 +1,100 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// corruptionPanic aborts an operation after the cache was rebuilt in recovery mode.
+type corruptionPanic string
+
+// fatalPanic reports an internal inconsistency outside recovery mode.
+// It is raised with the mutex held.
+type fatalPanic string
+
+// Error returns the message of the panic.
+func (p fatalPanic) Error() string {
+	return string(p)
+}
+
+// SetRecovery enables the self-healing recovery mode. Instead of panicking
+// on an internal inconsistency, the cache rebuilds its lists and mapping
+// from the entries array, increments Stats.Corruptions and continues
+// serving. The operation that detected the inconsistency is abandoned:
+// a Lookup reports a miss, an Insert is dropped.
+func (c *SLRUCache[K, V]) SetRecovery(enabled bool) {
+	c.mu.Lock()
+	c.recovery = enabled
+	c.mu.Unlock()
+}
+
+// recoverCorruption is deferred by operations that may call doPanic with
+// the mutex held. It stops a corruptionPanic and releases the mutex if
+// unlock is set; other panics are passed on.
+func (c *SLRUCache[K, V]) recoverCorruption(unlock bool) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if _, ok := r.(corruptionPanic); !ok {
+		panic(r)
+	}
+	if unlock {
+		c.mu.Unlock()
+	}
+}
+
+// rebuild reconstructs lists and mapping from the entries array.
+// An entry is kept if it claims to be in the probelist or lrulist and the
+// mapping points to it; everything else is freed. Recency order is lost,
+// entries beyond a segment's capacity are dropped.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) rebuild() {
+	var zeroK K
+	var zeroV V
+
+	var kept, freed []int
+	c.freelist = NewSLRUList(&c.entries, tagFree)
+	probelist := NewSLRUList(&c.entries, tagProbation)
+	lrulist := NewSLRUList(&c.entries, tagProtected)
+	probelist.sizer, lrulist.sizer = c.probelist.sizer, c.lrulist.sizer
+
+	for n := range c.entries {
+		e := &c.entries[n]
+		var l *SLRUList[K, V]
+		switch e.tag {
+		case tagProbation:
+			l = probelist
+		case tagProtected:
+			l = lrulist
+		}
+
+		// The index maps each key to one entry, so no key is kept twice
+		if m, ok := c.find(e.key); ok && m == n && l != nil {
+			limit := c.pnum
+			if l == lrulist {
+				limit = c.snum
+			}
+			if l.count < limit {
+				kept = append(kept, n)
+				l.insertHead(n)
+				continue
+			}
+		}
+
+		freed = append(freed, n)
+	}
+
+	// Clear entries only now, the index still reads their keys above
+	for _, n := range freed {
+		c.entries[n] = SLRUCacheEntry[K, V]{key: zeroK, value: zeroV}
+		delete(c.entryTTLs, n)
+		delete(c.leases, n)
+		c.freelist.insertHead(n)
+	}
+
+	c.probelist = probelist
+	c.lrulist = lrulist
+	c.resetIndex()
+	for _, n := range kept {
+		c.setIndex(c.entries[n].key, n)
+	}
+	c.evicted, c.recycled, c.spilled = nil, nil, nil
+}
@@ -0,0 +1,49 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestRecovery tests that an inconsistency is repaired instead of panicking.
+func TestRecovery(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.SetRecovery(true)
+	insertN(c, 4, 0)
+	lookupN(c, 2, 0)
+
+	// lose the freelist
+	c.freelist = NewSLRUList(&c.entries, tagFree)
+
+	c.Insert("x", "x")
+	if c.Stats().Corruptions != 1 {
+		t.Fatal("corruption should be counted")
+	}
+	if checkListCount(c, 6, 2, 2, "rebuilt cache") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	for _, k := range []string{"0", "1", "2", "3"} {
+		if c.Lookup(k) == nil {
+			t.Errorf("entry %s should survive the rebuild", k)
+		}
+	}
+
+	// the cache keeps serving
+	c.Insert("x", "x")
+	if v := c.Lookup("x"); v == nil || *v != "x" || checkSLRUCacheSanity(c) {
+		t.Error("insert after rebuild failed")
+	}
+}
+
+// TestNoRecovery tests that inconsistencies still panic by default.
+func TestNoRecovery(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.freelist = NewSLRUList(&c.entries, tagFree)
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+		// the panic leaves the mutex held
+		c.mu.Unlock()
+	}()
+	c.Insert("x", "x")
+}
@@ -0,0 +1,18 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// SetRecycler sets a function receiving the values of evicted entries, for
+// example to put byte slices back into a sync.Pool so allocation heavy
+// workloads reuse them instead of churning the GC. It is called after the
+// cache has been unlocked and after Hooks.OnEvict, once the cache holds no
+// reference to the value anymore: with a victim buffer, see
+// SetVictimBuffer, an evicted value is recycled only when it drops out of
+// the buffer. Values of removed, replaced or expired entries, values the
+// victim buffer drops for removed or invalidated keys and the value
+// returned by RemoveOldest are not recycled. Pass nil to disable recycling.
+func (c *SLRUCache[K, V]) SetRecycler(recycle func(V)) {
+	c.mu.Lock()
+	c.recycle = recycle
+	c.mu.Unlock()
+}
@@ -0,0 +1,60 @@
+package slrucache
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestRecycler tests that evicted values are handed to the recycler.
+func TestRecycler(t *testing.T) {
+	pool := sync.Pool{New: func() any { return new([]byte) }}
+	var recycled []string
+
+	c := NewSLRUCache[string, []byte](1, 1)
+	c.SetRecycler(func(b []byte) {
+		recycled = append(recycled, string(b))
+		b = b[:0]
+		pool.Put(&b)
+	})
+
+	c.Insert("a", []byte("a"))
+	c.Lookup("a")
+	c.Insert("b", []byte("b"))
+	c.Lookup("b") // evicts a from lrulist
+	c.Insert("c", []byte("c"))
+	c.Insert("d", []byte("d")) // evicts c from probelist
+	c.Remove("d")
+
+	if !equalKeys(recycled, []string{"a", "c"}) {
+		t.Errorf("unexpected recycled values %v", recycled)
+	}
+	if _, v, _ := c.RemoveOldest(); string(v) != "b" || len(recycled) != 2 {
+		t.Error("RemoveOldest should not recycle the returned value")
+	}
+}
+
+// TestRecyclerVictimBuffer tests that values are recycled only when they leave the victim buffer.
+func TestRecyclerVictimBuffer(t *testing.T) {
+	var recycled []string
+	c := NewSLRUCache[string, []byte](1, 1)
+	c.SetVictimBuffer(1)
+	c.SetRecycler(func(b []byte) {
+		recycled = append(recycled, string(b))
+		copy(b, "XXXX")
+	})
+
+	c.Insert("a", []byte("a"))
+	c.Insert("b", []byte("b")) // evicts a into the buffer
+	if len(recycled) != 0 {
+		t.Fatalf("buffered value should not be recycled: %v", recycled)
+	}
+	if v := c.Lookup("a"); v == nil || string(*v) != "a" {
+		t.Fatal("readmitted value should be intact")
+	}
+
+	c.Insert("c", []byte("c")) // evicts b into the buffer
+	c.Insert("d", []byte("d")) // evicts c, b drops out of the buffer
+	if !equalKeys(recycled, []string{"b"}) {
+		t.Errorf("unexpected recycled values %v", recycled)
+	}
+}
@@ -0,0 +1,49 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Rename moves the entry of oldKey to newKey, keeping its value, segment,
+// recency position and settings, e.g. when a temporary ID is replaced by
+// a persistent one. An entry cached for newKey is removed. Returns false
+// if oldKey is not cached.
+func (c *SLRUCache[K, V]) Rename(oldKey, newKey K) bool {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+	c.drainReads()
+
+	n, ok := c.find(oldKey)
+	if !ok || c.expired(n) {
+		c.mu.Unlock()
+		return false
+	}
+	if oldKey == newKey {
+		c.mu.Unlock()
+		return true
+	}
+
+	replaced := false
+	if m, ok := c.find(newKey); ok {
+		c.removeKey(m, newKey)
+		replaced = true
+	}
+	c.deleteIndex(oldKey)
+	c.entries[n].key = newKey
+	c.setIndex(newKey, n)
+	if c.mlog != nil {
+		c.logMutation(logRemove, n, oldKey)
+		c.logMutation(logOp(c.entries[n].negative), n, newKey)
+	}
+	tier, removeCb := c.tier, c.removeCb
+	c.mu.Unlock()
+
+	if tier != nil {
+		tier.Delete(oldKey)
+		if replaced {
+			tier.Delete(newKey)
+		}
+	}
+	if replaced {
+		c.deliverRemoved(removeCb, newKey)
+	}
+	return true
+}
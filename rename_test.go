@@ -0,0 +1,34 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestRename tests that a renamed entry keeps its value and position.
+func TestRename(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 3)
+	insertN(c, 3, 0)
+	lookupN(c, 2, 0)
+
+	if !c.Rename("0", "new") || c.Rename("missing", "x") {
+		t.Fatal("rename should report cached keys only")
+	}
+	if _, ok := c.find("0"); ok {
+		t.Error("old key should be gone")
+	}
+	info, ok := c.EntryInfo("new")
+	if !ok || info.Segment != SegmentProtected || info.Accesses != 1 {
+		t.Errorf("entry info %+v", info)
+	}
+	if e := c.entries[c.lrulist.Tail()]; e.key != "new" || e.value != "0" {
+		t.Errorf("lrulist tail %q, value and recency should be kept", e.key)
+	}
+
+	// renaming onto a cached key replaces it
+	if !c.Rename("new", "1") || c.indexLen() != 2 || checkSLRUCacheSanity(c) {
+		t.Error("rename should replace the entry of the new key")
+	}
+	if n, ok := c.find("1"); !ok || c.entries[n].value != "0" {
+		t.Error("renamed value should be kept")
+	}
+}
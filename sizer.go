@@ -0,0 +1,52 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Sizer is implemented by values reporting their size in bytes. If the
+// value type of a cache implements it, the cache tracks the total size of
+// the values per segment, see Stats.
+type Sizer interface {
+	Size() int
+}
+
+// valueSizer returns the size function for values of type V if V
+// implements Sizer, nil otherwise.
+func valueSizer[V any]() func(V) int {
+	var zero V
+	if _, ok := any(zero).(Sizer); !ok {
+		return nil
+	}
+	return func(v V) int {
+		if s, ok := any(v).(Sizer); ok {
+			return s.Size()
+		}
+		return 0
+	}
+}
+
+// SetSizer sets the function reporting the size of values in bytes, which
+// replaces the Size method of values implementing Sizer. The total size of
+// the values per segment is reported in Stats. Values must not change their
+// size while cached. Pass nil to stop tracking sizes.
+func (c *SLRUCache[K, V]) SetSizer(size func(V) int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, l := range []*SLRUList[K, V]{c.probelist, c.lrulist} {
+		l.sizer, l.bytes = size, 0
+		if size == nil {
+			continue
+		}
+		for n := l.head; n >= 0; n = int(c.entries[n].next) {
+			l.bytes += int64(size(c.entries[n].value))
+		}
+	}
+}
+
+// Bytes returns the total size of the cached values as reported by the
+// sizer, 0 if sizes are not tracked.
+func (c *SLRUCache[K, V]) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.probelist.bytes + c.lrulist.bytes
+}
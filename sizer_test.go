@@ -0,0 +1,49 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+type sizedValue string
+
+func (v sizedValue) Size() int { return len(v) }
+
+// TestSizer tests the size accounting of values implementing Sizer.
+func TestSizer(t *testing.T) {
+	c := NewSLRUCache[string, sizedValue](2, 2)
+	c.Insert("a", "1234")
+	c.Insert("b", "12")
+	c.Lookup("a")
+	if s := c.Stats(); s.ProtectedBytes != 4 || s.ProbationBytes != 2 {
+		t.Errorf("sizes %d/%d, want 4/2", s.ProtectedBytes, s.ProbationBytes)
+	}
+
+	c.Insert("a", "1")
+	c.Insert("c", "123")
+	c.Insert("d", "1234")
+	c.Remove("d")
+	if b := c.Bytes(); b != 1+3 {
+		t.Errorf("Bytes() = %d, want 4", b)
+	}
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestSetSizer tests a sizer function for values without Size method.
+func TestSetSizer(t *testing.T) {
+	c := NewSLRUCache[int, []byte](2, 2)
+	c.Insert(1, make([]byte, 100))
+	if c.Bytes() != 0 {
+		t.Error("sizes should not be tracked without sizer")
+	}
+	c.SetSizer(func(v []byte) int { return len(v) })
+	c.Insert(2, make([]byte, 50))
+	if c.Bytes() != 150 {
+		t.Errorf("Bytes() = %d, want 150", c.Bytes())
+	}
+	c.SetSizer(nil)
+	if c.Bytes() != 0 {
+		t.Error("sizes should be reset")
+	}
+}
@@ -3,41 +3,77 @@
 package slrucache
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// mutex is the lock shared by all caches unless they are created with
+// their own lock, like the stripes of a StripedCache.
 var (
-	mutex sync.Mutex
+	mutex sync.RWMutex
 )
 
 // SLRU_EOF is a special marker for the end of the list.
 const SLRU_EOF = -3
 
+// Tags of the lists of an SLRUCache.
+const (
+	tagFree      uint8 = iota + 1 // freelist
+	tagProbation                  // probelist
+	tagProtected                  // lrulist
+)
+
+// MaxEntries is the maximum total size of a cache. Entries link each
+// other by int32 indices to keep them small.
+const MaxEntries = math.MaxInt32
+
 // SLRUCacheEntry represents an element in the cache linked list.
 // It stores the key, value, and pointers to previous and next entries by index.
 // Key and Value are generic types.
 type SLRUCacheEntry[K comparable, V any] struct {
-	key   K
-	value V
-	prev  int             // index of previous entry (>=0 if set)
-	next  int             // index of next entry (>=0 if set)
-	list  *SLRUList[K, V] // pointer to the list this entry belongs to
+	key      K
+	value    V
+	prev     int32    // index of previous entry (>=0 if set)
+	next     int32    // index of next entry (>=0 if set)
+	pins     int32    // number of outstanding pins, pinned entries are not evicted
+	weight   int32    // weight of the entry, see WithWeight
+	prio     Priority // eviction priority, lower priorities are evicted first
+	tag      uint8    // tag of the list this entry belongs to, 0 if none
+	negative bool     // entry caches a "not found" result
+	epoch    uint32   // cache epoch the entry was written in, see InvalidateAll
+
+	expires  time.Time // expiration time, zero if the entry does not expire
+	inserted time.Time // time the key was inserted
+	accessed time.Time // time of the last hit or the insertion
+	accesses uint64    // number of hits
 }
 
 // SLRUList is a doubly linked list of SLRUCacheEntries backed by an array.
 // It maintains head and tail indices and the count of entries.
 type SLRUList[K comparable, V any] struct {
 	entries *[]SLRUCacheEntry[K, V]
-	head    int // index of the head entry
-	tail    int // index of the tail entry
-	count   int // number of entries in the list
+	tag     uint8 // marks the entries of this list
+	head    int   // index of the head entry
+	tail    int   // index of the tail entry
+	count   int   // number of entries in the list
+
+	prios  [priorityLevels]int // number of entries per priority
+	weight int64               // total weight of the entries
+	bytes  int64               // total size of the values, see SetSizer
+	sizer  func(V) int         // size of values, nil if not tracked
 }
 
 // NewSLRUList initializes a new empty SLRUList backed by the given entries slice.
-func NewSLRUList[K comparable, V any](entries *[]SLRUCacheEntry[K, V]) *SLRUList[K, V] {
+// Entries record the tag of the list they are in, so lists sharing entries
+// need distinct tags; tag 0 marks entries in no list and must not be used.
+func NewSLRUList[K comparable, V any](entries *[]SLRUCacheEntry[K, V], tag uint8) *SLRUList[K, V] {
 	return &SLRUList[K, V]{
 		entries: entries,
+		tag:     tag,
 		head:    SLRU_EOF,
 		tail:    SLRU_EOF,
 		count:   0,
@@ -52,7 +88,7 @@ func (l *SLRUList[K, V]) removeTail() int {
 	}
 
 	e := *l.entries
-	l.tail = e[t].prev
+	l.tail = int(e[t].prev)
 	e[t].next = SLRU_EOF
 	e[t].prev = SLRU_EOF
 
@@ -62,8 +98,13 @@ func (l *SLRUList[K, V]) removeTail() int {
 	} else {
 		e[l.tail].next = SLRU_EOF
 	}
-	e[t].list = nil
+	e[t].tag = 0
 	l.count--
+	l.prios[e[t].prio.level()]--
+	l.weight -= int64(e[t].weight)
+	if l.sizer != nil {
+		l.bytes -= int64(l.sizer(e[t].value))
+	}
 
 	return t
 }
@@ -76,7 +117,7 @@ func (l *SLRUList[K, V]) removeHead() int {
 	}
 
 	e := *l.entries
-	l.head = e[h].next
+	l.head = int(e[h].next)
 	e[h].next = SLRU_EOF
 	e[h].prev = SLRU_EOF
 
@@ -86,8 +127,13 @@ func (l *SLRUList[K, V]) removeHead() int {
 	} else {
 		e[l.head].prev = SLRU_EOF
 	}
-	e[h].list = nil
+	e[h].tag = 0
 	l.count--
+	l.prios[e[h].prio.level()]--
+	l.weight -= int64(e[h].weight)
+	if l.sizer != nil {
+		l.bytes -= int64(l.sizer(e[h].value))
+	}
 
 	return h
 }
@@ -98,7 +144,7 @@ func (l *SLRUList[K, V]) remove(n int) bool {
 	e := *l.entries
 
 	// Check if entry belongs to this list
-	if e[n].list != l {
+	if e[n].tag != l.tag {
 		return false
 	}
 
@@ -113,8 +159,13 @@ func (l *SLRUList[K, V]) remove(n int) bool {
 
 		e[n].next = SLRU_EOF
 		e[n].prev = SLRU_EOF
-		e[n].list = nil
+		e[n].tag = 0
 		l.count--
+		l.prios[e[n].prio.level()]--
+		l.weight -= int64(e[n].weight)
+		if l.sizer != nil {
+			l.bytes -= int64(l.sizer(e[n].value))
+		}
 	}
 
 	return true
@@ -128,8 +179,8 @@ func (l *SLRUList[K, V]) insertHead(n int) {
 
 	if h >= 0 {
 		// List has entries, link new head
-		e[h].prev = n
-		e[n].next = h
+		e[h].prev = int32(n)
+		e[n].next = int32(h)
 	} else {
 		// List was empty
 		e[n].next = SLRU_EOF
@@ -137,19 +188,110 @@ func (l *SLRUList[K, V]) insertHead(n int) {
 	}
 
 	e[n].prev = SLRU_EOF
-	e[n].list = l
+	e[n].tag = l.tag
 	l.head = n
 	l.count++
+	l.prios[e[n].prio.level()]++
+	l.weight += int64(e[n].weight)
+	if l.sizer != nil {
+		l.bytes += int64(l.sizer(e[n].value))
+	}
+}
+
+// insertTail inserts the entry at index n at the tail of the list.
+// Does not check if entry already exists in the list.
+func (l *SLRUList[K, V]) insertTail(n int) {
+	e := *l.entries
+	t := l.tail
+
+	if t >= 0 {
+		// List has entries, link new tail
+		e[t].next = int32(n)
+		e[n].prev = int32(t)
+	} else {
+		// List was empty
+		e[n].prev = SLRU_EOF
+		l.head = n
+	}
+
+	e[n].next = SLRU_EOF
+	e[n].tag = l.tag
+	l.tail = n
+	l.count++
+	l.prios[e[n].prio.level()]++
+	l.weight += int64(e[n].weight)
+	if l.sizer != nil {
+		l.bytes += int64(l.sizer(e[n].value))
+	}
+}
+
+// Head returns the index of the head entry or SLRU_EOF if the list is empty.
+func (l *SLRUList[K, V]) Head() int {
+	return l.head
+}
+
+// Tail returns the index of the tail entry or SLRU_EOF if the list is empty.
+func (l *SLRUList[K, V]) Tail() int {
+	return l.tail
+}
+
+// Len returns the number of entries in the list.
+func (l *SLRUList[K, V]) Len() int {
+	return l.count
+}
+
+// Next returns the index of the entry following n towards the tail.
+func (l *SLRUList[K, V]) Next(n int) int {
+	return int((*l.entries)[n].next)
+}
+
+// Prev returns the index of the entry preceding n towards the head.
+func (l *SLRUList[K, V]) Prev(n int) int {
+	return int((*l.entries)[n].prev)
+}
+
+// MoveToHead moves the entry at index n to the head of the list.
+// Returns false if the entry is not part of this list.
+func (l *SLRUList[K, V]) MoveToHead(n int) bool {
+	if n == l.head {
+		return (*l.entries)[n].tag == l.tag
+	}
+	if !l.remove(n) {
+		return false
+	}
+	l.insertHead(n)
+	return true
+}
+
+// MoveToTail moves the entry at index n to the tail of the list.
+// Returns false if the entry is not part of this list.
+func (l *SLRUList[K, V]) MoveToTail(n int) bool {
+	if n == l.tail {
+		return (*l.entries)[n].tag == l.tag
+	}
+	if !l.remove(n) {
+		return false
+	}
+	l.insertTail(n)
+	return true
 }
 
 // SLRUCache implements a segmented LRU cache with two segments:
 // - lrulist: protected entries with at least one hit (survivor entries)
 // - probelist: probationary entries with no hits yet
-// Entries are backed by an array and indexed by a map for O(1) lookup.
+// Entries are backed by an array and indexed by a map for O(1) lookup,
+// or by an open-addressed table, see SetOpenIndex.
 // Key type must be comparable for map keys.
+// With a probelist size of 0 the cache is a plain LRU cache: new entries
+// go straight into the lrulist.
 type SLRUCache[K comparable, V any] struct {
+	mu *sync.RWMutex // lock guarding the cache, the package mutex by default
+
 	entries []SLRUCacheEntry[K, V]
-	mapping map[K]int // key to entry index
+	mapping map[K]int     // key to entry index, nil if index is used
+	mapSize int           // number of keys the mapping is allocated for
+	index   *openIndex[K] // optional open-addressed replacement of mapping
+	hasher  Hasher[K]     // optional key hasher for sketches and indexes
 
 	cnum int // total number of entries (snum + pnum)
 	snum int // number of survivor entries (lrulist size)
@@ -158,28 +300,102 @@ type SLRUCache[K comparable, V any] struct {
 	insertCb func(K) // optional callback after insert into lrulist
 	removeCb func(K) // optional callback after removal from lrulist
 
+	policy      Policy[K, V]          // victim selection and ordering within both segments
+	probePolicy Policy[K, V]          // optional policy of the probelist overriding policy
+	webhook     *WebhookSink          // optional sink for significant events
+	demote      bool                  // demote protected victims into probelist
+	promoteHits int                   // probation hits needed for promotion, 0 for one
+	promotion   PromotionPolicy[K, V] // optional decision on probation hits
+	maxWeight   int64                 // limit of the total entry weight, 0 for none
+	evictBatch  int                   // minimum number of entries evicted for the weight limit
+	entryLimit  int                   // limit of the number of entries below the capacity, 0 for none
+	softLimit   int                   // number of entries above which lookups evict, 0 for none
+	hardLimit   int                   // number of entries above which inserts evict, 0 for the capacity
+	recovery    bool                  // rebuild instead of panicking on inconsistencies
+
+	equal  func(a, b V) bool // value comparison of CompareAndSwap, nil compares with ==
+	cloner func(V) V         // optional deep copy of values handed out by lookups
+
+	ttl         time.Duration         // time to live of inserted entries, 0 for no expiration
+	idleTTL     time.Duration         // time to live after the last hit, 0 for no idle expiration
+	epoch       uint32                // current epoch, entries of older epochs are stale
+	janitor     *janitor              // optional goroutine sweeping expired entries
+	sweepPos    int                   // entry index the next sweep starts at
+	negativeTTL time.Duration         // time to live of negative entries, 0 for no expiration
+	entryTTLs   map[int]time.Duration // own TTLs of entries by index, negative for a fixed deadline
+	leases      map[int]uint64        // generation of entries pinned by Acquire by index, see lease
+	leaseGen    uint64                // last generation handed out by lease
+	now         func() time.Time      // clock used for expiration
+
+	valueIndexes []valueIndexer[K, V] // secondary indexes, see NewValueIndex
+
+	stats Stats // operation counters
+
+	concurrent atomic.Bool    // serve protected hits under the read lock
+	reads      *readBuffer[K] // protected hits pending reordering
+
+	loader   LoaderFunc[K, V]   // optional loader for GetOrCompute
+	calls    map[K]*loadCall[V] // loads in flight by key
+	keyLocks *keyLocks[K]       // lock table of LockKey, created on first use
+
+	hooks    Hooks[K, V] // optional instrumentation hooks
+	recycle  func(V)     // optional recycler of evicted values
+	evicted  []KV[K, V]  // evictions pending delivery to hooks
+	recycled []V         // evicted values pending delivery to the recycler
+	spilled  []KV[K, V]  // evictions pending writing to the tier
+	collect  bool        // collect evictions for InsertEvict without hooks
+
+	events        chan CacheEvent[K] // optional event channel, created by Events
+	eventBuffer   int                // capacity of the event channel
+	droppedEvents uint64             // events dropped because the channel was full
+
+	mlog        *MutationLog[K, V] // optional log of mutations
+	tier        Tier[K, V]         // optional second tier receiving evicted entries
+	writeBehind *writeBehind[K, V] // optional asynchronous persistence of inserts
+	readThrough Store[K, V]        // optional store loaded from on misses
+
+	recorder atomic.Pointer[Recorder[K]]         // optional access trace recorder
+	async    atomic.Pointer[callbackQueue[K, V]] // optional queue of eviction callbacks
+	window   atomic.Pointer[hitWindow]           // optional sliding hit ratio window
+	overSoft atomic.Bool                         // entries above the soft limit
+
+	writeThrough atomic.Pointer[writeThrough[K, V]] // optional store written before caching
+
+	victims   *victimBuffer[K, V] // optional buffer of evicted entries
+	admission Admission[K]        // optional admission control of new keys
+
 	freelist  *SLRUList[K, V] // list of free entries
 	lrulist   *SLRUList[K, V] // protected segment
 	probelist *SLRUList[K, V] // probationary segment
 }
 
 // NewSLRUCache creates a new SLRUCache with given sizes for survivor and probe segments.
+// lruEntries must be at least 1 and probeEntries at least 0, otherwise
+// inserts fail later. Use NewSLRUCacheE to get invalid sizes reported.
 func NewSLRUCache[K comparable, V any](lruEntries int, probeEntries int) *SLRUCache[K, V] {
 	cache := &SLRUCache[K, V]{
-		snum:    lruEntries,
-		pnum:    probeEntries,
-		cnum:    lruEntries + probeEntries,
-		mapping: make(map[K]int),
+		mu:   &mutex,
+		snum: lruEntries,
+		pnum: probeEntries,
+		cnum: lruEntries + probeEntries,
 	}
+	// Pre-size the mapping so a full cache never grows it
+	cache.mapSize = max(cache.cnum, 0)
+	cache.mapping = make(map[K]int, cache.mapSize)
 
 	cache.entries = make([]SLRUCacheEntry[K, V], cache.cnum)
 
-	cache.freelist = NewSLRUList(&cache.entries)
-	cache.lrulist = NewSLRUList(&cache.entries)
-	cache.probelist = NewSLRUList(&cache.entries)
+	cache.freelist = NewSLRUList(&cache.entries, tagFree)
+	cache.lrulist = NewSLRUList(&cache.entries, tagProtected)
+	cache.probelist = NewSLRUList(&cache.entries, tagProbation)
+	cache.lrulist.sizer = valueSizer[V]()
+	cache.probelist.sizer = cache.lrulist.sizer
 
 	cache.insertCb = nil
 	cache.removeCb = nil
+	cache.policy = LRUPolicy[K, V]{}
+	cache.evictBatch = 1
+	cache.now = time.Now
 
 	// Initialize freelist with all entries
 	for i := 0; i < cache.cnum; i++ {
@@ -189,108 +405,669 @@ func NewSLRUCache[K comparable, V any](lruEntries int, probeEntries int) *SLRUCa
 	return cache
 }
 
+// NewSLRUCacheE is NewSLRUCache returning an error wrapping
+// ErrInvalidConfig for invalid sizes instead of creating a cache that fails
+// on first use.
+func NewSLRUCacheE[K comparable, V any](lruEntries int, probeEntries int) (*SLRUCache[K, V], error) {
+	if err := validateSizes(lruEntries, probeEntries); err != nil {
+		return nil, err
+	}
+	return NewSLRUCache[K, V](lruEntries, probeEntries), nil
+}
+
+// SetPolicy replaces the eviction policy used for both segments, or for
+// the lrulist only if a probation policy is set.
+// It should be called before the cache is populated.
+func (c *SLRUCache[K, V]) SetPolicy(p Policy[K, V]) {
+	c.mu.Lock()
+	c.policy = p
+	c.mu.Unlock()
+}
+
+// SetProbationPolicy sets a separate eviction policy for the probelist,
+// for example MRUPolicy for cyclic scans larger than the cache or
+// FIFOPolicy for a strictly FIFO probelist in insert heavy workloads.
+// Pass nil to use the policy of SetPolicy for both segments again.
+// It should be called before the cache is populated.
+func (c *SLRUCache[K, V]) SetProbationPolicy(p Policy[K, V]) {
+	c.mu.Lock()
+	c.probePolicy = p
+	c.mu.Unlock()
+}
+
+// policyOf returns the policy in charge of list l.
+func (c *SLRUCache[K, V]) policyOf(l *SLRUList[K, V]) Policy[K, V] {
+	if l == c.probelist && c.probePolicy != nil {
+		return c.probePolicy
+	}
+	return c.policy
+}
+
+// SetWebhook attaches a sink reporting significant events to a webhook.
+// Pass nil to detach it.
+func (c *SLRUCache[K, V]) SetWebhook(s *WebhookSink) {
+	c.mu.Lock()
+	c.webhook = s
+	c.mu.Unlock()
+}
+
+// SetDemotion enables demotion of protected victims. When enabled, the
+// entry evicted from a full lrulist during promotion moves to the head of
+// the probelist instead of leaving the cache, like classic SLRU does.
+func (c *SLRUCache[K, V]) SetDemotion(enabled bool) {
+	c.mu.Lock()
+	c.demote = enabled
+	c.mu.Unlock()
+}
+
+// SetPromotionThreshold sets the number of hits an entry needs in the
+// probelist before it is promoted to the lrulist, 1 by default. Entries
+// below the threshold are kept in the probelist as by a probation hit, so
+// keys hit only occasionally do not flood the protected segment in noisy
+// workloads. The hits are counted by the per entry hit counter.
+func (c *SLRUCache[K, V]) SetPromotionThreshold(hits int) {
+	c.mu.Lock()
+	c.promoteHits = hits
+	c.mu.Unlock()
+}
+
 // doPanic is called on fatal errors to check cache sanity before panicking.
+// In recovery mode the cache is rebuilt and the current operation is
+// aborted by a corruptionPanic, which recoverCorruption turns into a miss.
 func (c *SLRUCache[K, V]) doPanic(msg string) {
 	checkSLRUCacheSanity(c)
-	panic(msg)
+	if c.webhook != nil {
+		c.webhook.corrupted(msg)
+	}
+	if c.recovery {
+		c.rebuild()
+		c.stats.Corruptions++
+		panic(corruptionPanic(msg))
+	}
+	panic(fatalPanic(msg))
+}
+
+// clearEntry removes the key of the entry at index n from the mapping
+// and clears the entry. It does not touch the lists.
+func (c *SLRUCache[K, V]) clearEntry(n int) {
+	c.deleteIndex(c.entries[n].key)
+	var zeroK K
+	var zeroV V
+	c.entries[n].key = zeroK
+	c.entries[n].value = zeroV
+	c.entries[n].pins = 0
+	c.entries[n].weight = 0
+	c.entries[n].prio = PriorityNormal
+	c.entries[n].expires = time.Time{}
+	c.entries[n].negative = false
+	c.entries[n].inserted = time.Time{}
+	c.entries[n].accessed = time.Time{}
+	c.entries[n].accesses = 0
+	delete(c.entryTTLs, n)
+	delete(c.leases, n)
+}
+
+// victim returns the index of the entry the policy selects for eviction
+// from l. Entries of the lowest priority present in l are preferred,
+// pinned entries and entries of higher priority are skipped by moving
+// them to the head of l, or as decided by a policy implementing Skipper.
+// Returns SLRU_EOF if l is empty or all of its entries are pinned.
+func (c *SLRUCache[K, V]) victim(l *SLRUList[K, V]) int {
+	if l == c.lrulist {
+		c.drainReads()
+	}
+	policy := c.policyOf(l)
+	skipper, _ := policy.(Skipper[K, V])
+	for p := PriorityLow; p <= PriorityHigh; p++ {
+		if l.prios[p.level()] == 0 {
+			continue
+		}
+		for i := l.count; i > 0; i-- {
+			n := policy.Victim(l)
+			if n == SLRU_EOF {
+				return n
+			}
+			if e := &c.entries[n]; e.pins == 0 && e.prio <= p {
+				return n
+			}
+			if skipper != nil {
+				skipper.Skip(l, n)
+			} else {
+				l.MoveToHead(n)
+			}
+		}
+	}
+	return SLRU_EOF
+}
+
+// evict removes the victim chosen by the policy from l and clears it.
+// Returns the index of the evicted entry, which is not part of any list,
+// or SLRU_EOF if all entries of l are pinned.
+func (c *SLRUCache[K, V]) evict(l *SLRUList[K, V], op string) int {
+	if l.count == 0 {
+		c.doPanic(fmt.Sprintf("%s: no entry to evict", op))
+	}
+	n := c.victim(l)
+	if n == SLRU_EOF {
+		return SLRU_EOF
+	}
+	c.evictEntry(l, n, op)
+	return n
+}
+
+// evictEntry removes the entry at index n from l and clears it, spilling
+// it to the tier and recording the eviction.
+func (c *SLRUCache[K, V]) evictEntry(l *SLRUList[K, V], n int, op string) {
+	c.dropVictim(l, n, op)
+	c.stats.Evictions++
+	if c.webhook != nil {
+		c.webhook.evicted(1)
+	}
+}
+
+// dropVictim is evictEntry without counting the eviction, for callers
+// counting a batch of evictions at once.
+func (c *SLRUCache[K, V]) dropVictim(l *SLRUList[K, V], n int, op string) {
+	if !l.remove(n) {
+		c.doPanic(fmt.Sprintf("%s: cannot remove victim index %d", op, n))
+	}
+	if c.tier != nil {
+		c.addSpill(n)
+	}
+	if c.events != nil {
+		c.emit(EventEvict, c.entries[n].key)
+	}
+	if c.victims != nil && !c.entries[n].negative && !c.expired(n) {
+		// The buffer keeps the value, recycle the one it drops instead
+		old, dropped := c.victims.add(c.entries[n].key, c.entries[n].value, c.entries[n].expires)
+		if dropped && c.recycle != nil {
+			c.recycled = append(c.recycled, old)
+		}
+	} else if c.recycle != nil {
+		c.recycled = append(c.recycled, c.entries[n].value)
+	}
+	if c.hooks != nil || c.collect {
+		c.evicted = append(c.evicted, KV[K, V]{Key: c.entries[n].key, Value: c.entries[n].value})
+	}
+	c.clearEntry(n)
 }
 
 // Lookup returns a pointer to the value for the given key, or nil if not found.
 // It also promotes entries from probelist to lrulist on hit.
+// Expired entries and negative entries are reported as not found.
 func (c *SLRUCache[K, V]) Lookup(key K) *V {
-	n, ok := c.mapping[key]
-	if !ok {
-		return nil
+	v, _ := c.lookup(key, nil)
+	return v
+}
+
+// Get returns a copy of the value for the given key and whether it was found.
+// It behaves like Lookup but the copy stays valid when the entry is later
+// evicted and its slot reused, so it is the recommended accessor. Misses
+// are loaded from the read-through store, if any, see SetReadThrough.
+func (c *SLRUCache[K, V]) Get(key K) (V, bool) {
+	v, err := c.get(key)
+	return v, err == nil
+}
+
+// GetOrDefault returns a copy of the cached value for key, or def if it is
+// not cached. def is not inserted and the read-through store not consulted.
+func (c *SLRUCache[K, V]) GetOrDefault(key K, def V) V {
+	var out lookupOut[V]
+	if _, state := c.lookup(key, &out); state == LookupHit {
+		return out.value
 	}
+	return def
+}
 
-	mutex.Lock()
+// get looks up key and loads it from the read-through store on a miss.
+// Returns ErrNotFound on a miss or a negative entry, or the load error.
+func (c *SLRUCache[K, V]) get(key K) (V, error) {
+	var out lookupOut[V]
+	switch _, state := c.lookup(key, &out); state {
+	case LookupHit:
+		return out.value, nil
+	case LookupMiss:
+		c.mu.RLock()
+		rt := c.readThrough
+		c.mu.RUnlock()
+		if rt != nil {
+			return c.compute(context.Background(), key, rt.Get, true)
+		}
+	}
+	var zero V
+	return zero, ErrNotFound
+}
 
-	e := &c.entries[n]
-	// If entry is in lrulist (protected segment)
-	if e.list == c.lrulist {
-		if n != c.lrulist.head {
-			// Move to head of lrulist (most recently used)
+// Touch marks the entry for key as used like a Lookup, promoting it on a
+// probelist hit, without returning its value. Returns true on a hit.
+func (c *SLRUCache[K, V]) Touch(key K) bool {
+	_, state := c.lookup(key, nil)
+	return state == LookupHit
+}
 
-			if !c.lrulist.remove(n) {
-				c.doPanic(fmt.Sprintf("Lookup: cannot remove from lrulist index %d", n))
-			}
-			c.lrulist.insertHead(n)
+// lookup returns a pointer to the value for the given key and the state of
+// the lookup. The pointer is nil unless the state is LookupHit.
+// If out is not nil, it receives the results of a hit under the mutex.
+func (c *SLRUCache[K, V]) lookup(key K, out *lookupOut[V]) (v *V, state LookupState) {
+	c.record(TraceLookup, key)
+	if c.overSoft.Load() {
+		defer c.softEvict()
+	}
+
+	if c.concurrent.Load() {
+		if v, ok := c.lookupShared(key, out); ok {
+			return v, LookupHit
 		}
-		mutex.Unlock()
-		return &e.value
 	}
 
-	// Entry is in probelist or freelist (should not be freelist)
-	// Try to promote to lrulist
-	var removal bool
-	var removedKey K
-	if c.lrulist.count >= c.snum {
-		// lrulist full, remove tail entry
-		lt := c.lrulist.removeTail()
-		if lt != SLRU_EOF {
-			// Remove old key from mapping and clear entry
-			delete(c.mapping, c.entries[lt].key)
-			removal = true
-			removedKey = c.entries[lt].key
-			var zeroK K
-			var zeroV V
-			c.entries[lt].key = zeroK
-			c.entries[lt].value = zeroV
-			// Put removed entry into freelist
-			c.freelist.insertHead(lt)
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
 
+	if hooks := c.hooks; hooks != nil {
+		// Deliver hooks after the mutex has been released
+		defer func() {
+			if state == LookupMiss {
+				hooks.OnMiss(key)
+			} else {
+				hooks.OnHit(key)
+			}
+		}()
+	}
+
+	n, ok := c.find(key)
+	expired := ok && c.expired(n)
+	if expired {
+		// Drop expired entry and report a miss
+		c.removeEntry(n)
+		c.stats.Expirations++
+		if c.events != nil {
+			c.emit(EventExpire, key)
+		}
+		ok = false
+	}
+	if !ok && !expired && c.victims != nil {
+		n, ok = c.readmit(key)
+	}
+	if c.webhook != nil {
+		c.webhook.lookup(ok)
+	}
+	if w := c.window.Load(); w != nil {
+		w.record(c.now(), ok)
+	}
+	if !ok {
+		c.stats.Misses++
+		tier, removeCb := c.tier, c.removeCb
+		c.mu.Unlock()
+		if expired {
+			c.deliverRemoved(removeCb, key)
+		}
+		if tier != nil {
+			if expired {
+				tier.Delete(key)
+			} else if v := c.reload(tier, key, out); v != nil {
+				// Reloaded from the second tier
+				return v, LookupHit
+			}
 		}
+		return nil, LookupMiss
 	}
 
-	// Remove from current list (probelist)
-	if !e.list.remove(n) {
-		c.doPanic(fmt.Sprintf("Lookup: cannot remove from probelist index %d", n))
+	e := &c.entries[n]
+	state = LookupHit
+	value := &e.value
+	if e.negative {
+		state = LookupNegative
+		value = nil
+	} else {
+		value = c.result(n, out)
+	}
+	if out != nil {
+		out.segment = c.segmentOf(n)
 	}
 
-	// Insert at head of lrulist
-	c.lrulist.insertHead(n)
+	c.stats.Hits++
+
+	// If entry is in lrulist (protected segment)
+	if e.tag == tagProtected {
+		c.stats.ProtectedHits++
+		// Let the policy reorder the lrulist, batched by the read buffer
+		c.protectedHit(n, key)
+		// A readmitted entry may have evicted the protected victim
+		hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+		c.mu.Unlock()
+		c.deliverEvicted(hooks, recycle, evicted)
+		return value, state
+	}
+
+	e.accessed = c.now()
+	e.accesses++
+
+	// Entry is in probelist or freelist (should not be freelist)
+	c.stats.ProbationHits++
+
+	switch c.promotionOf(n) {
+	case KeepProbation:
+		// Not hit often enough yet or held back by the promotion policy
+		c.policyOf(c.probelist).Hit(c.probelist, n)
+		c.mu.Unlock()
+		return value, state
+	case Ignore:
+		c.mu.Unlock()
+		return value, state
+	}
+
+	removedKey, removal, ok := c.promote(n, "Lookup")
+	if !ok {
+		// lrulist is pinned completely, keep entry in probelist
+		c.policyOf(c.probelist).Hit(c.probelist, n)
+		c.mu.Unlock()
+		return value, state
+	}
+	if out != nil {
+		out.promoted = true
+	}
 
 	// Unlock mutex before user callbacks
-	mutex.Unlock()
+	hooks, recycle, evicted, removeCb := c.hooks, c.recycle, c.takeEvicted(), c.removeCb
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
 
-	if c.removeCb != nil && removal {
-		c.removeCb(removedKey)
+	if removal {
+		c.deliverRemoved(removeCb, removedKey)
 	}
 
 	if c.insertCb != nil {
 		c.insertCb(key)
 	}
 
-	return &e.value
+	return value, state
+}
+
+// promote moves the probationary entry at index n to the head of the
+// lrulist, vacating the lrulist victim if it is full. Returns the key of
+// the vacated victim and whether there was one, and false if the lrulist
+// is pinned completely. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) promote(n int, op string) (removedKey K, removal bool, ok bool) {
+	// Select the lrulist victim if promotion needs room
+	lt := SLRU_EOF
+	if c.lrulist.count >= c.snum {
+		lt = c.victim(c.lrulist)
+		if lt == SLRU_EOF {
+			return removedKey, false, false
+		}
+	}
+
+	// Remove from current list (probelist)
+	if !c.probelist.remove(n) {
+		c.doPanic(fmt.Sprintf("%s: cannot remove from probelist index %d", op, n))
+	}
+
+	if lt != SLRU_EOF {
+		// lrulist full, remove the victim chosen by the policy. Demotion
+		// cannot fail, n just left the probelist.
+		removal = true
+		removedKey = c.entries[lt].key
+		c.vacateProtected(lt, op)
+	}
+
+	// Insert at head of lrulist
+	c.lrulist.insertHead(n)
+	c.policy.Inserted(c.lrulist, n)
+	c.stats.Promotions++
+	if c.events != nil {
+		c.emit(EventPromote, c.entries[n].key)
+	}
+	if c.mlog != nil {
+		c.logMutation(logPromote, n, c.entries[n].key)
+	}
+	return removedKey, removal, true
+}
+
+// vacateProtected makes room in the full lrulist by demoting its victim at
+// index lt to the probelist if demotion is enabled, or evicting it.
+// Returns false if the victim could not be demoted because the probelist
+// is full and pinned completely. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) vacateProtected(lt int, op string) bool {
+	if !c.demote {
+		// Evict the victim and put it into freelist
+		c.evictEntry(c.lrulist, lt, op)
+		c.freelist.insertHead(lt)
+		return true
+	}
+
+	// Give the victim a second chance in probelist
+	return c.demoteEntry(lt, false, op)
+}
+
+// demoteEntry moves the protected entry at index n to the head of the
+// probelist, or its tail if tail is set, evicting the probelist victim if
+// it is full. Returns false and leaves the entry in the lrulist if the
+// probelist is full and pinned completely. Must be called with the mutex
+// held.
+func (c *SLRUCache[K, V]) demoteEntry(n int, tail bool, op string) bool {
+	if c.probelist.count >= c.pnum {
+		pt := c.evict(c.probelist, op)
+		if pt == SLRU_EOF {
+			return false
+		}
+		c.freelist.insertHead(pt)
+	}
+	if !c.lrulist.remove(n) {
+		c.doPanic(fmt.Sprintf("%s: cannot remove victim from lrulist index %d", op, n))
+	}
+	if tail {
+		c.probelist.insertTail(n)
+	} else {
+		c.probelist.insertHead(n)
+		c.policyOf(c.probelist).Inserted(c.probelist, n)
+	}
+	c.stats.Demotions++
+	if c.events != nil {
+		c.emit(EventDemote, c.entries[n].key)
+	}
+	if c.mlog != nil {
+		op := logDemote
+		if tail {
+			op = logDemoteTail
+		}
+		c.logMutation(op, n, c.entries[n].key)
+	}
+	return true
 }
 
 // Insert adds or updates a key-value pair in the cache.
 // New entries go into the probelist first.
 func (c *SLRUCache[K, V]) Insert(key K, value V) {
+	c.insert(key, value, entryOptions{})
+}
+
+// Upsert adds or updates a key-value pair like Insert and returns the value
+// it replaced and whether there was one, without a separate Lookup that
+// would also change the recency of the entry.
+func (c *SLRUCache[K, V]) Upsert(key K, value V) (old V, existed bool) {
+	var out insertOut[K, V]
+	c.insertWith(key, value, entryOptions{}, &out)
+	return out.old, out.existed
+}
+
+// InsertEvict adds or updates a key-value pair like Insert and returns the
+// entries it evicted, so callers can cascade them to a lower tier or
+// release their resources without callbacks. Evicted entries are still
+// passed to the hooks and the recycler, if any.
+func (c *SLRUCache[K, V]) InsertEvict(key K, value V) []KV[K, V] {
+	out := insertOut[K, V]{collect: true}
+	c.insertWith(key, value, entryOptions{}, &out)
+	return out.evicted
+}
+
+// TryInsert adds or updates a key-value pair like Insert, but only if the
+// key is cached or the new entry fits without evicting another one, for
+// callers preferring to drop new data over displacing hot data. Returns
+// false if the entry was not inserted.
+func (c *SLRUCache[K, V]) TryInsert(key K, value V) bool {
+	return c.insert(key, value, entryOptions{noEvict: true}) == nil
+}
+
+// hasRoom reports whether a new entry with options o fits without
+// evicting. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) hasRoom(o entryOptions) bool {
+	l, size := c.probelist, c.pnum
+	if c.pnum == 0 {
+		l, size = c.lrulist, c.snum
+	}
+	if l.count >= size {
+		return false
+	}
+	if limit := c.limit(); limit > 0 && c.indexLen() >= limit {
+		return false
+	}
+	weight := max(int64(o.weight), 1)
+	return c.maxWeight <= 0 || c.probelist.weight+c.lrulist.weight+weight <= c.maxWeight
+}
+
+// insertOut receives the results of an insert under the mutex.
+type insertOut[K comparable, V any] struct {
+	old     V    // value replaced by the insert
+	existed bool // a value was replaced
+
+	collect bool       // collect the evicted entries
+	evicted []KV[K, V] // entries evicted by the insert
+}
+
+// insert adds or updates a key-value pair in the cache with options o.
+// Returns ErrCapacityExceeded if a new entry was dropped because the
+// target segment is pinned completely or full with o.noEvict, ErrRejected if the admission
+// control rejected it, or the error of the write-through store.
+func (c *SLRUCache[K, V]) insert(key K, value V, o entryOptions) error {
+	return c.insertWith(key, value, o, nil)
+}
+
+// insertWith is insert filling out if it is not nil.
+func (c *SLRUCache[K, V]) insertWith(key K, value V, o entryOptions, out *insertOut[K, V]) error {
+	c.record(TraceInsert, key)
+	wt := c.writeThrough.Load()
+	if wt != nil && !o.negative && !o.stored {
+		wt.mu.Lock()
+		defer wt.mu.Unlock()
+		if !o.noEvict {
+			if err := wt.store.Put(context.Background(), key, value); err != nil {
+				return err
+			}
+		}
+	} else {
+		wt = nil
+	}
+
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+	c.drainReads()
+	if wt != nil && o.noEvict {
+		// Write only entries that fit
+		if _, ok := c.find(key); !ok && !c.hasRoom(o) {
+			c.mu.Unlock()
+			return ErrCapacityExceeded
+		}
+		if err := c.putUnlocked(wt, key, value); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+	}
+	return c.insertLocked(key, value, o, out)
+}
 
-	mutex.Lock()
+// insertLocked is insertWith after writing through. It must be called with
+// the mutex held and releases it.
+func (c *SLRUCache[K, V]) insertLocked(key K, value V, o entryOptions, out *insertOut[K, V]) error {
+	c.collect = out != nil && out.collect
 
-	if n, ok := c.mapping[key]; ok {
+	if n, ok := c.find(key); ok {
 		// Key exists, update value if changed
-		e := &c.entries[n]
-		e.value = value
-		mutex.Unlock()
-		return
+		if out != nil && !c.entries[n].negative && !c.expired(n) {
+			out.old, out.existed = c.entries[n].value, true
+		}
+		c.update(n, value, o)
+	} else if o.noEvict && !c.hasRoom(o) {
+		c.collect = false
+		c.mu.Unlock()
+		return ErrCapacityExceeded
+	} else if c.admission != nil && !o.pin && !o.negative && !o.bypass && !c.admission.Admit(key) {
+		c.stats.Rejections++
+		c.collect = false
+		c.mu.Unlock()
+		return ErrRejected
+	} else if c.insertNew(key, value, o) == SLRU_EOF {
+		c.collect = false
+		c.mu.Unlock()
+		return ErrCapacityExceeded
+	}
+	c.enforceWeight()
+	c.enforceEntryLimit()
+
+	hooks, recycle, evicted := c.hooks, c.recycle, c.takeEvicted()
+	if c.collect {
+		c.collect = false
+		out.evicted = evicted.entries
+	}
+	// Enqueue under the mutex, so the store sees the updates in cache order
+	if wb := c.writeBehind; wb != nil && !o.negative {
+		wb.add(key, value)
+	}
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, evicted)
+	if hooks != nil && !o.negative {
+		hooks.OnInsert(key, value)
+	}
+	return nil
+}
+
+// update sets the value of the existing entry at index n. The priority is
+// only changed if o.setPrio is set, the weight only if o.weight is set.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) update(n int, value V, o entryOptions) {
+	c.setValue(n, value)
+	e := &c.entries[n]
+	e.negative = o.negative
+	e.expires = c.expiry(o)
+	c.setEntryTTL(n, o)
+	e.epoch = c.epoch
+	if o.setPrio && e.prio != o.prio {
+		l := c.listOf(n)
+		l.prios[e.prio.level()]--
+		l.prios[o.prio.level()]++
+		e.prio = o.prio
+	}
+	if o.weight > 0 {
+		c.setWeight(n, o.weight)
+	}
+	if o.pin {
+		e.pins++
+	}
+	if c.mlog != nil {
+		c.logMutation(logOp(o.negative), n, e.key)
+	}
+}
+
+// insertNew adds a new entry at the head of the probelist, evicting the
+// probelist victim if it is full. Without probelist (plain LRU mode) the
+// entry goes to the lrulist instead. Returns the index of the entry, or
+// SLRU_EOF if it was dropped because all entries of the target list are
+// pinned. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) insertNew(key K, value V, o entryOptions) int {
+	l, size := c.probelist, c.pnum
+	if c.pnum == 0 {
+		l, size = c.lrulist, c.snum
 	}
 
 	var n int
-	if c.probelist.count >= c.pnum {
-		// Probelist full, evict tail entry
-		n = c.probelist.removeTail()
+	if l.count >= size {
+		// List full, evict the victim chosen by the policy
+		n = c.evict(l, "Insert")
 		if n == SLRU_EOF {
-			c.doPanic(fmt.Sprintf("Insert: no entry to evict in probelist for key %v", key))
+			// All entries are pinned, drop the new entry
+			return SLRU_EOF
 		}
-		// Remove old key from mapping and clear entry
-		delete(c.mapping, c.entries[n].key)
-		var zeroK K
-		var zeroV V
-		c.entries[n].key = zeroK
-		c.entries[n].value = zeroV
 
 	} else {
 		// Take from freelist
@@ -300,113 +1077,121 @@ func (c *SLRUCache[K, V]) Insert(key K, value V) {
 		}
 	}
 
-	// Set new key and value
+	// Set new key, value and priority
 	c.entries[n].key = key
 	c.entries[n].value = value
+	c.entries[n].prio = o.prio
+	c.entries[n].weight = max(o.weight, 1)
+	c.entries[n].negative = o.negative
+	c.entries[n].expires = c.expiry(o)
+	c.setEntryTTL(n, o)
+	if o.pin {
+		c.entries[n].pins = 1
+	}
+	c.entries[n].epoch = c.epoch
+	c.entries[n].inserted = c.now()
+	c.entries[n].accessed = c.entries[n].inserted
 
 	// Add to mapping
-	c.mapping[key] = n
+	c.setIndex(key, n)
+	c.stats.Inserts++
+	if c.events != nil {
+		c.emit(EventInsert, key)
+	}
 
-	// Insert at head of probelist
-	c.probelist.insertHead(n)
+	// Insert at head of the target list
+	l.insertHead(n)
+	c.policyOf(l).Inserted(l, n)
 
-	mutex.Unlock()
+	if c.mlog != nil {
+		c.logMutation(logOp(o.negative), n, key)
+	}
+	return n
 }
 
 // Remove deletes an entry by key from the cache.
 // Returns true if the entry was found and removed.
 func (c *SLRUCache[K, V]) Remove(key K) bool {
+	_, _, ok := c.remove(key)
+	return ok
+}
 
-	n, ok := c.mapping[key]
-	if !ok {
-		return false
-	}
+// RemoveGet deletes key from the cache and returns its value, so callers
+// can release the resource held by the value without a racy Lookup before
+// Remove. Returns false if the key was not found; negative and expired
+// entries are removed but reported as not found.
+func (c *SLRUCache[K, V]) RemoveGet(key K) (V, bool) {
+	v, live, _ := c.remove(key)
+	return v, live
+}
 
-	mutex.Lock()
+// remove implements Remove and RemoveGet. It returns the value of the
+// removed entry and whether it was live, i.e. neither negative nor
+// expired, and whether an entry was removed.
+func (c *SLRUCache[K, V]) remove(key K) (v V, live bool, removed bool) {
+	c.record(TraceRemove, key)
 
-	e := &c.entries[n]
-	if e.list != nil {
-		e.list.remove(n)
-	}
+	c.mu.Lock()
 
-	delete(c.mapping, key)
+	n, ok := c.find(key)
+	if !ok {
+		if c.victims != nil {
+			c.victims.remove(key)
+		}
+		c.mu.Unlock()
+		return v, false, false
+	}
 
-	// Clear entry and return to freelist
-	var zeroK K
-	var zeroV V
-	e.key = zeroK
-	e.value = zeroV
-	c.freelist.insertHead(n)
+	if e := &c.entries[n]; !e.negative && !c.expired(n) {
+		v, live = e.value, true
+	}
+	c.removeKey(n, key)
+	tier, removeCb := c.tier, c.removeCb
 
-	mutex.Unlock()
+	c.mu.Unlock()
 
-	if c.removeCb != nil {
-		c.removeCb(key)
+	if tier != nil {
+		tier.Delete(key)
 	}
+	c.deliverRemoved(removeCb, key)
 
-	return true
+	return v, live, true
 }
 
-// checkSLRUCacheSanity verifies internal consistency of the cache lists.
-// Returns true if any inconsistency is found.
-func checkSLRUCacheSanity[K comparable, V any](c *SLRUCache[K, V]) bool {
-	fail := false
-	topic := ""
-
-	failure := func(msg string) {
-		fail = true
-		fmt.Printf("%s: %s\n", topic, msg)
+// removeKey removes the entry at index n holding key and records the
+// removal. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) removeKey(n int, key K) {
+	if c.victims != nil {
+		c.victims.remove(key)
 	}
+	c.removeEntry(n)
+	if c.mlog != nil {
+		c.logMutation(logRemove, n, key)
+	}
+	if c.events != nil {
+		c.emit(EventRemove, key)
+	}
+}
 
-	walkList := func(l *SLRUList[K, V]) {
-		n := l.head
-		ln := n
-		entries := *l.entries
-
-		var lastList *SLRUList[K, V]
-
-		for n >= 0 {
-			e := entries[n]
-
-			if e.prev >= 0 && entries[e.prev].next != n {
-				failure("prev link failure")
-			}
-			if e.next >= 0 && entries[e.next].prev != n {
-				failure("next link failure")
-			}
-			if e.list == nil {
-				failure("nil list reference")
-			}
-			if lastList != nil && lastList != e.list {
-				failure("multiple list references")
-			}
-
-			ln = n
-			n = e.next
-			lastList = e.list
-		}
-
-		if l.tail != ln {
-			failure("tail reference mismatch")
-		}
+// removeEntry removes the entry at index n from its list, clears it and
+// returns it to the freelist. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) removeEntry(n int) {
+	if l := c.listOf(n); l != nil {
+		l.remove(n)
 	}
 
-	topic = "freelist"
-	walkList(c.freelist)
-	topic = "probelist"
-	walkList(c.probelist)
-	topic = "lrulist"
-	walkList(c.lrulist)
+	// Clear entry and return to freelist
+	c.clearEntry(n)
+	c.freelist.insertHead(n)
+}
 
-	if c.freelist.count > c.cnum {
-		failure("freelist size overflow")
-	}
-	if c.probelist.count > c.pnum {
-		failure("probelist size overflow")
-	}
-	if c.lrulist.count > c.snum {
-		failure("lrulist size overflow")
+// checkSLRUCacheSanity verifies internal consistency of the cache lists
+// and prints the problems found.
+// Returns true if any inconsistency is found.
+func checkSLRUCacheSanity[K comparable, V any](c *SLRUCache[K, V]) bool {
+	problems := c.verify()
+	for _, p := range problems {
+		fmt.Println(p)
 	}
-
-	return fail
+	return len(problems) > 0
 }
@@ -1,10 +1,12 @@
 package slrucache
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"strconv"
 	"testing"
+	"unsafe"
 )
 
 // The generic SLRUCache uses type parameters for keys and values.
@@ -152,3 +154,206 @@ func BenchmarkMovingWindow(b *testing.B) {
 		movingWindow(c, 10, 100, 5, 2, false)
 	}
 }
+
+// TestSLRUCacheDemotion tests that protected victims get a second chance in probe.
+func TestSLRUCacheDemotion(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.SetDemotion(true)
+	insertN(c, 5, 0)
+	lookupN(c, 5, 0)
+	insertN(c, 5, 5)
+	lookupN(c, 2, 5)
+
+	// two originals demoted to probe instead of being removed
+	if checkListCount(c, 0, 5, 5, "demote protected victims") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if c.Lookup("0") == nil || c.Lookup("1") == nil {
+		t.Error("demoted entries should still be cached")
+	}
+
+	// fill probe with new entries, demoted entries are evicted eventually
+	insertN(c, 5, 100)
+	if len(c.mapping) != 10 || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestSLRUCacheGet tests that Get returns a copy that survives slot reuse.
+func TestSLRUCacheGet(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	c.Insert("a", "a")
+
+	v, ok := c.Get("a")
+	p := c.Lookup("a")
+	if !ok || v != "a" || p == nil {
+		t.Fatal("Get should find the entry")
+	}
+
+	// push a out of the cache, its slot gets reused
+	c.Insert("b", "b")
+	c.Lookup("b")
+	c.Insert("c", "c")
+	if v != "a" {
+		t.Error("copy returned by Get should not change")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("evicted entry should not be found")
+	}
+}
+
+// TestSLRUCachePlainLRU tests that a cache without probelist behaves as LRU.
+func TestSLRUCachePlainLRU(t *testing.T) {
+	c := NewSLRUCache[string, string](3, 0)
+	insertN(c, 3, 0)
+	if checkListCount(c, 0, 3, 0, "plain LRU insert") {
+		t.Fail()
+	}
+
+	// hit 0, so 1 is the least recently used entry
+	c.Lookup("0")
+	c.Insert("3", "3")
+	if c.Lookup("1") != nil || c.Lookup("0") == nil || c.Lookup("3") == nil {
+		t.Error("plain LRU should evict the least recently used entry")
+	}
+	if checkListCount(c, 0, 3, 0, "plain LRU evict") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestSLRUCacheTouch tests that Touch promotes like a hit.
+func TestSLRUCacheTouch(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	c.Insert("0", "0")
+	c.Insert("1", "1")
+	if !c.Touch("0") || c.Touch("missing") {
+		t.Error("Touch should report hits only")
+	}
+	if checkListCount(c, 2, 1, 1, "touch promote") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Promotions != 1 {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+// TestNewSLRUCacheE tests that invalid sizes are reported.
+func TestNewSLRUCacheE(t *testing.T) {
+	for _, sizes := range [][2]int{{0, 1}, {-1, 1}, {1, -1}, {MaxEntries, 1}} {
+		if _, err := NewSLRUCacheE[string, string](sizes[0], sizes[1]); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("sizes %v should be invalid, got %v", sizes, err)
+		}
+	}
+	if c, err := NewSLRUCacheE[string, string](1, 0); err != nil || c == nil {
+		t.Error("plain LRU sizes should be valid")
+	}
+}
+
+// TestEntrySize guards the compact entry layout.
+func TestEntrySize(t *testing.T) {
+	// key, value, 4 int32, prio, tag, negative, 3 times and accesses
+	if s := unsafe.Sizeof(SLRUCacheEntry[int, int]{}); s > 120 {
+		t.Errorf("entry size %d", s)
+	}
+}
+
+// TestSLRUCachePromotionThreshold tests that promotion waits for the configured probation hits.
+func TestSLRUCachePromotionThreshold(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	c.SetPromotionThreshold(3)
+	c.Insert("0", "0")
+	lookupN(c, 1, 0)
+	lookupN(c, 1, 0)
+	if checkListCount(c, 3, 0, 1, "below threshold") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	lookupN(c, 1, 0)
+	if checkListCount(c, 3, 1, 0, "threshold reached") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if s := c.Stats(); s.ProbationHits != 3 || s.Promotions != 1 {
+		t.Errorf("stats %+v", s)
+	}
+}
+
+// TestSLRUCacheGetOrDefault tests that the default is returned but not inserted.
+func TestSLRUCacheGetOrDefault(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	c.Insert("a", "1")
+	if v := c.GetOrDefault("a", "x"); v != "1" {
+		t.Errorf("cached value %q", v)
+	}
+	if v := c.GetOrDefault("b", "x"); v != "x" || c.Lookup("b") != nil {
+		t.Errorf("default %q should not be inserted", v)
+	}
+}
+
+// TestSLRUCacheUpsert tests that Upsert reports the replaced value without promoting.
+func TestSLRUCacheUpsert(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	if old, existed := c.Upsert("a", "1"); existed || old != "" {
+		t.Errorf("new key: %q %v", old, existed)
+	}
+	if old, existed := c.Upsert("a", "2"); !existed || old != "1" {
+		t.Errorf("existing key: %q %v", old, existed)
+	}
+	if *c.Lookup("a") != "2" || checkListCount(c, 3, 1, 0, "upsert") {
+		t.Fail()
+	}
+	if s := c.Stats(); s.Hits != 1 {
+		t.Errorf("upsert should not count as lookup: %+v", s)
+	}
+}
+
+// TestSLRUCacheInsertEvict tests that the evicted entry is returned.
+func TestSLRUCacheInsertEvict(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	if ev := c.InsertEvict("a", "1"); len(ev) != 0 {
+		t.Errorf("nothing should be evicted: %v", ev)
+	}
+	ev := c.InsertEvict("b", "2")
+	if len(ev) != 1 || ev[0] != (KV[string, string]{"a", "1"}) {
+		t.Errorf("evicted %v", ev)
+	}
+	if c.evicted != nil || c.collect {
+		t.Error("evictions should not be collected after InsertEvict")
+	}
+}
+
+// TestSLRUCacheRemoveGet tests that the removed value is returned.
+func TestSLRUCacheRemoveGet(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	c.Insert("a", "1")
+	c.InsertNegative("n")
+	if v, ok := c.RemoveGet("a"); !ok || v != "1" || c.Lookup("a") != nil {
+		t.Errorf("remove get %q %v", v, ok)
+	}
+	if _, ok := c.RemoveGet("a"); ok {
+		t.Error("removed key should not be found")
+	}
+	if _, ok := c.RemoveGet("n"); ok || c.indexLen() != 0 {
+		t.Error("negative entry should be removed but not reported")
+	}
+}
+
+// TestSLRUCacheTryInsert tests that TryInsert never evicts.
+func TestSLRUCacheTryInsert(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	if !c.TryInsert("a", "1") || !c.TryInsert("b", "1") {
+		t.Error("inserts with room should succeed")
+	}
+	if c.TryInsert("c", "1") || c.Lookup("a") == nil {
+		t.Error("insert into a full probelist should be dropped")
+	}
+	if !c.TryInsert("b", "2") || *c.Lookup("b") != "2" {
+		t.Error("update of a cached key should succeed")
+	}
+	if !c.TryInsert("c", "1") {
+		t.Error("insert should succeed after promotions made room")
+	}
+
+	c.SetMaxWeight(3)
+	if c.TryInsert("d", "1") || checkSLRUCacheSanity(c) {
+		t.Error("insert over the weight limit should be dropped")
+	}
+}
@@ -0,0 +1,88 @@
+// author: (c) Gunter Hartmann
+
+package slrusim
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Optimal returns the hit ratio of Belady's clairvoyant algorithm on trace
+// for a cache of capacity entries: on a miss with a full cache it evicts
+// the entry used furthest in the future. No real cache can do better, so
+// it bounds what tuning a configuration can achieve.
+func Optimal[K comparable](trace []K, capacity int) float64 {
+	if len(trace) == 0 || capacity < 1 {
+		return 0
+	}
+
+	// next[i] is the position of the next access to trace[i]
+	next := make([]int, len(trace))
+	last := make(map[K]int)
+	for i := len(trace) - 1; i >= 0; i-- {
+		if j, ok := last[trace[i]]; ok {
+			next[i] = j
+		} else {
+			next[i] = math.MaxInt
+		}
+		last[trace[i]] = i
+	}
+
+	// cached maps keys to their next access, the heap orders them by it.
+	// Heap items of keys accessed since they were pushed are stale.
+	cached := make(map[K]int, capacity)
+	h := &useHeap[K]{}
+	hits := 0
+	for i, k := range trace {
+		if _, ok := cached[k]; ok {
+			hits++
+		} else if len(cached) >= capacity {
+			for {
+				it := heap.Pop(h).(use[K])
+				if n, ok := cached[it.key]; ok && n == it.next {
+					delete(cached, it.key)
+					break
+				}
+			}
+		}
+		cached[k] = next[i]
+		heap.Push(h, use[K]{key: k, next: next[i]})
+	}
+	return float64(hits) / float64(len(trace))
+}
+
+// CompareOptimal sets the Optimal field of each result to the hit ratio of
+// Belady's algorithm at the capacity of its configuration.
+func CompareOptimal[K comparable](trace []K, results []Result) {
+	byCapacity := make(map[int]float64)
+	for i := range results {
+		c := results[i].Capacity()
+		opt, ok := byCapacity[c]
+		if !ok {
+			opt = Optimal(trace, c)
+			byCapacity[c] = opt
+		}
+		results[i].Optimal = opt
+	}
+}
+
+// use is a cached key with the position of its next access.
+type use[K comparable] struct {
+	key  K
+	next int
+}
+
+// useHeap is a max heap of uses by next access.
+type useHeap[K comparable] []use[K]
+
+func (h useHeap[K]) Len() int           { return len(h) }
+func (h useHeap[K]) Less(i, j int) bool { return h[i].next > h[j].next }
+func (h useHeap[K]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *useHeap[K]) Push(x any)        { *h = append(*h, x.(use[K])) }
+
+func (h *useHeap[K]) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
@@ -0,0 +1,36 @@
+package slrusim
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestOptimal tests Belady's algorithm on a known trace.
+func TestOptimal(t *testing.T) {
+	// Cyclic access over 3 keys with room for 2: OPT keeps one key per miss
+	trace := []int{1, 2, 3, 1, 2, 3, 1, 2, 3}
+	if got := Optimal(trace, 2); got != 3.0/9 {
+		t.Errorf("optimal hit ratio %v", got)
+	}
+	if Optimal(trace, 3) != 6.0/9 || Optimal(trace, 0) != 0 || Optimal([]int{}, 2) != 0 {
+		t.Error("edge case failure")
+	}
+}
+
+// TestCompareOptimal tests that no configuration beats OPT.
+func TestCompareOptimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(rnd, 1.2, 1, 1000)
+	trace := make([]uint64, 20000)
+	for i := range trace {
+		trace[i] = z.Uint64()
+	}
+
+	results := Simulate(trace, Grid([]int{50, 200}, []float64{0, 0.2, 0.5}))
+	CompareOptimal(trace, results)
+	for _, r := range results {
+		if r.Optimal == 0 || r.HitRatio > r.Optimal {
+			t.Errorf("%+v exceeds the optimum", r)
+		}
+	}
+}
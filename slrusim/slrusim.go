@@ -0,0 +1,129 @@
+// author: (c) Gunter Hartmann
+
+// Package slrusim simulates SLRU caches of different sizes on a key trace
+// for capacity planning. The simulated caches store at most the size of
+// each entry, so large configurations can be evaluated on production
+// traces cheaply.
+//
+// Each lookup in the trace that misses is followed by an insert of the
+// key, like a cache fronting a slower store would do.
+package slrusim
+
+import (
+	"io"
+	"sort"
+
+	"slrucache"
+)
+
+// Config is a candidate cache configuration.
+type Config struct {
+	LRU   int // protected segment size
+	Probe int // probationary segment size
+}
+
+// Capacity returns the total number of entries.
+func (c Config) Capacity() int {
+	return c.LRU + c.Probe
+}
+
+// Result is the outcome of simulating one configuration.
+type Result struct {
+	Config
+	Hits     int
+	Misses   int
+	HitRatio float64
+	Bytes    int64   // peak size of the cached entries, set by SimulateSized
+	Optimal  float64 // hit ratio of Belady's algorithm, set by CompareOptimal
+}
+
+// Grid returns the configurations for each capacity split by each ratio
+// of probationary entries. Splits leaving a segment empty are skipped,
+// except a ratio of 0 which yields a plain LRU cache.
+func Grid(capacities []int, ratios []float64) []Config {
+	var configs []Config
+	for _, c := range capacities {
+		for _, r := range ratios {
+			p := int(float64(c) * r)
+			if p < 0 || p >= c || (p == 0 && r != 0) {
+				continue
+			}
+			configs = append(configs, Config{LRU: c - p, Probe: p})
+		}
+	}
+	return configs
+}
+
+// Simulate replays trace against a cache for each configuration and
+// returns the results in the order of configs.
+func Simulate[K comparable](trace []K, configs []Config) []Result {
+	return SimulateSized(trace, configs, nil)
+}
+
+// SimulateSized is like Simulate, but also reports the peak memory of each
+// configuration in bytes. size returns the bytes an entry for key takes;
+// it is measured with the cache's Sizer.
+func SimulateSized[K comparable](trace []K, configs []Config, size func(K) int) []Result {
+	results := make([]Result, len(configs))
+	for i, cfg := range configs {
+		results[i] = simulate(trace, cfg, size)
+	}
+	return results
+}
+
+// simulate replays trace against a single configuration. The cached values
+// are the entry sizes, so the Sizer reports them back.
+func simulate[K comparable](trace []K, cfg Config, size func(K) int) Result {
+	c := slrucache.NewSLRUCache[K, int](cfg.LRU, cfg.Probe)
+	if size != nil {
+		c.SetSizer(func(n int) int { return n })
+	}
+	r := Result{Config: cfg}
+	for _, k := range trace {
+		if c.Lookup(k) != nil {
+			r.Hits++
+			continue
+		}
+		r.Misses++
+		if size == nil {
+			c.Insert(k, 0)
+			continue
+		}
+		c.Insert(k, size(k))
+		r.Bytes = max(r.Bytes, c.Bytes())
+	}
+	if len(trace) > 0 {
+		r.HitRatio = float64(r.Hits) / float64(len(trace))
+	}
+	return r
+}
+
+// ReadKeys reads the looked up keys of a trace in the format read by
+// slrucache.ReadTrace, which includes plain key lists. Inserts and
+// removals are skipped since the simulation inserts after misses itself.
+func ReadKeys[K comparable](r io.Reader, parse func(string) (K, error)) ([]K, error) {
+	var keys []K
+	err := slrucache.ReadTrace(r, parse, func(rec slrucache.TraceRecord[K]) error {
+		if rec.Op == slrucache.TraceLookup {
+			keys = append(keys, rec.Key)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// Best returns the result with the highest hit ratio, preferring smaller
+// capacities on ties. It returns false if results is empty.
+func Best(results []Result) (Result, bool) {
+	if len(results) == 0 {
+		return Result{}, false
+	}
+	sorted := append([]Result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].HitRatio != sorted[j].HitRatio {
+			return sorted[i].HitRatio > sorted[j].HitRatio
+		}
+		return sorted[i].Capacity() < sorted[j].Capacity()
+	})
+	return sorted[0], true
+}
@@ -0,0 +1,75 @@
+package slrusim
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"slrucache"
+)
+
+// TestGrid tests the configuration grid.
+func TestGrid(t *testing.T) {
+	got := Grid([]int{10, 100}, []float64{0, 0.2, 1})
+	want := []Config{{10, 0}, {8, 2}, {100, 0}, {80, 20}}
+	if len(got) != len(want) {
+		t.Fatalf("got %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("config %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestSimulate tests hit counting and that larger caches do not lose.
+func TestSimulate(t *testing.T) {
+	// Keys come in pairs, so the second access promotes them
+	var trace []int
+	for i := 0; i < 500; i++ {
+		trace = append(trace, i%20, i%20)
+	}
+
+	results := Simulate(trace, []Config{{5, 5}, {20, 5}})
+	if r := results[1]; r.Misses != 20 || r.Hits != 980 || r.HitRatio != 0.98 {
+		t.Errorf("cyclic trace fitting the cache: %+v", r)
+	}
+	if results[0].Hits >= results[1].Hits {
+		t.Errorf("smaller cache should hit less: %+v", results)
+	}
+	if best, _ := Best(results); best.Config != (Config{20, 5}) {
+		t.Errorf("best %+v", best)
+	}
+}
+
+// TestSimulateSized tests that the peak entry bytes are reported; only the
+// probationary segment fills on a trace without repeated hits.
+func TestSimulateSized(t *testing.T) {
+	trace := []int{1, 2, 3, 4, 5, 1}
+	results := SimulateSized(trace, []Config{{2, 2}, {10, 10}}, func(k int) int { return 10 * k })
+	if results[0].Bytes != 90 || results[1].Bytes != 150 {
+		t.Errorf("bytes %d and %d", results[0].Bytes, results[1].Bytes)
+	}
+	if r := Simulate(trace, []Config{{2, 2}}); r[0].Bytes != 0 || r[0].Misses != results[0].Misses {
+		t.Errorf("unsized %+v", r[0])
+	}
+}
+
+// TestReadKeys tests reading the lookups of a recorded trace.
+func TestReadKeys(t *testing.T) {
+	var b strings.Builder
+	rec := slrucache.NewRecorder[int](&b)
+	c := slrucache.NewSLRUCache[int, int](2, 2)
+	c.SetRecorder(rec)
+	for i := 0; i < 4; i++ {
+		if c.Lookup(i) == nil {
+			c.Insert(i, i)
+		}
+	}
+	rec.Flush()
+
+	keys, err := ReadKeys(strings.NewReader(b.String()), strconv.Atoi)
+	if err != nil || len(keys) != 4 || keys[3] != 3 {
+		t.Errorf("keys %v, %v", keys, err)
+	}
+}
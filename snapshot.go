@@ -0,0 +1,163 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotVersion is the version of the snapshot format.
+const snapshotVersion = 1
+
+// snapshotHeader precedes the entries of a snapshot.
+type snapshotHeader struct {
+	Version int
+	LRU     int // lrulist size of the saved cache
+	Probe   int // probelist size of the saved cache
+	Count   int // number of entries following the header
+}
+
+// snapshotEntry is a saved cache entry.
+type snapshotEntry[K comparable, V any] struct {
+	Key       K
+	Value     V
+	Protected bool // entry was in lrulist
+	Priority  Priority
+	Expires   time.Time
+	Negative  bool
+}
+
+// Snapshot writes the cache contents to w using encoding/gob.
+// Keys, values and segment membership are saved in recency order, so
+// Restore can resume with a warm cache. K and V must be encodable by gob.
+func (c *SLRUCache[K, V]) Snapshot(w io.Writer) error {
+	c.mu.Lock()
+	c.drainReads()
+	entries := make([]snapshotEntry[K, V], 0, c.probelist.count+c.lrulist.count)
+	for _, l := range []*SLRUList[K, V]{c.probelist, c.lrulist} {
+		// from tail to head, so restoring by insertHead keeps the order
+		for n := l.tail; n >= 0; n = int(c.entries[n].prev) {
+			e := &c.entries[n]
+			if e.epoch != c.epoch {
+				// invalidated by InvalidateAll
+				continue
+			}
+			entries = append(entries, snapshotEntry[K, V]{
+				Key:       e.key,
+				Value:     e.value,
+				Protected: l == c.lrulist,
+				Priority:  e.prio,
+				Expires:   e.expires,
+				Negative:  e.negative,
+			})
+		}
+	}
+	header := snapshotHeader{
+		Version: snapshotVersion,
+		LRU:     c.snum,
+		Probe:   c.pnum,
+		Count:   len(entries),
+	}
+	c.mu.Unlock()
+
+	enc := gob.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return err
+	}
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore replaces the cache contents with a snapshot read from r.
+// Segment sizes may differ from the saved cache; if a segment does not fit,
+// its least recently used entries are dropped. Expired entries are skipped.
+// Callbacks are not called. On error the cache is left unchanged.
+func (c *SLRUCache[K, V]) Restore(r io.Reader) error {
+	dec := gob.NewDecoder(r)
+
+	var header snapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		return err
+	}
+	if header.Version != snapshotVersion {
+		return fmt.Errorf("slrucache: unsupported snapshot version %d", header.Version)
+	}
+	if header.Count < 0 {
+		return fmt.Errorf("slrucache: invalid snapshot entry count %d", header.Count)
+	}
+
+	var probe, lru []snapshotEntry[K, V]
+	for i := 0; i < header.Count; i++ {
+		var e snapshotEntry[K, V]
+		if err := dec.Decode(&e); err != nil {
+			return err
+		}
+		if e.Protected {
+			lru = append(lru, e)
+		} else {
+			probe = append(probe, e)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reset()
+	now := c.now()
+	for _, seg := range []struct {
+		l       *SLRUList[K, V]
+		size    int
+		entries []snapshotEntry[K, V]
+	}{{c.probelist, c.pnum, probe}, {c.lrulist, c.snum, lru}} {
+		if len(seg.entries) > seg.size {
+			seg.entries = seg.entries[len(seg.entries)-seg.size:]
+		}
+		for _, se := range seg.entries {
+			if !se.Expires.IsZero() && !now.Before(se.Expires) {
+				continue
+			}
+			if _, ok := c.find(se.Key); ok {
+				continue
+			}
+			n := c.freelist.removeTail()
+			e := &c.entries[n]
+			e.key = se.Key
+			e.value = se.Value
+			e.prio = clampPriority(se.Priority)
+			e.weight = 1
+			e.expires = se.Expires
+			e.negative = se.Negative
+			e.epoch = c.epoch
+			e.inserted = now
+			e.accessed = now
+			c.setIndex(se.Key, n)
+			seg.l.insertHead(n)
+			c.policyOf(seg.l).Inserted(seg.l, n)
+		}
+	}
+
+	return nil
+}
+
+// reset removes all entries without calling callbacks.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) reset() {
+	for _, l := range []*SLRUList[K, V]{c.probelist, c.lrulist} {
+		for l.count > 0 {
+			n := l.removeTail()
+			c.clearEntry(n)
+			c.freelist.insertHead(n)
+		}
+	}
+	c.resetIndex()
+	if c.victims != nil {
+		c.victims.reset()
+	}
+}
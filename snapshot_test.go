@@ -0,0 +1,82 @@
+package slrucache
+
+import (
+	"bytes"
+	"testing"
+)
+
+// listKeys returns the keys of l from head to tail.
+func listKeys(c *SLRUCache[string, string], l *SLRUList[string, string]) []string {
+	var keys []string
+	for n := l.head; n >= 0; n = int(c.entries[n].next) {
+		keys = append(keys, c.entries[n].key)
+	}
+	return keys
+}
+
+// TestSnapshotRestore tests that contents, segments and order survive a round trip.
+func TestSnapshotRestore(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 10, 0)
+	lookupN(c, 3, 5)
+	c.InsertWithPriority("9", "nine", PriorityHigh)
+
+	var buf bytes.Buffer
+	if err := c.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewSLRUCache[string, string](5, 5)
+	insertN(r, 3, 100)
+	if err := r.Restore(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalKeys(listKeys(c, c.probelist), listKeys(r, r.probelist)) ||
+		!equalKeys(listKeys(c, c.lrulist), listKeys(r, r.lrulist)) {
+		t.Error("restored lists differ")
+	}
+	if v := r.Lookup("9"); v == nil || *v != "nine" || r.entries[r.mapping["9"]].prio != PriorityHigh {
+		t.Error("restored entry differs")
+	}
+	if _, ok := r.mapping["100"]; ok || checkSLRUCacheSanity(r) {
+		t.Error("restore should replace previous contents")
+	}
+
+	// restore into a smaller cache keeps the most recent entries
+	s := NewSLRUCache[string, string](2, 2)
+	if err := s.Restore(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if checkListCount(s, 0, 2, 2, "restore into smaller cache") || checkSLRUCacheSanity(s) {
+		t.Fail()
+	}
+	if _, ok := s.mapping["7"]; !ok {
+		t.Error("most recent protected entry should be kept")
+	}
+}
+
+// TestRestoreInvalid tests that a broken snapshot leaves the cache unchanged.
+func TestRestoreInvalid(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 3, 0)
+	if err := c.Restore(bytes.NewReader([]byte("garbage"))); err == nil {
+		t.Error("expected error")
+	}
+	if len(c.mapping) != 3 {
+		t.Error("cache should be unchanged")
+	}
+}
+
+// equalKeys compares two key slices.
+func equalKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
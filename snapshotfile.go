@@ -0,0 +1,89 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// snapshotMagic identifies snapshot files written by SaveToFile.
+var snapshotMagic = [8]byte{'S', 'L', 'R', 'U', 'S', 'N', 'A', 'P'}
+
+// ErrInvalidSnapshot is returned by LoadFromFile for damaged or foreign files.
+var ErrInvalidSnapshot = errors.New("slrucache: invalid snapshot file")
+
+// SaveToFile writes a snapshot of the cache to path atomically.
+// The snapshot is written to a temporary file in the same directory,
+// synced and renamed into place, so readers see either the old or the
+// new file. The file carries a checksum verified by LoadFromFile.
+func (c *SLRUCache[K, V]) SaveToFile(path string) error {
+	var data bytes.Buffer
+	if err := c.Snapshot(&data); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	// header: magic, payload length, payload checksum
+	var header [16]byte
+	copy(header[:8], snapshotMagic[:])
+	binary.BigEndian.PutUint32(header[8:12], uint32(data.Len()))
+	binary.BigEndian.PutUint32(header[12:16], crc32.ChecksumIEEE(data.Bytes()))
+
+	_, err = f.Write(header[:])
+	if err == nil {
+		_, err = f.Write(data.Bytes())
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// LoadFromFile replaces the cache contents with a snapshot written by SaveToFile.
+// The file is validated before the cache is touched; on error the cache is unchanged.
+func (c *SLRUCache[K, V]) LoadFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var header [16]byte
+	if _, err := io.ReadFull(f, header[:]); err != nil {
+		return ErrInvalidSnapshot
+	}
+	if !bytes.Equal(header[:8], snapshotMagic[:]) {
+		return ErrInvalidSnapshot
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if uint32(len(data)) != binary.BigEndian.Uint32(header[8:12]) ||
+		crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[12:16]) {
+		return ErrInvalidSnapshot
+	}
+
+	return c.Restore(bytes.NewReader(data))
+}
@@ -0,0 +1,50 @@
+package slrucache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveLoadFile tests the file round trip and validation.
+func TestSaveLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snap")
+
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 8, 0)
+	lookupN(c, 2, 3)
+	if err := c.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewSLRUCache[string, string](5, 5)
+	if err := r.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if checkListCount(r, 5, 2, 3, "load from file") || checkSLRUCacheSanity(r) {
+		t.Fail()
+	}
+
+	// flip a payload byte
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.LoadFromFile(path); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("expected ErrInvalidSnapshot, got %v", err)
+	}
+	if len(r.mapping) != 5 {
+		t.Error("failed load should leave the cache unchanged")
+	}
+
+	// no temporary files are left behind
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp*"))
+	if len(files) != 0 {
+		t.Errorf("temporary files left: %v", files)
+	}
+}
@@ -0,0 +1,137 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Stats holds the operation counters of a cache.
+// Hits are broken down by the segment they occurred in: protected hits are
+// hits in the lrulist, probation hits are hits in the probelist which
+// usually lead to a promotion. Many probation hits relative to protected
+// hits indicate a protected segment too small for the working set.
+type Stats struct {
+	Hits            uint64 // lookups finding an entry, including negative entries
+	ProtectedHits   uint64 // hits in the protected segment
+	ProbationHits   uint64 // hits in the probationary segment
+	Misses          uint64 // lookups finding no entry
+	Inserts         uint64 // new entries
+	Promotions      uint64 // entries moved from probation to protected
+	Demotions       uint64 // protected victims moved back to probation
+	Evictions       uint64 // entries evicted for capacity
+	WeightEvictions uint64 // entries evicted for the weight limit, included in Evictions
+	VictimHits      uint64 // hits readmitted from the victim buffer, included in Hits
+	Rejections      uint64 // new keys rejected by the admission control
+	TierErrors      uint64 // failed writes of evicted entries to the second tier
+	Expirations     uint64 // expired entries dropped
+	Corruptions     uint64 // inconsistencies repaired in recovery mode
+
+	ProtectedBytes int64 // size of the values in the protected segment, see Sizer
+	ProbationBytes int64 // size of the values in the probationary segment
+}
+
+// Stats returns a copy of the current counters.
+func (c *SLRUCache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.currentStats()
+}
+
+// ResetStats sets all counters to zero and returns their values before.
+func (c *SLRUCache[K, V]) ResetStats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.currentStats()
+	c.stats = Stats{}
+	return s
+}
+
+// StatsSince returns the counters accumulated since prev was taken by
+// Stats, e.g. to compute the hit ratio of a reporting interval. The sizes
+// are the current ones. Counters reset by ResetStats in between are not
+// accounted for; a counter below its value in prev is reported as 0.
+func (c *SLRUCache[K, V]) StatsSince(prev Stats) Stats {
+	return c.Stats().sub(prev)
+}
+
+// currentStats returns the counters completed by the segment sizes.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) currentStats() Stats {
+	s := c.stats
+	s.ProtectedBytes = c.lrulist.bytes
+	s.ProbationBytes = c.probelist.bytes
+	return s
+}
+
+// add adds the counters of o to s.
+func (s *Stats) add(o Stats) {
+	s.Hits += o.Hits
+	s.ProtectedHits += o.ProtectedHits
+	s.ProbationHits += o.ProbationHits
+	s.Misses += o.Misses
+	s.Inserts += o.Inserts
+	s.Promotions += o.Promotions
+	s.Demotions += o.Demotions
+	s.Evictions += o.Evictions
+	s.WeightEvictions += o.WeightEvictions
+	s.VictimHits += o.VictimHits
+	s.Rejections += o.Rejections
+	s.TierErrors += o.TierErrors
+	s.Expirations += o.Expirations
+	s.Corruptions += o.Corruptions
+	s.ProtectedBytes += o.ProtectedBytes
+	s.ProbationBytes += o.ProbationBytes
+}
+
+// sub returns the counters accumulated since o was taken, clamped at 0
+// for counters reset meanwhile. The sizes are current values and taken
+// from s.
+func (s Stats) sub(o Stats) Stats {
+	return Stats{
+		Hits:            since(s.Hits, o.Hits),
+		ProtectedHits:   since(s.ProtectedHits, o.ProtectedHits),
+		ProbationHits:   since(s.ProbationHits, o.ProbationHits),
+		Misses:          since(s.Misses, o.Misses),
+		Inserts:         since(s.Inserts, o.Inserts),
+		Promotions:      since(s.Promotions, o.Promotions),
+		Demotions:       since(s.Demotions, o.Demotions),
+		Evictions:       since(s.Evictions, o.Evictions),
+		WeightEvictions: since(s.WeightEvictions, o.WeightEvictions),
+		VictimHits:      since(s.VictimHits, o.VictimHits),
+		Rejections:      since(s.Rejections, o.Rejections),
+		TierErrors:      since(s.TierErrors, o.TierErrors),
+		Expirations:     since(s.Expirations, o.Expirations),
+		Corruptions:     since(s.Corruptions, o.Corruptions),
+
+		ProtectedBytes: s.ProtectedBytes,
+		ProbationBytes: s.ProbationBytes,
+	}
+}
+
+// since returns cur - prev, or 0 if cur is less than prev.
+func since(cur, prev uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// HitRatio returns the fraction of lookups that were hits.
+func (s Stats) HitRatio() float64 {
+	return ratio(s.Hits, s.Hits+s.Misses)
+}
+
+// ProtectedHitRatio returns the fraction of lookups that hit the protected segment.
+func (s Stats) ProtectedHitRatio() float64 {
+	return ratio(s.ProtectedHits, s.Hits+s.Misses)
+}
+
+// ProbationHitRatio returns the fraction of lookups that hit the probationary segment.
+func (s Stats) ProbationHitRatio() float64 {
+	return ratio(s.ProbationHits, s.Hits+s.Misses)
+}
+
+// ratio returns a/b or 0 if b is 0.
+func ratio(a, b uint64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,61 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestStats tests the counters including the per-segment hits.
+func TestStats(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 10, 0)  // 0..4 evicted
+	lookupN(c, 8, 3)   // 3,4,10 miss, 5..9 probation hits
+	lookupN(c, 3, 5)   // 5,6,7 protected hits
+	lookupN(c, 2, 100) // misses
+
+	s := c.Stats()
+	want := Stats{
+		Hits:          8,
+		ProtectedHits: 3,
+		ProbationHits: 5,
+		Misses:        5,
+		Inserts:       10,
+		Promotions:    5,
+		Evictions:     5,
+	}
+	if s != want {
+		t.Errorf("unexpected stats %+v, want %+v", s, want)
+	}
+	if s.HitRatio() != 8.0/13 || s.ProtectedHitRatio() != 3.0/13 || s.ProbationHitRatio() != 5.0/13 {
+		t.Error("unexpected ratios")
+	}
+	if (Stats{}).HitRatio() != 0 {
+		t.Error("empty stats should have ratio 0")
+	}
+}
+
+// TestStatsSince tests interval deltas and resetting the counters.
+func TestStatsSince(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.Insert("a", "a")
+	c.Lookup("a")
+	prev := c.Stats()
+
+	c.Lookup("a")
+	c.Lookup("b")
+	if d := c.StatsSince(prev); d.Hits != 1 || d.Misses != 1 || d.Inserts != 0 || d.HitRatio() != 0.5 {
+		t.Errorf("interval stats %+v", d)
+	}
+
+	if s := c.ResetStats(); s.Hits != 2 || s.Inserts != 1 {
+		t.Errorf("ResetStats returned %+v", s)
+	}
+	if s := c.Stats(); s != (Stats{}) {
+		t.Errorf("stats after reset %+v", s)
+	}
+
+	// prev is newer than the reset counters
+	c.Lookup("b")
+	if d := c.StatsSince(prev); d.Hits != 0 || d.Misses != 1 {
+		t.Errorf("interval stats across a reset %+v", d)
+	}
+}
@@ -0,0 +1,62 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"context"
+	"sync"
+)
+
+// Store is a backing store holding the source of truth the cache fronts.
+// Implementations must be safe for concurrent use.
+type Store[K comparable, V any] interface {
+	// Get returns the value stored for key, or ErrNotFound.
+	Get(ctx context.Context, key K) (V, error)
+	// Put stores value for key.
+	Put(ctx context.Context, key K, value V) error
+}
+
+// writeThrough is a store written before caching.
+type writeThrough[K comparable, V any] struct {
+	store Store[K, V]
+	mu    sync.Mutex // orders writes to the store and the cache
+}
+
+// SetWriteThrough writes the values stored by Insert and its variants,
+// CompareAndSwap and Compute synchronously to s before caching them. If
+// the write fails the value is not cached: InsertE returns the error of
+// the store and InsertWithOptions false. These writes are serialized, so
+// they reach the store in the order they are cached; TryInsert writes only
+// entries that fit. Warm, Merge, Rename and Restore bypass the store, as
+// do negative entries and values loaded by a loader. Pass nil to stop
+// writing through.
+func (c *SLRUCache[K, V]) SetWriteThrough(s Store[K, V]) {
+	var wt *writeThrough[K, V]
+	if s != nil {
+		wt = &writeThrough[K, V]{store: s}
+	}
+	c.writeThrough.Store(wt)
+}
+
+// putUnlocked writes value for key to the store of wt with the mutex
+// released, so a slow store does not block other caches sharing the
+// mutex, and locks it again. Must be called with the mutex and wt.mu held.
+func (c *SLRUCache[K, V]) putUnlocked(wt *writeThrough[K, V], key K, value V) error {
+	c.mu.Unlock()
+	err := wt.store.Put(context.Background(), key, value)
+	c.mu.Lock()
+	c.drainReads()
+	return err
+}
+
+// SetReadThrough loads misses of Get, GetE and GetOrCompute without loader
+// from s and caches the result. Concurrent misses of a key share one load
+// like in GetOrCompute, and keys missing in s are cached as negative
+// entries if a negative TTL is set. Cached values are not written back.
+// A loader set by SetLoader takes precedence. Pass nil to stop reading
+// through.
+func (c *SLRUCache[K, V]) SetReadThrough(s Store[K, V]) {
+	c.mu.Lock()
+	c.readThrough = s
+	c.mu.Unlock()
+}
@@ -0,0 +1,184 @@
+package slrucache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapStore is a Store backed by a map.
+type mapStore struct {
+	mu   sync.Mutex
+	m    map[string]string
+	err  error // error returned by all calls if set
+	gets int
+}
+
+// Get returns the stored value.
+func (s *mapStore) Get(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gets++
+	if s.err != nil {
+		return "", s.err
+	}
+	v, ok := s.m[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// Put stores the value.
+func (s *mapStore) Put(ctx context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.m[key] = value
+	return nil
+}
+
+// TestWriteThrough tests that inserts reach the store before the cache.
+func TestWriteThrough(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	s := &mapStore{m: map[string]string{}}
+	c.SetWriteThrough(s)
+
+	if err := c.InsertE("a", "1"); err != nil || s.m["a"] != "1" || c.Lookup("a") == nil {
+		t.Error("insert should be written and cached")
+	}
+	c.InsertNegative("n")
+	if _, ok := s.m["n"]; ok {
+		t.Error("negative entries should not be written")
+	}
+
+	s.err = errors.New("unavailable")
+	if err := c.InsertE("b", "1"); err != s.err || c.Lookup("b") != nil {
+		t.Errorf("failed write should not be cached: %v", err)
+	}
+	if c.InsertWithOptions("a", "2") || *c.Lookup("a") != "1" {
+		t.Error("failed write should not update the cache")
+	}
+
+	// loaded values are not written back
+	s.err = nil
+	c.SetLoader(func(ctx context.Context, key string) (string, error) { return "loaded", nil })
+	delete(s.m, "a")
+	c.Remove("a")
+	if v, err := c.GetOrCompute("a", nil); err != nil || v != "loaded" {
+		t.Fatal(v, err)
+	}
+	if _, ok := s.m["a"]; ok {
+		t.Error("loaded value should not be written")
+	}
+}
+
+// TestReadThrough tests that misses are loaded from the store once.
+func TestReadThrough(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	s := &mapStore{m: map[string]string{"a": "1"}}
+	c.SetReadThrough(s)
+	c.SetNegativeTTL(time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if v, ok := c.Get("a"); !ok || v != "1" {
+			t.Errorf("get %q %v", v, ok)
+		}
+		if _, err := c.GetE("missing"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("missing key: %v", err)
+		}
+	}
+	if s.gets != 2 {
+		t.Errorf("store read %d times, want 2", s.gets)
+	}
+	if st := c.Stats(); st.Misses != 2 {
+		t.Errorf("misses %d", st.Misses)
+	}
+
+	s.err = errors.New("unavailable")
+	if _, err := c.GetE("b"); err != s.err {
+		t.Errorf("store error: %v", err)
+	}
+}
+
+// TestWriteThroughTryInsert tests that TryInsert writes only entries that fit.
+func TestWriteThroughTryInsert(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	s := &mapStore{m: map[string]string{}}
+	c.SetWriteThrough(s)
+	c.Insert("a", "1")
+
+	if c.TryInsert("b", "1") {
+		t.Error("entry should not fit")
+	}
+	if _, ok := s.m["b"]; ok {
+		t.Error("dropped entry should not be written")
+	}
+	if !c.TryInsert("a", "2") || s.m["a"] != "2" {
+		t.Error("update should be written")
+	}
+}
+
+// blockingStore is a Store whose Put waits until release is closed.
+type blockingStore struct {
+	putting chan struct{}
+	release chan struct{}
+}
+
+// Get finds nothing.
+func (s *blockingStore) Get(ctx context.Context, key string) (string, error) {
+	return "", ErrNotFound
+}
+
+// Put reports the write and blocks until released.
+func (s *blockingStore) Put(ctx context.Context, key, value string) error {
+	s.putting <- struct{}{}
+	<-s.release
+	return nil
+}
+
+// TestWriteThroughUnlocked tests that a slow store does not block other caches sharing the mutex.
+func TestWriteThroughUnlocked(t *testing.T) {
+	for name, write := range map[string]func(c *SLRUCache[string, string]){
+		"TryInsert":      func(c *SLRUCache[string, string]) { c.TryInsert("a", "1") },
+		"CompareAndSwap": func(c *SLRUCache[string, string]) { c.CompareAndSwap("a", "0", "1") },
+		"Compute": func(c *SLRUCache[string, string]) {
+			c.Compute("a", func(string, bool) (string, bool) { return "1", true })
+		},
+	} {
+		c := NewSLRUCache[string, string](2, 2)
+		c.Insert("a", "0")
+		s := &blockingStore{putting: make(chan struct{}), release: make(chan struct{})}
+		c.SetWriteThrough(s)
+		other := NewSLRUCache[string, string](2, 2)
+		other.Insert("b", "1")
+
+		done := make(chan struct{})
+		go func() {
+			write(c)
+			close(done)
+		}()
+		<-s.putting
+
+		hit := make(chan bool, 1)
+		go func() { hit <- other.Lookup("b") != nil }()
+		select {
+		case ok := <-hit:
+			if !ok {
+				t.Errorf("%s: lookup should hit", name)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: lookup blocked by the store", name)
+		}
+
+		close(s.release)
+		<-done
+		if v := c.Lookup("a"); v == nil || *v != "1" {
+			t.Errorf("%s: written value should be cached", name)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"sync"
+)
+
+// StripedCache partitions keys by hash over independent SLRUCache stripes,
+// each with its own lock and lists, so operations on keys of different
+// stripes do not contend. Every stripe is a complete cache with a share of
+// the capacity; recency and eviction are tracked per stripe.
+type StripedCache[K comparable, V any] struct {
+	stripes []*SLRUCache[K, V]
+	hasher  Hasher[K]
+}
+
+// NewStripedCache creates a StripedCache with n stripes sharing the given
+// total sizes of the protected and probationary segments. Each stripe gets
+// at least one protected entry. Keys are routed by a DefaultHasher.
+func NewStripedCache[K comparable, V any](n int, lruEntries int, probeEntries int) *StripedCache[K, V] {
+	return NewStripedCacheHasher[K, V](n, lruEntries, probeEntries, NewDefaultHasher[K]())
+}
+
+// NewStripedCacheHasher is NewStripedCache routing keys by hasher.
+// The stripes use hasher as well, see SetHasher.
+func NewStripedCacheHasher[K comparable, V any](n int, lruEntries int, probeEntries int, hasher Hasher[K]) *StripedCache[K, V] {
+	if n < 1 {
+		n = 1
+	}
+
+	s := &StripedCache[K, V]{
+		stripes: make([]*SLRUCache[K, V], n),
+		hasher:  hasher,
+	}
+	for i := range s.stripes {
+		lru := max(1, share(lruEntries, n, i))
+		c := NewSLRUCache[K, V](lru, share(probeEntries, n, i))
+		c.mu = new(sync.RWMutex)
+		c.hasher = hasher
+		s.stripes[i] = c
+	}
+	return s
+}
+
+// share returns the part of total given to stripe i of n.
+func share(total, n, i int) int {
+	part := total / n
+	if i < total%n {
+		part++
+	}
+	return part
+}
+
+// Stripe returns the stripe responsible for key, giving access to the
+// complete SLRUCache API for that key.
+func (s *StripedCache[K, V]) Stripe(key K) *SLRUCache[K, V] {
+	return s.stripes[s.hasher.Hash(key)%uint64(len(s.stripes))]
+}
+
+// Stripes returns all stripes.
+func (s *StripedCache[K, V]) Stripes() []*SLRUCache[K, V] {
+	return s.stripes
+}
+
+// Lookup returns a pointer to the value for key, see SLRUCache.Lookup.
+func (s *StripedCache[K, V]) Lookup(key K) *V {
+	return s.Stripe(key).Lookup(key)
+}
+
+// Get returns a copy of the value for key, see SLRUCache.Get.
+func (s *StripedCache[K, V]) Get(key K) (V, bool) {
+	return s.Stripe(key).Get(key)
+}
+
+// Insert adds or updates a key-value pair, see SLRUCache.Insert.
+func (s *StripedCache[K, V]) Insert(key K, value V) {
+	s.Stripe(key).Insert(key, value)
+}
+
+// Remove deletes key, see SLRUCache.Remove.
+func (s *StripedCache[K, V]) Remove(key K) bool {
+	return s.Stripe(key).Remove(key)
+}
+
+// Len returns the number of cached entries over all stripes.
+func (s *StripedCache[K, V]) Len() int {
+	n := 0
+	for _, c := range s.stripes {
+		c.mu.RLock()
+		n += c.indexLen()
+		c.mu.RUnlock()
+	}
+	return n
+}
+
+// Stats returns the sum of the counters of all stripes.
+func (s *StripedCache[K, V]) Stats() Stats {
+	var sum Stats
+	for _, c := range s.stripes {
+		sum.add(c.Stats())
+	}
+	return sum
+}
@@ -0,0 +1,67 @@
+package slrucache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestStripedCache tests capacity sharing and key routing.
+func TestStripedCache(t *testing.T) {
+	s := NewStripedCache[string, string](4, 40, 40)
+	total := 0
+	for _, c := range s.Stripes() {
+		total += c.cnum
+		if c.mu == &mutex {
+			t.Error("stripes should have their own lock")
+		}
+	}
+	if total != 80 {
+		t.Errorf("stripes should share the capacity, got %d", total)
+	}
+
+	for i := 0; i < 8; i++ {
+		k := strconv.Itoa(i)
+		s.Insert(k, k)
+		if s.Stripe(k) != s.Stripe(k) {
+			t.Fatal("routing should be stable")
+		}
+	}
+	if v, ok := s.Get("3"); !ok || v != "3" {
+		t.Error("entry should be found in its stripe")
+	}
+	if !s.Remove("3") || s.Lookup("3") != nil {
+		t.Error("entry should be removed")
+	}
+	if s.Stats().Inserts != 8 {
+		t.Error("stats should be summed over stripes")
+	}
+}
+
+// TestStripedCacheParallel runs parallel operations, meant for -race.
+func TestStripedCacheParallel(t *testing.T) {
+	s := NewStripedCache[int, int](8, 100, 100)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				k := (i * (g + 1)) % 300
+				if _, ok := s.Get(k); !ok {
+					s.Insert(k, k)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if s.Len() > 200 {
+		t.Error("striped cache exceeds its capacity")
+	}
+	for _, c := range s.Stripes() {
+		if err := c.Verify(); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// Tier is a secondary store chained behind the cache, e.g. a disk store,
+// Redis or memcached. The cache populates it with evicted entries, consults
+// it on a miss before declaring a miss and deletes removed keys from it.
+// Implementations must be safe for concurrent use.
+type Tier[K comparable, V any] interface {
+	// Get returns the value stored for key and whether it was found.
+	Get(key K) (V, bool, error)
+	// Set stores value for key.
+	Set(key K, value V) error
+	// Delete removes key from the store.
+	Delete(key K) error
+}
+
+// TierFuncs adapts a set of functions, e.g. calls of a Redis or memcached
+// client, to the Tier interface. Nil functions are no-ops or misses.
+type TierFuncs[K comparable, V any] struct {
+	GetFunc    func(key K) (V, bool, error)
+	SetFunc    func(key K, value V) error
+	DeleteFunc func(key K) error
+}
+
+// Get calls GetFunc.
+func (t TierFuncs[K, V]) Get(key K) (V, bool, error) {
+	if t.GetFunc == nil {
+		var zero V
+		return zero, false, nil
+	}
+	return t.GetFunc(key)
+}
+
+// Set calls SetFunc.
+func (t TierFuncs[K, V]) Set(key K, value V) error {
+	if t.SetFunc == nil {
+		return nil
+	}
+	return t.SetFunc(key, value)
+}
+
+// Delete calls DeleteFunc.
+func (t TierFuncs[K, V]) Delete(key K) error {
+	if t.DeleteFunc == nil {
+		return nil
+	}
+	return t.DeleteFunc(key)
+}
+
+// SetTier chains a second tier behind the cache. Entries evicted from
+// memory are written to the tier after the mutex has been released, a
+// Lookup missing in memory reloads the entry from the tier into the
+// probelist before declaring a miss, and Remove deletes the key from the
+// tier as well. Expired and negative entries are not written. Failed
+// writes are counted in Stats.TierErrors, failed reads treated as misses.
+// Pass nil to detach the tier.
+func (c *SLRUCache[K, V]) SetTier(t Tier[K, V]) {
+	c.mu.Lock()
+	c.tier = t
+	c.mu.Unlock()
+}
+
+// SetOverflow attaches a disk store as second tier, see SetTier.
+// Pass nil to detach it.
+func (c *SLRUCache[K, V]) SetOverflow(d *DiskStore[K, V]) {
+	if d == nil {
+		c.SetTier(nil)
+		return
+	}
+	c.SetTier(d)
+}
+
+// addSpill queues the entry at index n for the second tier.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) addSpill(n int) {
+	e := &c.entries[n]
+	if e.negative || c.expired(n) {
+		return
+	}
+	c.spilled = append(c.spilled, KV[K, V]{Key: e.key, Value: e.value})
+}
+
+// spill writes the evicted entries to tier t and counts the failed writes.
+// Must be called without the mutex held.
+func (c *SLRUCache[K, V]) spill(t Tier[K, V], spilled []KV[K, V]) {
+	var failed uint64
+	for _, kv := range spilled {
+		if err := t.Set(kv.Key, kv.Value); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		c.mu.Lock()
+		c.stats.TierErrors += failed
+		c.mu.Unlock()
+	}
+}
+
+// reload copies the entry for key from the second tier into the cache.
+// Returns a pointer to the cached value or nil if the tier has no entry.
+// If out is not nil, it receives the results of the hit.
+func (c *SLRUCache[K, V]) reload(t Tier[K, V], key K, out *lookupOut[V]) *V {
+	v, ok, err := t.Get(key)
+	if !ok || err != nil {
+		return nil
+	}
+
+	c.insert(key, v, entryOptions{stored: true})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n, ok := c.find(key); ok {
+		if out != nil {
+			out.segment = c.segmentOf(n)
+		}
+		return c.result(n, out)
+	}
+	return nil
+}
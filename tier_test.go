@@ -0,0 +1,174 @@
+package slrucache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDiskStore tests the store operations and reopening.
+func TestDiskStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	d, err := OpenDiskStore[string, int](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Set("a", 1)
+	d.Set("b", 2)
+	d.Set("a", 3)
+	d.Delete("b")
+	d.Close()
+
+	d, err = OpenDiskStore[string, int](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if v, ok, err := d.Get("a"); !ok || err != nil || v != 3 {
+		t.Errorf("expected a=3, got %v %v %v", v, ok, err)
+	}
+	if _, ok, _ := d.Get("b"); ok {
+		t.Error("deleted key should be gone")
+	}
+
+	if err := d.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, _ := d.Get("a"); !ok || v != 3 || d.Len() != 1 || d.dead != 0 {
+		t.Error("compaction lost data")
+	}
+
+	d.Close()
+	if err := d.Set("c", 4); !errors.Is(err, ErrClosed) {
+		t.Errorf("Set on closed store: %v", err)
+	}
+}
+
+// TestDiskStoreCorruption tests that only a torn last record is dropped.
+func TestDiskStoreCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	d, err := OpenDiskStore[string, int](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Set("a", 1)
+	d.Set("b", 2)
+	d.Set("c", 3)
+	offB, offC, size := d.index["b"], d.index["c"], d.size
+	d.Close()
+
+	corrupt := func(off int64) {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := make([]byte, 1)
+		f.ReadAt(b, off+10)
+		f.WriteAt([]byte{b[0] ^ 0xff}, off+10)
+		f.Close()
+	}
+
+	corrupt(offB)
+	if _, err := OpenDiskStore[string, int](path); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("corrupt middle record should fail open: %v", err)
+	}
+	if fi, _ := os.Stat(path); fi.Size() != size {
+		t.Error("file should be left unchanged")
+	}
+
+	corrupt(offB)
+	corrupt(offC)
+	d, err = OpenDiskStore[string, int](path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if v, ok, _ := d.Get("b"); !ok || v != 2 || d.Len() != 2 || d.size != offC {
+		t.Error("corrupt last record should be truncated")
+	}
+}
+
+// TestOverflow tests spilling evicted entries and reloading them on miss.
+func TestOverflow(t *testing.T) {
+	d, err := OpenDiskStore[string, string](filepath.Join(t.TempDir(), "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetOverflow(d)
+	insertN(c, 6, 0)
+	if d.Len() != 3 {
+		t.Errorf("expected 3 spilled entries, got %d", d.Len())
+	}
+
+	if v := c.Lookup("0"); v == nil || *v != "0" {
+		t.Error("spilled entry should be reloaded")
+	}
+	if _, ok := c.mapping["0"]; !ok || checkSLRUCacheSanity(c) {
+		t.Error("reloaded entry should be cached")
+	}
+	// reloading evicted another entry into the store
+	if d.Len() != 4 {
+		t.Errorf("expected 4 stored entries, got %d", d.Len())
+	}
+	c.Remove("0")
+	if _, ok, _ := d.Get("0"); ok || c.Lookup("0") != nil {
+		t.Error("removed entry should be deleted from the store")
+	}
+	if c.Lookup("missing") != nil {
+		t.Error("unknown key should miss")
+	}
+}
+
+// TestTierFuncs tests a function based tier standing in for a remote store.
+func TestTierFuncs(t *testing.T) {
+	remote := map[string]string{"r": "remote"}
+	c := NewSLRUCache[string, string](2, 2)
+	c.SetTier(TierFuncs[string, string]{
+		GetFunc: func(k string) (string, bool, error) {
+			v, ok := remote[k]
+			return v, ok, nil
+		},
+		SetFunc: func(k, v string) error {
+			remote[k] = v
+			return nil
+		},
+	})
+
+	if v := c.Lookup("r"); v == nil || *v != "remote" {
+		t.Error("remote entry should be loaded")
+	}
+	insertN(c, 3, 0)
+	if remote["r"] != "remote" || remote["0"] != "0" || len(remote) != 2 {
+		t.Errorf("evicted entries should be written to the tier: %v", remote)
+	}
+	c.Remove("r") // nil DeleteFunc is a no-op
+}
+
+// TestTierSpillUnlocked tests that evicted entries are written after unlock and failures counted.
+func TestTierSpillUnlocked(t *testing.T) {
+	c := NewSLRUCache[string, string](1, 1)
+	locked, writes := false, 0
+	c.SetTier(TierFuncs[string, string]{
+		SetFunc: func(key, value string) error {
+			if !c.mu.TryLock() {
+				locked = true
+			} else {
+				c.mu.Unlock()
+			}
+			writes++
+			return errors.New("unavailable")
+		},
+	})
+
+	insertN(c, 3, 0)
+	if locked || writes != 2 {
+		t.Errorf("writes %d, under lock %v", writes, locked)
+	}
+	if s := c.Stats(); s.TierErrors != 2 {
+		t.Errorf("tier errors %d", s.TierErrors)
+	}
+}
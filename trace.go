@@ -0,0 +1,149 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// TraceOp is the operation of a trace record.
+type TraceOp byte
+
+// Trace operations.
+const (
+	TraceLookup TraceOp = 'L' // lookup of any kind, Lookup, Get, ...
+	TraceInsert TraceOp = 'I' // insert or update
+	TraceRemove TraceOp = 'R' // explicit removal
+)
+
+// Recorder writes the accesses of a cache to a trace, one line per access
+// consisting of the operation, a tab and the key formatted with %v. Keys
+// must not format to text containing newlines. Recorders are safe for
+// concurrent use; writes are buffered, call Flush when done.
+type Recorder[K comparable] struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	err error
+}
+
+// NewRecorder creates a Recorder writing to w.
+func NewRecorder[K comparable](w io.Writer) *Recorder[K] {
+	return &Recorder[K]{w: bufio.NewWriter(w)}
+}
+
+// Record appends a record. Errors are kept and reported by Flush.
+func (r *Recorder[K]) Record(op TraceOp, key K) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		_, r.err = fmt.Fprintf(r.w, "%c\t%v\n", op, key)
+	}
+}
+
+// Flush writes buffered records and returns the first error that occurred.
+func (r *Recorder[K]) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// SetRecorder starts recording lookups, inserts and removals to r. Pass
+// nil to stop recording. Records are written before the operation takes
+// the cache lock, so concurrent operations may be recorded in a slightly
+// different order than they are applied.
+func (c *SLRUCache[K, V]) SetRecorder(r *Recorder[K]) {
+	c.recorder.Store(r)
+}
+
+// record writes a trace record if a recorder is set.
+func (c *SLRUCache[K, V]) record(op TraceOp, key K) {
+	if r := c.recorder.Load(); r != nil {
+		r.Record(op, key)
+	}
+}
+
+// TraceRecord is a single access read from a trace.
+type TraceRecord[K comparable] struct {
+	Op  TraceOp
+	Key K
+}
+
+// ReadTrace reads a trace written by a Recorder and calls fn for each
+// record. parse converts the key text back into a key. Lines holding only
+// a key are read as lookups, so plain key lists work as traces too.
+func ReadTrace[K comparable](r io.Reader, parse func(string) (K, error), fn func(TraceRecord[K]) error) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		op := TraceLookup
+		if len(text) >= 2 && text[1] == '\t' && strings.IndexByte("LIR", text[0]) >= 0 {
+			op, text = TraceOp(text[0]), text[2:]
+		}
+		key, err := parse(text)
+		if err != nil {
+			return fmt.Errorf("trace line %d: %w", line, err)
+		}
+		if err := fn(TraceRecord[K]{Op: op, Key: key}); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+// Replayer feeds recorded traces into a cache, for example to tune sizes
+// and policies offline with production access patterns.
+type Replayer[K comparable, V any] struct {
+	// Parse converts the key text of a record into a key.
+	Parse func(string) (K, error)
+	// Value returns the value inserted for key, the zero value if nil.
+	Value func(key K) V
+	// FillMisses inserts keys after lookup misses, for traces of caches
+	// whose inserts were not recorded, such as plain key lists.
+	FillMisses bool
+}
+
+// Replay applies the trace read from r to c and returns the counters
+// accumulated by c during the replay.
+func (p *Replayer[K, V]) Replay(r io.Reader, c *SLRUCache[K, V]) (Stats, error) {
+	before := c.Stats()
+
+	err := ReadTrace(r, p.Parse, func(rec TraceRecord[K]) error {
+		switch rec.Op {
+		case TraceLookup:
+			if c.Lookup(rec.Key) == nil && p.FillMisses {
+				c.Insert(rec.Key, p.value(rec.Key))
+			}
+		case TraceInsert:
+			c.Insert(rec.Key, p.value(rec.Key))
+		case TraceRemove:
+			c.Remove(rec.Key)
+		}
+		return nil
+	})
+
+	return c.Stats().sub(before), err
+}
+
+// value returns the value to insert for key.
+func (p *Replayer[K, V]) value(key K) V {
+	if p.Value == nil {
+		var zero V
+		return zero
+	}
+	return p.Value(key)
+}
+
+// ParseStringKey is a Replayer.Parse function for string keys.
+func ParseStringKey(s string) (string, error) {
+	return s, nil
+}
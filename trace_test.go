@@ -0,0 +1,57 @@
+package slrucache
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRecordReplay tests that replaying a recorded trace reproduces the
+// statistics of the recorded cache.
+func TestRecordReplay(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder[string](&buf)
+
+	c := NewSLRUCache[string, string](3, 3)
+	c.SetRecorder(rec)
+	for i := 0; i < 50; i++ {
+		k := strconv.Itoa(i % 7)
+		if c.Lookup(k) == nil {
+			c.Insert(k, k)
+		}
+		if i%10 == 0 {
+			c.Remove(k)
+		}
+	}
+	c.SetRecorder(nil)
+	c.Insert("unrecorded", "")
+	if err := rec.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "L\t0\nI\t0\nR\t0\n") {
+		t.Fatalf("unexpected trace start %q", buf.String()[:12])
+	}
+
+	p := Replayer[string, string]{Parse: ParseStringKey}
+	stats, err := p.Replay(bytes.NewReader(buf.Bytes()), NewSLRUCache[string, string](3, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := c.Stats(); stats.Hits != want.Hits || stats.Misses != want.Misses || stats.Inserts != want.Inserts-1 {
+		t.Errorf("replay stats %+v, recorded %+v", stats, want)
+	}
+}
+
+// TestReplayKeyList tests replaying a plain key list with FillMisses.
+func TestReplayKeyList(t *testing.T) {
+	p := Replayer[int, int]{Parse: strconv.Atoi, FillMisses: true}
+	stats, err := p.Replay(strings.NewReader("1\n2\n1\n1\n"), NewSLRUCache[int, int](2, 2))
+	if err != nil || stats.Hits != 2 || stats.Misses != 2 || stats.Inserts != 2 {
+		t.Errorf("stats %+v, %v", stats, err)
+	}
+
+	if _, err := p.Replay(strings.NewReader("1\nx\n"), NewSLRUCache[int, int](2, 2)); err == nil {
+		t.Error("parse error expected")
+	}
+}
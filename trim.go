@@ -0,0 +1,81 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// RemoveOldest evicts the current victim and returns its key and value.
+// The probelist victim goes first, the lrulist victim only if the
+// probelist is empty or pinned completely. The eviction is reported like
+// any other. Returns false if there is no unpinned entry.
+func (c *SLRUCache[K, V]) RemoveOldest() (K, V, bool) {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+
+	key, value, seg := c.removeOldest()
+	hooks, evicted, removeCb := c.hooks, c.takeEvicted(), c.removeCb
+	c.mu.Unlock()
+
+	// The value is handed to the caller, not to the recycler
+	c.deliverEvicted(hooks, nil, evicted)
+	if seg == SegmentProtected {
+		c.deliverRemoved(removeCb, key)
+	}
+	return key, value, seg != SegmentNone
+}
+
+// removeOldest evicts the current victim and returns its key, value and
+// the segment it was evicted from, SegmentNone if nothing was evicted.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) removeOldest() (K, V, Segment) {
+	for _, seg := range []Segment{SegmentProbation, SegmentProtected} {
+		l := c.segmentList(seg)
+		n := c.victim(l)
+		if n == SLRU_EOF {
+			continue
+		}
+
+		key, value := c.entries[n].key, c.entries[n].value
+		c.evictEntry(l, n, "RemoveOldest")
+		c.freelist.insertHead(n)
+		return key, value, seg
+	}
+
+	var zeroK K
+	var zeroV V
+	return zeroK, zeroV, SegmentNone
+}
+
+// TrimTo evicts victims until at most n entries remain and returns the
+// number of evicted entries. Pinned entries are kept, so more than n
+// entries may remain. Evictions are reported like any other.
+func (c *SLRUCache[K, V]) TrimTo(n int) int {
+	c.mu.Lock()
+	count := c.indexLen() - n
+	c.mu.Unlock()
+	return c.EvictN(count)
+}
+
+// EvictN evicts up to n victims, probation first, and returns the number
+// of evicted entries. Evictions are reported like any other.
+func (c *SLRUCache[K, V]) EvictN(n int) int {
+	c.mu.Lock()
+	defer c.recoverCorruption(true)
+
+	var removed []K
+	evicted := 0
+	for ; evicted < n; evicted++ {
+		key, _, seg := c.removeOldest()
+		if seg == SegmentNone {
+			break
+		}
+		if seg == SegmentProtected && c.removeCb != nil {
+			removed = append(removed, key)
+		}
+	}
+
+	hooks, recycle, kvs, removeCb := c.hooks, c.recycle, c.takeEvicted(), c.removeCb
+	c.mu.Unlock()
+
+	c.deliverEvicted(hooks, recycle, kvs)
+	c.deliverRemoved(removeCb, removed...)
+	return evicted
+}
@@ -0,0 +1,59 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestRemoveOldest tests that victims are removed probation first.
+func TestRemoveOldest(t *testing.T) {
+	c := NewSLRUCache[string, string](2, 2)
+	var removed []string
+	c.removeCb = func(k string) { removed = append(removed, k) }
+	insertN(c, 2, 0)
+	lookupN(c, 2, 0)
+	insertN(c, 1, 2)
+
+	var keys []string
+	for {
+		k, v, ok := c.RemoveOldest()
+		if !ok {
+			break
+		}
+		if k != v {
+			t.Error("value should match key")
+		}
+		keys = append(keys, k)
+	}
+
+	if !equalKeys(keys, []string{"2", "0", "1"}) {
+		t.Errorf("unexpected removal order %v", keys)
+	}
+	if !equalKeys(removed, []string{"0", "1"}) {
+		t.Errorf("remove callback should fire for protected entries, got %v", removed)
+	}
+	if c.Stats().Evictions != 3 || checkListCount(c, 4, 0, 0, "remove oldest") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
+
+// TestTrimTo tests trimming to a number of entries and evicting n entries.
+func TestTrimTo(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 5, 0)
+	lookupN(c, 3, 0)
+	insertN(c, 3, 5)
+	c.Pin("5")
+
+	if n := c.TrimTo(4); n != 4 {
+		t.Errorf("expected 4 evictions, got %d", n)
+	}
+	if _, ok := c.mapping["5"]; !ok || len(c.mapping) != 4 || c.lrulist.count != 3 {
+		t.Error("trim should evict probation first and skip pinned entries")
+	}
+	if n := c.EvictN(10); n != 3 {
+		t.Errorf("expected 3 evictions, got %d", n)
+	}
+	if c.TrimTo(5) != 0 || checkListCount(c, 9, 0, 1, "trim") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+}
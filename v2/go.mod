@@ -0,0 +1,7 @@
+module slrucache/v2
+
+go 1.22.2
+
+require slrucache v0.0.0
+
+replace slrucache => ../
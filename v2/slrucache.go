@@ -0,0 +1,83 @@
+// author: (c) Gunter Hartmann
+
+// Package slrucache is the v2 API of the segmented LRU cache.
+//
+// v2 replaces the pointer returning Lookup/Insert/Remove surface with
+// Get/Set/Delete, configures caches through Options, reports invalid
+// configurations as errors and describes caches by the Cache interface.
+// Breaking changes land here while the v1 module keeps its exported surface.
+// For now the v2 cache is a thin adapter over the v1 implementation, so both
+// share the same behavior and v1 users can migrate call site by call site.
+package slrucache
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "slrucache"
+)
+
+// ErrInvalidOptions is returned by New for unusable Options.
+var ErrInvalidOptions = errors.New("slrucache: invalid options")
+
+// Cache is the interface implemented by v2 caches.
+type Cache[K comparable, V any] interface {
+	// Get returns a copy of the value for key and whether it was cached.
+	Get(key K) (V, bool)
+	// Set adds or updates the value for key.
+	Set(key K, value V)
+	// Delete removes key and reports whether it was cached.
+	Delete(key K) bool
+}
+
+// Options configures a cache created by New.
+type Options struct {
+	Protected int  // size of the protected segment
+	Probation int  // size of the probationary segment, 0 for a plain LRU cache
+	Demotion  bool // demote protected victims into probation instead of evicting them
+}
+
+// SLRU is the v2 segmented LRU cache.
+type SLRU[K comparable, V any] struct {
+	c *v1.SLRUCache[K, V]
+}
+
+// New creates a cache configured by opts.
+func New[K comparable, V any](opts Options) (*SLRU[K, V], error) {
+	if opts.Protected < 1 || opts.Probation < 0 {
+		return nil, fmt.Errorf("%w: protected %d must be positive and probation %d not negative",
+			ErrInvalidOptions, opts.Protected, opts.Probation)
+	}
+
+	c := v1.NewSLRUCache[K, V](opts.Protected, opts.Probation)
+	c.SetDemotion(opts.Demotion)
+
+	return &SLRU[K, V]{c: c}, nil
+}
+
+// Wrap adapts an existing v1 cache to the v2 API.
+func Wrap[K comparable, V any](c *v1.SLRUCache[K, V]) *SLRU[K, V] {
+	return &SLRU[K, V]{c: c}
+}
+
+// Unwrap returns the underlying v1 cache.
+func (s *SLRU[K, V]) Unwrap() *v1.SLRUCache[K, V] {
+	return s.c
+}
+
+// Get returns a copy of the value for key and whether it was cached.
+func (s *SLRU[K, V]) Get(key K) (V, bool) {
+	return s.c.Get(key)
+}
+
+// Set adds or updates the value for key.
+func (s *SLRU[K, V]) Set(key K, value V) {
+	s.c.Insert(key, value)
+}
+
+// Delete removes key and reports whether it was cached.
+func (s *SLRU[K, V]) Delete(key K) bool {
+	return s.c.Remove(key)
+}
+
+var _ Cache[string, int] = (*SLRU[string, int])(nil)
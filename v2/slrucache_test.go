@@ -0,0 +1,45 @@
+package slrucache
+
+import (
+	"errors"
+	"testing"
+
+	v1 "slrucache"
+)
+
+// TestSLRUGetSetDelete tests the basic v2 operations.
+func TestSLRUGetSetDelete(t *testing.T) {
+	c, err := New[string, int](Options{Protected: 2, Probation: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Set("a", 1)
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Error("get after set failed")
+	}
+	if !c.Delete("a") || c.Delete("a") {
+		t.Error("delete failed")
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Error("get after delete should miss")
+	}
+}
+
+// TestNewInvalidOptions tests that invalid options are rejected.
+func TestNewInvalidOptions(t *testing.T) {
+	if _, err := New[string, int](Options{Protected: 0, Probation: 2}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestWrap tests that v1 and v2 views share the same cache.
+func TestWrap(t *testing.T) {
+	old := v1.NewSLRUCache[string, int](2, 2)
+	old.Insert("a", 1)
+
+	c := Wrap(old)
+	if v, ok := c.Get("a"); !ok || v != 1 || c.Unwrap() != old {
+		t.Error("wrapped cache should see v1 entries")
+	}
+}
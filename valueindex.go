@@ -0,0 +1,97 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// valueIndexer is a secondary index maintained by the cache along with its
+// key index. All methods are called with the mutex held.
+type valueIndexer[K comparable, V any] interface {
+	add(key K, value V)
+	remove(key K, value V)
+	reset()
+}
+
+// ValueIndex is a secondary index mapping an attribute of the cached values
+// to the keys of all entries sharing it, e.g. an account ID to all cached
+// sessions of the account. The cache updates it on every insert, update,
+// eviction and removal, so lookups need no scan.
+type ValueIndex[K comparable, V any, I comparable] struct {
+	cache *SLRUCache[K, V]
+	attr  func(V) I
+	keys  map[I]map[K]struct{} // guarded by the cache mutex
+}
+
+// NewValueIndex registers a secondary index on c deriving the attribute
+// of values by attr, which must be fast and must not call the cache.
+// The entries already cached are indexed immediately.
+func NewValueIndex[K comparable, V any, I comparable](c *SLRUCache[K, V], attr func(V) I) *ValueIndex[K, V, I] {
+	x := &ValueIndex[K, V, I]{
+		cache: c,
+		attr:  attr,
+		keys:  make(map[I]map[K]struct{}),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rangeIndex(func(key K, n int) {
+		x.add(key, c.entries[n].value)
+	})
+	c.valueIndexes = append(c.valueIndexes, x)
+	return x
+}
+
+// LookupByIndex returns the keys of all cached entries whose value has
+// attribute i, in no particular order. Expired and negative entries are
+// left out. Lookups do not count as hits or change the recency of entries.
+func (x *ValueIndex[K, V, I]) LookupByIndex(i I) []K {
+	c := x.cache
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var keys []K
+	for key := range x.keys[i] {
+		if n, ok := c.find(key); ok && !c.expired(n) && !c.entries[n].negative {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// add indexes key under the attribute of value.
+func (x *ValueIndex[K, V, I]) add(key K, value V) {
+	i := x.attr(value)
+	keys := x.keys[i]
+	if keys == nil {
+		keys = make(map[K]struct{})
+		x.keys[i] = keys
+	}
+	keys[key] = struct{}{}
+}
+
+// remove removes key from the attribute of value.
+func (x *ValueIndex[K, V, I]) remove(key K, value V) {
+	i := x.attr(value)
+	keys := x.keys[i]
+	delete(keys, key)
+	if len(keys) == 0 {
+		delete(x.keys, i)
+	}
+}
+
+// reset empties the index.
+func (x *ValueIndex[K, V, I]) reset() {
+	x.keys = make(map[I]map[K]struct{})
+}
+
+// setValue replaces the value of the entry at index n, keeping the
+// secondary indexes and the segment sizes up to date. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) setValue(n int, value V) {
+	e := &c.entries[n]
+	for _, x := range c.valueIndexes {
+		x.remove(e.key, e.value)
+		x.add(e.key, value)
+	}
+	if l := c.listOf(n); l != nil && l.sizer != nil {
+		l.bytes += int64(l.sizer(value) - l.sizer(e.value))
+	}
+	e.value = value
+}
@@ -0,0 +1,47 @@
+package slrucache
+
+import (
+	"slices"
+	"testing"
+)
+
+type testSession struct {
+	account string
+	id      int
+}
+
+// TestValueIndex tests that the secondary index follows inserts, updates,
+// evictions and removals.
+func TestValueIndex(t *testing.T) {
+	c := NewSLRUCache[int, testSession](2, 3)
+	c.Insert(1, testSession{"alice", 1})
+	x := NewValueIndex(c, func(s testSession) string { return s.account })
+
+	c.Insert(2, testSession{"alice", 2})
+	c.Insert(3, testSession{"bob", 3})
+	keys := x.LookupByIndex("alice")
+	slices.Sort(keys)
+	if !slices.Equal(keys, []int{1, 2}) {
+		t.Errorf("alice has keys %v, want [1 2]", keys)
+	}
+
+	c.Insert(2, testSession{"bob", 2})
+	c.Remove(3)
+	if keys := x.LookupByIndex("bob"); !slices.Equal(keys, []int{2}) {
+		t.Errorf("bob has keys %v, want [2]", keys)
+	}
+
+	// Evict 1 and 2 from the probelist
+	for i := 4; i <= 6; i++ {
+		c.Insert(i, testSession{"carol", i})
+	}
+	if keys := x.LookupByIndex("alice"); len(keys) != 0 {
+		t.Errorf("evicted keys %v still indexed", keys)
+	}
+	if n := len(x.LookupByIndex("carol")); n != 3 {
+		t.Errorf("carol has %d keys, want 3", n)
+	}
+	if len(x.keys) != 1 {
+		t.Errorf("index holds %d attributes, want 1", len(x.keys))
+	}
+}
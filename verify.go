@@ -0,0 +1,148 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Problem is a single inconsistency found by VerifyReport.
+type Problem struct {
+	List  string // freelist, probelist, lrulist or mapping
+	Index int    // entry index, -1 if the problem concerns the whole list
+	Msg   string
+}
+
+// Error implements the error interface.
+func (p Problem) Error() string {
+	if p.Index < 0 {
+		return fmt.Sprintf("%s: %s", p.List, p.Msg)
+	}
+	return fmt.Sprintf("%s: entry %d: %s", p.List, p.Index, p.Msg)
+}
+
+// Verify checks the internal invariants of the cache and returns an error
+// joining all problems found, or nil if the cache is consistent.
+func (c *SLRUCache[K, V]) Verify() error {
+	problems := c.VerifyReport()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	errs := make([]error, len(problems))
+	for i, p := range problems {
+		errs[i] = p
+	}
+	return errors.Join(errs...)
+}
+
+// VerifyReport checks the internal invariants of the cache and returns
+// all problems found. The result is empty if the cache is consistent.
+func (c *SLRUCache[K, V]) VerifyReport() []Problem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.verify()
+}
+
+// verify checks list links, list references, counts and the mapping.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) verify() []Problem {
+	var problems []Problem
+
+	failure := func(list string, n int, msg string) {
+		problems = append(problems, Problem{List: list, Index: n, Msg: msg})
+	}
+
+	walkList := func(name string, l *SLRUList[K, V]) {
+		n := l.head
+		ln := n
+		entries := *l.entries
+
+		var prios [priorityLevels]int
+		var weight, bytes int64
+		steps := 0
+
+		for n >= 0 {
+			if n >= len(entries) {
+				failure(name, n, "index out of range")
+				return
+			}
+			if steps++; steps > len(entries) {
+				failure(name, -1, "cycle detected")
+				return
+			}
+			e := entries[n]
+
+			if e.prev >= 0 && int(entries[e.prev].next) != n {
+				failure(name, n, "prev link failure")
+			}
+			if e.next >= 0 && int(entries[e.next].prev) != n {
+				failure(name, n, "next link failure")
+			}
+			if e.tag == 0 {
+				failure(name, n, "missing list tag")
+			} else if e.tag != l.tag {
+				failure(name, n, "foreign list tag")
+			}
+
+			prios[e.prio.level()]++
+			weight += int64(e.weight)
+			if l.sizer != nil {
+				bytes += int64(l.sizer(e.value))
+			}
+			ln = n
+			n = int(e.next)
+		}
+
+		if steps != l.count {
+			failure(name, -1, fmt.Sprintf("count %d does not match %d linked entries", l.count, steps))
+		}
+		if l.prios != prios {
+			failure(name, -1, "priority count mismatch")
+		}
+		if l.weight != weight {
+			failure(name, -1, "weight mismatch")
+		}
+		if l.bytes != bytes {
+			failure(name, -1, "size mismatch")
+		}
+		if l.tail != ln {
+			failure(name, -1, "tail reference mismatch")
+		}
+	}
+
+	walkList("freelist", c.freelist)
+	walkList("probelist", c.probelist)
+	walkList("lrulist", c.lrulist)
+
+	if c.freelist.count > c.cnum {
+		failure("freelist", -1, "size overflow")
+	}
+	if c.probelist.count > c.pnum {
+		failure("probelist", -1, "size overflow")
+	}
+	if c.lrulist.count > c.snum {
+		failure("lrulist", -1, "size overflow")
+	}
+
+	c.rangeIndex(func(k K, n int) {
+		switch {
+		case n < 0 || n >= len(c.entries):
+			failure("mapping", n, "index out of range")
+		case c.entries[n].key != k:
+			failure("mapping", n, "key mismatch")
+		case c.segmentOf(n) == SegmentNone:
+			failure("mapping", n, "entry not in a segment")
+		default:
+			if m, _ := c.find(k); m != n {
+				failure("mapping", n, "key not reachable")
+			}
+		}
+	})
+	if c.indexLen() != c.probelist.count+c.lrulist.count {
+		failure("mapping", -1, fmt.Sprintf("%d keys for %d entries in segments", c.indexLen(), c.probelist.count+c.lrulist.count))
+	}
+
+	return problems
+}
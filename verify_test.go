@@ -0,0 +1,42 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestVerify tests that a consistent cache reports no problems.
+func TestVerify(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 8, 0)
+	lookupN(c, 3, 0)
+	c.Remove("5")
+
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+	if len(c.VerifyReport()) != 0 {
+		t.Error("no problems expected")
+	}
+}
+
+// TestVerifyReport tests that broken links and mapping entries are reported.
+func TestVerifyReport(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	insertN(c, 4, 0)
+
+	n := c.mapping["1"]
+	c.entries[n].next = SLRU_EOF
+	c.mapping["x"] = n
+
+	problems := c.VerifyReport()
+	lists := map[string]bool{}
+	for _, p := range problems {
+		lists[p.List] = true
+	}
+	if !lists["probelist"] || !lists["mapping"] {
+		t.Errorf("expected probelist and mapping problems, got %v", problems)
+	}
+	if c.Verify() == nil {
+		t.Error("Verify should fail")
+	}
+}
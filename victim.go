@@ -0,0 +1,122 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"time"
+)
+
+// victimEntry is an entry retained by the victim buffer.
+type victimEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+	used    bool
+}
+
+// victimBuffer retains the last evicted entries in a ring.
+type victimBuffer[K comparable, V any] struct {
+	ring  []victimEntry[K, V]
+	next  int       // ring position of the next entry
+	index map[K]int // ring position by key
+}
+
+// SetVictimBuffer retains the last n entries evicted for capacity in a
+// small buffer. A lookup missing the cache but finding the key in the
+// buffer re-admits the entry directly into the protected segment and
+// reports a hit, which reduces the cost of marginal evictions. Such hits
+// are counted in Stats.VictimHits. Removed, expired and negative entries
+// are not retained. 0 disables the buffer.
+func (c *SLRUCache[K, V]) SetVictimBuffer(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n <= 0 {
+		c.victims = nil
+		return
+	}
+	c.victims = &victimBuffer[K, V]{
+		ring:  make([]victimEntry[K, V], n),
+		index: make(map[K]int, n),
+	}
+}
+
+// add retains an evicted entry, dropping the oldest one if full. Returns
+// the value of the dropped entry and whether one was dropped.
+func (b *victimBuffer[K, V]) add(key K, value V, expires time.Time) (V, bool) {
+	var dropped V
+	b.remove(key)
+	old := &b.ring[b.next]
+	used := old.used
+	if used {
+		dropped = old.value
+		delete(b.index, old.key)
+	}
+	b.ring[b.next] = victimEntry[K, V]{key: key, value: value, expires: expires, used: true}
+	b.index[key] = b.next
+	b.next = (b.next + 1) % len(b.ring)
+	return dropped, used
+}
+
+// take removes and returns the entry for key.
+func (b *victimBuffer[K, V]) take(key K) (victimEntry[K, V], bool) {
+	i, ok := b.index[key]
+	if !ok {
+		return victimEntry[K, V]{}, false
+	}
+	e := b.ring[i]
+	b.remove(key)
+	return e, true
+}
+
+// remove drops the entry for key.
+func (b *victimBuffer[K, V]) remove(key K) {
+	if i, ok := b.index[key]; ok {
+		b.ring[i] = victimEntry[K, V]{}
+		delete(b.index, key)
+	}
+}
+
+// reset drops all entries.
+func (b *victimBuffer[K, V]) reset() {
+	clear(b.ring)
+	clear(b.index)
+}
+
+// readmit moves the entry for key from the victim buffer to the head of
+// the lrulist and returns its index. Returns false if the buffer has no
+// live entry for key or no room could be made in the lrulist.
+// Must be called with the mutex held.
+func (c *SLRUCache[K, V]) readmit(key K) (int, bool) {
+	v, ok := c.victims.take(key)
+	if !ok || !v.expires.IsZero() && !c.now().Before(v.expires) {
+		return SLRU_EOF, false
+	}
+
+	if c.lrulist.count >= c.snum {
+		lt := c.victim(c.lrulist)
+		if lt == SLRU_EOF || !c.vacateProtected(lt, "Readmit") {
+			return SLRU_EOF, false
+		}
+	}
+
+	n := c.freelist.removeTail()
+	if n == SLRU_EOF {
+		c.doPanic("Readmit: no free entry available")
+	}
+	e := &c.entries[n]
+	e.key = key
+	e.value = v.value
+	e.weight = 1
+	e.expires = v.expires
+	e.epoch = c.epoch
+	e.inserted = c.now()
+	e.accessed = e.inserted
+	c.setIndex(key, n)
+	c.lrulist.insertHead(n)
+	c.policy.Inserted(c.lrulist, n)
+	c.stats.VictimHits++
+	if c.mlog != nil {
+		c.logMutation(logInsert, n, key)
+	}
+	return n, true
+}
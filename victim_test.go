@@ -0,0 +1,61 @@
+package slrucache
+
+import (
+	"testing"
+)
+
+// TestVictimBuffer tests that recently evicted entries are readmitted into
+// the protected segment.
+func TestVictimBuffer(t *testing.T) {
+	c := NewSLRUCache[int, int](2, 2)
+	c.SetVictimBuffer(2)
+	insertInts(c, 0, 5) // evicts 0, 1 and 2
+	c.Remove(1)
+
+	if v := c.Lookup(2); v == nil || *v != 2 {
+		t.Fatal("evicted entry should be readmitted")
+	}
+	if c.entries[c.lrulist.head].key != 2 {
+		t.Error("readmitted entry should be at the head of the lrulist")
+	}
+	if c.Lookup(0) != nil {
+		t.Error("entry should have dropped out of the buffer")
+	}
+	if c.Lookup(1) != nil {
+		t.Error("removed entry should not be readmitted")
+	}
+	if c.Lookup(2) == nil {
+		t.Error("readmitted entry should stay cached")
+	}
+
+	s := c.Stats()
+	if s.VictimHits != 1 || s.Hits != 2 || s.Misses != 2 {
+		t.Errorf("stats %+v", s)
+	}
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestVictimBufferPinnedProbation tests that readmission with demotion
+// into a pinned probelist reports a miss.
+func TestVictimBufferPinnedProbation(t *testing.T) {
+	c := NewSLRUCache[int, int](1, 2)
+	c.SetDemotion(true)
+	c.SetVictimBuffer(4)
+	insertInts(c, 0, 1)
+	c.Lookup(0)
+	insertInts(c, 1, 4) // evicts 1
+	c.Pin(2)
+	c.Pin(3)
+
+	if c.Lookup(1) != nil {
+		t.Error("readmission should fail")
+	}
+	if c.Lookup(0) == nil || c.entries[c.lrulist.head].key != 0 {
+		t.Error("protected victim should stay in the lrulist")
+	}
+	if err := c.Verify(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,137 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+// KV is a key-value pair.
+type KV[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Warm bulk loads items into the probelist for a fast cold start.
+// Items are ordered from most to least important, the first item ends up
+// at the head of the probelist. Warm only fills free capacity and never
+// evicts; keys already cached get their value updated, items that do not
+// fit are skipped. New entries weigh the size of their value if a Sizer is
+// set, 1 otherwise, and are skipped if they exceed the weight or entry
+// limit. Callbacks are not called.
+// Returns the number of items cached.
+func (c *SLRUCache[K, V]) Warm(items []KV[K, V]) int {
+	return c.WarmProtected(items, 0)
+}
+
+// NewSLRUCacheFromMap creates a cache with the given segment sizes holding
+// the entries of m, e.g. to turn a static lookup table into a bounded
+// cache. The keys listed in order come first, most important first, then
+// the remaining keys in map iteration order. The lrulist is filled first;
+// entries exceeding the capacity are left out.
+func NewSLRUCacheFromMap[K comparable, V any](lruEntries int, probeEntries int, m map[K]V, order []K) *SLRUCache[K, V] {
+	c := NewSLRUCache[K, V](lruEntries, probeEntries)
+
+	items := make([]KV[K, V], 0, len(m))
+	listed := make(map[K]bool, len(order))
+	for _, k := range order {
+		if v, ok := m[k]; ok && !listed[k] {
+			items = append(items, KV[K, V]{k, v})
+			listed[k] = true
+		}
+	}
+	for k, v := range m {
+		if !listed[k] {
+			items = append(items, KV[K, V]{k, v})
+		}
+	}
+
+	c.warm(items, c.snum)
+	return c
+}
+
+// WarmProtected is Warm placing the first fraction of items directly into
+// the lrulist, as far as it has free capacity.
+func (c *SLRUCache[K, V]) WarmProtected(items []KV[K, V], protected float64) int {
+	return c.warm(items, int(float64(len(items))*protected))
+}
+
+// warm is Warm placing the first np items into the lrulist.
+func (c *SLRUCache[K, V]) warm(items []KV[K, V], np int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.recoverCorruption(false)
+
+	np = min(max(np, 0), len(items))
+
+	// Update existing keys, collect new ones per segment
+	cached := 0
+	var lru, probe []int
+	weights := make([]int32, len(items))
+	weight := c.probelist.weight + c.lrulist.weight
+	seen := make(map[K]struct{})
+	for i, it := range items {
+		if n, ok := c.find(it.Key); ok {
+			c.setValue(n, it.Value)
+			cached++
+			continue
+		}
+		if _, ok := seen[it.Key]; ok {
+			// duplicate key, the first occurrence wins
+			continue
+		}
+		weights[i] = c.warmWeight(it.Value)
+		if c.maxWeight > 0 && weight+int64(weights[i]) > c.maxWeight {
+			continue
+		}
+		if limit := c.limit(); limit > 0 && c.indexLen()+len(lru)+len(probe) >= limit {
+			continue
+		}
+		if i < np && c.lrulist.count+len(lru) < c.snum {
+			lru = append(lru, i)
+		} else if c.probelist.count+len(probe) < c.pnum {
+			probe = append(probe, i)
+		} else {
+			continue
+		}
+		seen[it.Key] = struct{}{}
+		weight += int64(weights[i])
+	}
+
+	for _, seg := range []struct {
+		l   *SLRUList[K, V]
+		idx []int
+	}{{c.lrulist, lru}, {c.probelist, probe}} {
+		// insert in reverse so the first item ends up at the head
+		for j := len(seg.idx) - 1; j >= 0; j-- {
+			i := seg.idx[j]
+			it := items[i]
+			n := c.freelist.removeTail()
+			if n == SLRU_EOF {
+				c.doPanic("Warm: no free entry available")
+			}
+			e := &c.entries[n]
+			e.key = it.Key
+			e.value = it.Value
+			e.weight = weights[i]
+			e.expires = c.expiry(entryOptions{})
+			e.epoch = c.epoch
+			e.inserted = c.now()
+			e.accessed = e.inserted
+			c.setIndex(it.Key, n)
+			seg.l.insertHead(n)
+			c.policyOf(seg.l).Inserted(seg.l, n)
+			if c.mlog != nil {
+				c.logMutation(logInsert, n, it.Key)
+			}
+			cached++
+		}
+	}
+
+	return cached
+}
+
+// warmWeight returns the weight of a warmed value, its size if a Sizer is
+// set, at least 1. Must be called with the mutex held.
+func (c *SLRUCache[K, V]) warmWeight(value V) int32 {
+	if c.probelist.sizer == nil {
+		return 1
+	}
+	return int32(min(max(c.probelist.sizer(value), 1), MaxEntries))
+}
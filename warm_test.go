@@ -0,0 +1,84 @@
+package slrucache
+
+import (
+	"strconv"
+	"testing"
+)
+
+// makeKVs returns count items with keys and values starting at offset.
+func makeKVs(count int, offset int) []KV[string, string] {
+	items := make([]KV[string, string], count)
+	for n := range items {
+		s := strconv.Itoa(n + offset)
+		items[n] = KV[string, string]{Key: s, Value: s}
+	}
+	return items
+}
+
+// TestWarm tests bulk loading into the probelist.
+func TestWarm(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	if n := c.Warm(makeKVs(8, 0)); n != 5 {
+		t.Errorf("expected 5 cached items, got %d", n)
+	}
+	if checkListCount(c, 5, 0, 5, "warm") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if keys := listKeys(c, c.probelist); keys[0] != "0" || keys[4] != "4" {
+		t.Errorf("unexpected probelist order %v", keys)
+	}
+}
+
+// TestWarmProtected tests placing a fraction of the items into the lrulist.
+func TestWarmProtected(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.Insert("1", "old")
+	items := append(makeKVs(10, 0), KV[string, string]{Key: "0", Value: "dup"})
+	if n := c.WarmProtected(items, 0.3); n != 7 {
+		t.Errorf("expected 7 cached items, got %d", n)
+	}
+	if checkListCount(c, 3, 2, 5, "warm protected") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if keys := listKeys(c, c.lrulist); !equalKeys(keys, []string{"0", "2"}) {
+		t.Errorf("unexpected lrulist %v", keys)
+	}
+	if v := c.Lookup("1"); v == nil || *v != "1" {
+		t.Error("existing key should be updated")
+	}
+	if v := c.Lookup("0"); v == nil || *v != "0" {
+		t.Error("first occurrence of a duplicate key should win")
+	}
+}
+
+// TestWarmWeight tests that warmed entries weigh their size and respect the weight limit.
+func TestWarmWeight(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	c.SetSizer(func(v string) int { return len(v) })
+	c.SetMaxWeight(10)
+	items := []KV[string, string]{{"a", "aaaa"}, {"b", "bbbbbbb"}, {"c", "cc"}, {"d", "dddd"}}
+	if n := c.Warm(items); n != 3 || c.Weight() != 10 || c.Bytes() != 10 {
+		t.Errorf("cached %d, weight %d, bytes %d", n, c.Weight(), c.Bytes())
+	}
+	if c.Lookup("b") != nil || checkSLRUCacheSanity(c) {
+		t.Error("item exceeding the weight limit should be skipped")
+	}
+}
+
+// TestNewSLRUCacheFromMap tests bulk loading a map in the given order.
+func TestNewSLRUCacheFromMap(t *testing.T) {
+	m := make(map[string]string)
+	for _, kv := range makeKVs(10, 0) {
+		m[kv.Key] = kv.Value
+	}
+	c := NewSLRUCacheFromMap(2, 3, m, []string{"9", "8", "missing", "7"})
+	if checkListCount(c, 0, 2, 3, "from map") || checkSLRUCacheSanity(c) {
+		t.Fail()
+	}
+	if keys := listKeys(c, c.lrulist); keys[0] != "9" || keys[1] != "8" {
+		t.Errorf("unexpected lrulist order %v", keys)
+	}
+	if keys := listKeys(c, c.probelist); keys[0] != "7" {
+		t.Errorf("unexpected probelist order %v", keys)
+	}
+}
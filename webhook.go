@@ -0,0 +1,237 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Webhook event kinds.
+const (
+	WebhookMassEviction = "mass_eviction"
+	WebhookHitRatioDrop = "hit_ratio_drop"
+	WebhookCorruption   = "corruption"
+)
+
+// webhookMaxPending bounds the events kept while the webhook is unreachable.
+const webhookMaxPending = 1000
+
+// webhookTimeout bounds the posts of the default client.
+const webhookTimeout = 10 * time.Second
+
+// WebhookEvent is a significant cache event reported to a webhook.
+type WebhookEvent struct {
+	Kind   string    `json:"kind"`
+	Time   time.Time `json:"time"`
+	Value  float64   `json:"value"`  // evictions or hit ratio of the interval
+	Detail string    `json:"detail"` // human readable description
+}
+
+// WebhookSink batches significant cache events and POSTs them as a JSON
+// array to a webhook URL. The cache feeds it evictions, lookups and
+// corruption reports; the sink aggregates them per interval:
+//   - MassEviction: at least MassEvictions entries were evicted in the interval
+//   - HitRatioDrop: the hit ratio of the interval fell below MinHitRatio
+//   - Corruption: an internal inconsistency was detected, sent immediately
+//
+// The exported fields may be adjusted before the sink is attached to a cache.
+type WebhookSink struct {
+	URL           string
+	Client        *http.Client
+	MassEvictions int     // evictions per interval reported as mass eviction, 0 disables
+	MinHitRatio   float64 // hit ratio per interval below which a drop is reported, 0 disables
+	MinLookups    int     // lookups per interval required to judge the hit ratio
+
+	mu        sync.Mutex
+	pending   []WebhookEvent
+	evictions int
+	hits      int
+	misses    int
+	lastErr   error
+
+	sending sync.WaitGroup // corruption reports being sent
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewWebhookSink creates a WebhookSink posting to url with a client timing
+// out after 10 seconds. If interval is positive, the sink is flushed
+// periodically until Close is called.
+func NewWebhookSink(url string, interval time.Duration) *WebhookSink {
+	s := &WebhookSink{
+		URL:        url,
+		Client:     &http.Client{Timeout: webhookTimeout},
+		MinLookups: 100,
+	}
+
+	if interval > 0 {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.run(interval)
+	}
+
+	return s
+}
+
+// run flushes the sink every interval until stopped.
+func (s *WebhookSink) run(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	defer close(s.done)
+
+	for {
+		select {
+		case <-t.C:
+			s.Flush()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close stops the periodic flushing, waits for corruption reports being
+// sent and sends the remaining events.
+func (s *WebhookSink) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	s.sending.Wait()
+	return s.Flush()
+}
+
+// evicted records n evictions.
+func (s *WebhookSink) evicted(n int) {
+	s.mu.Lock()
+	s.evictions += n
+	s.mu.Unlock()
+}
+
+// lookup records a lookup result.
+func (s *WebhookSink) lookup(hit bool) {
+	s.mu.Lock()
+	if hit {
+		s.hits++
+	} else {
+		s.misses++
+	}
+	s.mu.Unlock()
+}
+
+// corrupted records a corruption report and sends the pending events
+// right away, since the cache is likely to panic afterwards. It is called
+// with the cache mutex held, so the post runs in its own goroutine and the
+// current interval is left open.
+func (s *WebhookSink) corrupted(msg string) {
+	s.mu.Lock()
+	s.pending = append(s.pending, WebhookEvent{
+		Kind:   WebhookCorruption,
+		Time:   time.Now(),
+		Detail: msg,
+	})
+	s.mu.Unlock()
+
+	s.sending.Add(1)
+	go func() {
+		defer s.sending.Done()
+		s.send()
+	}()
+}
+
+// closeInterval turns the counters of the current interval into events.
+// Must be called with s.mu held.
+func (s *WebhookSink) closeInterval() {
+	now := time.Now()
+
+	if s.MassEvictions > 0 && s.evictions >= s.MassEvictions {
+		s.pending = append(s.pending, WebhookEvent{
+			Kind:   WebhookMassEviction,
+			Time:   now,
+			Value:  float64(s.evictions),
+			Detail: fmt.Sprintf("%d entries evicted", s.evictions),
+		})
+	}
+
+	lookups := s.hits + s.misses
+	if s.MinHitRatio > 0 && lookups > 0 && lookups >= s.MinLookups {
+		ratio := float64(s.hits) / float64(lookups)
+		if ratio < s.MinHitRatio {
+			s.pending = append(s.pending, WebhookEvent{
+				Kind:   WebhookHitRatioDrop,
+				Time:   now,
+				Value:  ratio,
+				Detail: fmt.Sprintf("hit ratio %.4f below %.4f over %d lookups", ratio, s.MinHitRatio, lookups),
+			})
+		}
+	}
+
+	s.evictions = 0
+	s.hits = 0
+	s.misses = 0
+}
+
+// Flush closes the current interval and posts all pending events.
+// Events are kept for the next attempt if the post fails.
+func (s *WebhookSink) Flush() error {
+	s.mu.Lock()
+	s.closeInterval()
+	s.mu.Unlock()
+	return s.send()
+}
+
+// send posts all pending events, keeping them for the next attempt if the
+// post fails.
+func (s *WebhookSink) send() error {
+	s.mu.Lock()
+	events := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	err := s.post(events)
+	if err != nil {
+		s.mu.Lock()
+		s.pending = append(events, s.pending...)
+		if len(s.pending) > webhookMaxPending {
+			s.pending = s.pending[len(s.pending)-webhookMaxPending:]
+		}
+		s.lastErr = err
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// post sends events as a JSON array to the webhook URL.
+func (s *WebhookSink) post(events []WebhookEvent) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Err returns the error of the last failed post, if any.
+func (s *WebhookSink) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastErr
+}
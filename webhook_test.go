@@ -0,0 +1,111 @@
+package slrucache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// webhookRecorder collects the events posted to a test server.
+type webhookRecorder struct {
+	mu     sync.Mutex
+	events []WebhookEvent
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var events []WebhookEvent
+	if err := json.NewDecoder(req.Body).Decode(&events); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.mu.Lock()
+	r.events = append(r.events, events...)
+	r.mu.Unlock()
+}
+
+// TestWebhookEvents tests that mass evictions and hit ratio drops are posted.
+func TestWebhookEvents(t *testing.T) {
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	s := NewWebhookSink(srv.URL, 0)
+	s.MassEvictions = 10
+	s.MinHitRatio = 0.5
+	s.MinLookups = 10
+
+	c := NewSLRUCache[string, string](10, 10)
+	c.SetWebhook(s)
+	insertN(c, 30, 0)
+	lookupN(c, 20, 100)
+
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.events) != 2 || rec.events[0].Kind != WebhookMassEviction || rec.events[0].Value != 20 ||
+		rec.events[1].Kind != WebhookHitRatioDrop {
+		t.Errorf("unexpected events %v", rec.events)
+	}
+
+	// a quiet interval posts nothing
+	if err := s.Close(); err != nil || len(rec.events) != 2 {
+		t.Errorf("unexpected events after quiet interval %v", rec.events)
+	}
+}
+
+// TestWebhookRetry tests that events are kept when the webhook fails.
+func TestWebhookRetry(t *testing.T) {
+	fail := true
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if fail {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		rec.ServeHTTP(w, req)
+	}))
+	defer srv.Close()
+
+	s := NewWebhookSink(srv.URL, 0)
+	s.corrupted("test")
+	s.sending.Wait()
+	if s.Err() == nil {
+		t.Error("failed post should be reported")
+	}
+
+	fail = false
+	if err := s.Flush(); err != nil || len(rec.events) != 1 || rec.events[0].Kind != WebhookCorruption {
+		t.Errorf("event should be delivered on retry: %v %v", err, rec.events)
+	}
+}
+
+// TestWebhookCorruptionAsync tests that corruption reports neither block nor close the interval.
+func TestWebhookCorruptionAsync(t *testing.T) {
+	release := make(chan struct{})
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+		rec.ServeHTTP(w, req)
+	}))
+	defer srv.Close()
+
+	s := NewWebhookSink(srv.URL, 0)
+	s.MinHitRatio = 0.5
+	s.MinLookups = 1
+	s.lookup(false)
+	s.corrupted("test")
+	close(release)
+	s.sending.Wait()
+
+	rec.mu.Lock()
+	if len(rec.events) != 1 || rec.events[0].Kind != WebhookCorruption {
+		t.Errorf("only the corruption should be sent: %v", rec.events)
+	}
+	rec.mu.Unlock()
+
+	if err := s.Flush(); err != nil || len(rec.events) != 2 || rec.events[1].Kind != WebhookHitRatioDrop {
+		t.Errorf("interval should stay open: %v %v", err, rec.events)
+	}
+}
@@ -0,0 +1,80 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// windowBuckets is the number of buckets a hit window is divided into.
+const windowBuckets = 10
+
+// hitWindow counts hits and misses in a ring of buckets covering a sliding
+// time window. Buckets are reused once they fall out of the window. It is
+// updated with atomics, since shared lookups hold only the read lock.
+type hitWindow struct {
+	span    int64 // time span of a bucket in nanoseconds
+	buckets [windowBuckets]windowBucket
+}
+
+// windowBucket holds the counts of one time span.
+type windowBucket struct {
+	start  atomic.Int64 // start of the span in Unix nanoseconds
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// SetHitWindow enables tracking the hit ratio of the lookups within the
+// last window, see RecentHitRatio. The lifetime counters of Stats hide
+// recent changes of the workload. The window advances in steps of a tenth
+// of its length. 0 disables the tracking.
+func (c *SLRUCache[K, V]) SetHitWindow(window time.Duration) {
+	if window <= 0 {
+		c.window.Store(nil)
+		return
+	}
+	c.window.Store(&hitWindow{span: max(int64(window)/windowBuckets, 1)})
+}
+
+// RecentHitRatio returns the fraction of lookups that were hits within the
+// window set by SetHitWindow, 0 if it is not set or there were no lookups.
+func (c *SLRUCache[K, V]) RecentHitRatio() float64 {
+	w := c.window.Load()
+	if w == nil {
+		return 0
+	}
+	hits, misses := w.counts(c.now())
+	return ratio(hits, hits+misses)
+}
+
+// record counts a hit or miss at time now.
+func (w *hitWindow) record(now time.Time, hit bool) {
+	t := now.UnixNano()
+	start := t - t%w.span
+	b := &w.buckets[(t/w.span)%windowBuckets]
+	if old := b.start.Load(); old != start && b.start.CompareAndSwap(old, start) {
+		// Reuse the bucket of a past span; concurrent counts may get lost
+		b.hits.Store(0)
+		b.misses.Store(0)
+	}
+	if hit {
+		b.hits.Add(1)
+	} else {
+		b.misses.Add(1)
+	}
+}
+
+// counts returns the hits and misses of the buckets within the window
+// ending at now.
+func (w *hitWindow) counts(now time.Time) (hits, misses uint64) {
+	oldest := now.UnixNano() - w.span*windowBuckets
+	for i := range w.buckets {
+		b := &w.buckets[i]
+		if b.start.Load() > oldest {
+			hits += b.hits.Load()
+			misses += b.misses.Load()
+		}
+	}
+	return hits, misses
+}
@@ -0,0 +1,36 @@
+package slrucache
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRecentHitRatio tests that old lookups drop out of the window.
+func TestRecentHitRatio(t *testing.T) {
+	c := NewSLRUCache[string, string](5, 5)
+	clock := newTestClock(c)
+	if c.RecentHitRatio() != 0 {
+		t.Error("ratio without window should be 0")
+	}
+	c.SetHitWindow(10 * time.Second)
+
+	c.Insert("a", "a")
+	for i := 0; i < 3; i++ {
+		c.Lookup("b")
+	}
+	clock.advance(5 * time.Second)
+	c.Lookup("a")
+	if r := c.RecentHitRatio(); r != 0.25 {
+		t.Errorf("ratio %v, want 0.25", r)
+	}
+
+	// The misses leave the window, the hit stays
+	clock.advance(6 * time.Second)
+	c.Lookup("a")
+	if r := c.RecentHitRatio(); r != 1 {
+		t.Errorf("ratio %v, want 1", r)
+	}
+	if r := c.Stats().HitRatio(); r != 0.4 {
+		t.Errorf("lifetime ratio %v, want 0.4", r)
+	}
+}
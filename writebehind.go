@@ -0,0 +1,180 @@
+// author: (c) Gunter Hartmann
+
+package slrucache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchWriter is a durable store persisting entries written behind the
+// cache, see SetWriteBehind. It must be safe for concurrent use.
+type BatchWriter[K comparable, V any] interface {
+	// WriteBatch persists the entries, in insert order.
+	WriteBatch(ctx context.Context, entries []KV[K, V]) error
+}
+
+// WriteBehindConfig configures the batching and retrying of write-behind.
+// Zero values select the defaults.
+type WriteBehindConfig struct {
+	BatchSize  int             // maximum entries per batch, default 100
+	Interval   time.Duration   // maximum delay of a pending entry, default 1s
+	Retries    int             // retries of a failed batch, default 3
+	RetryDelay time.Duration   // delay before the first retry, doubled per retry, default 100ms
+	OnError    func(err error) // optional callback for batches dropped after the last retry
+}
+
+// writeBehind persists inserted entries from its own goroutine.
+type writeBehind[K comparable, V any] struct {
+	writer BatchWriter[K, V]
+	cfg    WriteBehindConfig
+
+	mu      sync.Mutex // guards pending and index
+	pending []KV[K, V] // entries waiting to be written
+	index   map[K]int  // position of pending keys, coalesces updates
+
+	writing sync.Mutex // serializes the writes of batches
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// SetWriteBehind enqueues inserted values for asynchronous persistence by w,
+// so the cache can front a slow durable store. Pending entries are written
+// in batches of up to cfg.BatchSize when a batch is full or cfg.Interval
+// passed; updates of a pending key are coalesced. Failed batches are
+// retried with exponential backoff and dropped after cfg.Retries retries.
+// Removals and negative entries are not persisted. Pass nil to stop
+// write-behind after flushing; Close does the same.
+func (c *SLRUCache[K, V]) SetWriteBehind(w BatchWriter[K, V], cfg WriteBehindConfig) {
+	var wb *writeBehind[K, V]
+	if w != nil {
+		wb = newWriteBehind(w, cfg)
+	}
+
+	c.mu.Lock()
+	old := c.writeBehind
+	c.writeBehind = wb
+	c.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
+
+// FlushWrites writes the pending write-behind entries and waits for them.
+func (c *SLRUCache[K, V]) FlushWrites() {
+	c.mu.RLock()
+	wb := c.writeBehind
+	c.mu.RUnlock()
+
+	if wb != nil {
+		wb.flush()
+	}
+}
+
+// newWriteBehind creates a writeBehind and starts its goroutine.
+func newWriteBehind[K comparable, V any](w BatchWriter[K, V], cfg WriteBehindConfig) *writeBehind[K, V] {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Retries <= 0 {
+		cfg.Retries = 3
+	}
+	if cfg.RetryDelay <= 0 {
+		cfg.RetryDelay = 100 * time.Millisecond
+	}
+
+	wb := &writeBehind[K, V]{
+		writer: w,
+		cfg:    cfg,
+		index:  make(map[K]int),
+		kick:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(wb.done)
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-wb.stop:
+				wb.flush()
+				return
+			case <-ticker.C:
+			case <-wb.kick:
+			}
+			wb.flush()
+		}
+	}()
+	return wb
+}
+
+// add enqueues an entry, waking the goroutine if a batch is full. It does
+// not block and is called with the cache mutex held.
+func (wb *writeBehind[K, V]) add(key K, value V) {
+	wb.mu.Lock()
+	if i, ok := wb.index[key]; ok {
+		wb.pending[i].Value = value
+	} else {
+		wb.index[key] = len(wb.pending)
+		wb.pending = append(wb.pending, KV[K, V]{Key: key, Value: value})
+	}
+	full := len(wb.pending) >= wb.cfg.BatchSize
+	wb.mu.Unlock()
+
+	if full {
+		select {
+		case wb.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flush writes all pending entries in batches.
+func (wb *writeBehind[K, V]) flush() {
+	wb.writing.Lock()
+	defer wb.writing.Unlock()
+
+	wb.mu.Lock()
+	pending := wb.pending
+	wb.pending = nil
+	clear(wb.index)
+	wb.mu.Unlock()
+
+	for len(pending) > 0 {
+		n := min(len(pending), wb.cfg.BatchSize)
+		wb.write(pending[:n])
+		pending = pending[n:]
+	}
+}
+
+// write writes one batch, retrying on errors.
+func (wb *writeBehind[K, V]) write(batch []KV[K, V]) {
+	delay := wb.cfg.RetryDelay
+	for retry := 0; ; retry++ {
+		err := wb.writer.WriteBatch(context.Background(), batch)
+		if err == nil {
+			return
+		}
+		if retry == wb.cfg.Retries {
+			if wb.cfg.OnError != nil {
+				wb.cfg.OnError(err)
+			}
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// close stops the goroutine after writing the pending entries.
+func (wb *writeBehind[K, V]) close() {
+	close(wb.stop)
+	<-wb.done
+}
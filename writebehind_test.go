@@ -0,0 +1,113 @@
+package slrucache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchRecorder records the batches written behind a cache.
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]KV[string, string]
+	fail    int // number of writes failing before success
+}
+
+// WriteBatch records entries or fails.
+func (r *batchRecorder) WriteBatch(ctx context.Context, entries []KV[string, string]) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail > 0 {
+		r.fail--
+		return errors.New("unavailable")
+	}
+	r.batches = append(r.batches, append([]KV[string, string](nil), entries...))
+	return nil
+}
+
+// TestWriteBehind tests batching and coalescing of written entries.
+func TestWriteBehind(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	c.mu = new(sync.RWMutex)
+	r := &batchRecorder{}
+	c.SetWriteBehind(r, WriteBehindConfig{BatchSize: 2, Interval: time.Hour})
+	defer c.Close()
+
+	c.Insert("a", "1")
+	c.Insert("b", "1")
+	c.Insert("c", "1")
+	c.InsertNegative("d")
+	c.FlushWrites()
+	c.Insert("c", "2")
+	c.Insert("c", "3")
+	c.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int
+	for _, b := range r.batches {
+		if len(b) > 2 {
+			t.Errorf("batch too large: %v", b)
+		}
+		n += len(b)
+	}
+	last := r.batches[len(r.batches)-1]
+	if n != 4 || len(last) != 1 || last[0] != (KV[string, string]{"c", "3"}) {
+		t.Errorf("batches %v", r.batches)
+	}
+}
+
+// TestWriteBehindRetry tests that failed batches are retried and dropped.
+func TestWriteBehindRetry(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	c.mu = new(sync.RWMutex)
+	r := &batchRecorder{fail: 2}
+	var failed error
+	c.SetWriteBehind(r, WriteBehindConfig{Interval: time.Hour, Retries: 2, RetryDelay: time.Millisecond,
+		OnError: func(err error) { failed = err }})
+
+	c.Insert("a", "1")
+	c.FlushWrites()
+	if failed != nil || len(r.batches) != 1 {
+		t.Errorf("batch should succeed on the last retry: %v %v", failed, r.batches)
+	}
+
+	r.fail = 3
+	c.Insert("b", "1")
+	c.Close()
+	if failed == nil || len(r.batches) != 1 {
+		t.Errorf("batch should be dropped: %v", r.batches)
+	}
+}
+
+// TestWriteBehindOrder tests that concurrent updates of a key reach the writer in cache order.
+func TestWriteBehindOrder(t *testing.T) {
+	c := NewSLRUCache[string, string](10, 10)
+	c.mu = new(sync.RWMutex)
+	r := &batchRecorder{}
+	c.SetWriteBehind(r, WriteBehindConfig{BatchSize: 1, Interval: time.Hour})
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Insert("k", fmt.Sprint(i, j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	c.FlushWrites()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	last := r.batches[len(r.batches)-1]
+	if v := c.Lookup("k"); last[len(last)-1].Value != *v {
+		t.Errorf("writer got %q, cache has %q", last[len(last)-1].Value, *v)
+	}
+}